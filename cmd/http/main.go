@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
 	"os"
 	"os/signal"
@@ -16,6 +17,7 @@ import (
 	"github.com/rafaelleal24/challenge/internal/adapters/outbox"
 	"github.com/rafaelleal24/challenge/internal/adapters/rabbitmq"
 	"github.com/rafaelleal24/challenge/internal/adapters/redis"
+	"github.com/rafaelleal24/challenge/internal/adapters/scheduler"
 	"github.com/rafaelleal24/challenge/internal/core/domain"
 	"github.com/rafaelleal24/challenge/internal/core/logger"
 	"github.com/rafaelleal24/challenge/internal/core/service"
@@ -43,6 +45,10 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	if err := cfg.Quote.Validate(); err != nil {
+		logger.Fatal(ctx, "Invalid quote configuration", err, nil)
+	}
+
 	// initialize database connection
 	mongoClient, err := mongo.NewConnection(cfg.Mongo)
 	if err != nil {
@@ -69,15 +75,21 @@ func main() {
 
 	// initialize database and repos
 	database := mongoClient.Database(cfg.Mongo.Database)
-	customerRepository := repository.NewCustomerRepository(database)
-	productRepository := repository.NewProductRepository(database)
 	outboxRepository := repository.NewOutboxRepository(database)
+	customerRepository := repository.NewCustomerRepository(database, outboxRepository)
+	productRepository := repository.NewProductRepository(database, outboxRepository)
 	orderRepository := repository.NewOrderRepository(database, outboxRepository)
+	reservationRepository := repository.NewReservationRepository(database)
+	stockMovementRepository := repository.NewStockMovementRepository(database)
+	categoryRepository := repository.NewCategoryRepository(database)
+	productImportRepository := repository.NewProductImportRepository(database)
 	txManager := mongo.NewTransactionManager(mongoClient)
 
 	// caches and rate limiter
 	orderCache := redis.NewCache[domain.Order](redisClient, "order-cache")
 	idempotencyCache := redis.NewCache[service.IdempotencyEntry[domain.Order]](redisClient, "idempotency-cache")
+	schedulerLocks := redis.NewCache[string](redisClient, "scheduler-lock")
+	customerExistenceCache := redis.NewCache[bool](redisClient, "customer-exists-cache")
 	rateLimiter := redis.NewRateLimiter(redisClient)
 
 	// outbox handler (uses cancellable context)
@@ -86,15 +98,45 @@ func main() {
 	logger.Info(ctx, "Outbox handler started", map[string]any{"interval": cfg.Outbox.Interval.String(), "batch_size": cfg.Outbox.BatchSize})
 
 	// services
-	customerService := service.NewCustomerService(customerRepository)
-	productService := service.NewProductService(productRepository)
+	customerService := service.NewCustomerService(customerRepository, customerExistenceCache)
 	idempotencyService := service.NewIdempotencyService(idempotencyCache, 15*time.Minute, 1*time.Second, 10*time.Second)
-	orderService := service.NewOrderService(orderRepository, productService, customerService, orderCache, idempotencyService, txManager)
+	customerDataService := service.NewCustomerDataService(customerRepository, orderRepository, orderCache, customerExistenceCache, idempotencyService, txManager)
+	productService := service.NewProductService(productRepository, categoryRepository, stockMovementRepository, txManager, cfg.Product.LowStockThreshold)
+	categoryService := service.NewCategoryService(categoryRepository, productRepository, txManager)
+	productImportService := service.NewProductImportService(productRepository, categoryRepository, stockMovementRepository, productImportRepository, txManager, cfg.ProductImport.StaleAfter)
+	reservationService := service.NewReservationService(reservationRepository, productService, txManager, cfg.Reservation.TTL)
+	quoteSecret := []byte(cfg.Quote.Secret)
+	if len(quoteSecret) == 0 {
+		quoteSecret = make([]byte, 32)
+		if _, err := rand.Read(quoteSecret); err != nil {
+			logger.Fatal(ctx, "Failed to generate quote secret", err, nil)
+		}
+		logger.Warn(ctx, "QUOTE_SECRET not set, using a random secret; quote tokens will not survive restarts or work across replicas", nil)
+	}
+	quoteSigner := service.NewQuoteSigner(quoteSecret, cfg.Quote.TTL)
+	orderService := service.NewOrderService(orderRepository, productService, reservationService, customerService, orderCache, idempotencyService, txManager, quoteSigner)
+
+	// reservation sweeper (uses cancellable context)
+	reservationSweeper := scheduler.NewReservationSweeper(reservationService, cfg.Reservation)
+	go reservationSweeper.Start(ctx)
+	logger.Info(ctx, "Reservation sweeper started", map[string]any{"interval": cfg.Reservation.SweepInterval.String(), "ttl": cfg.Reservation.TTL.String()})
+
+	// order expirer (uses cancellable context)
+	orderExpirer := scheduler.NewOrderExpirer(orderService, schedulerLocks, cfg.OrderExpiry)
+	go orderExpirer.Start(ctx)
+	logger.Info(ctx, "Order expirer started", map[string]any{"interval": cfg.OrderExpiry.Interval.String(), "max_age": cfg.OrderExpiry.MaxAge.String()})
+
+	// product importer (uses cancellable context)
+	productImporter := scheduler.NewProductImporter(productImportService, cfg.ProductImport)
+	go productImporter.Start(ctx)
+	logger.Info(ctx, "Product importer started", map[string]any{"interval": cfg.ProductImport.Interval.String(), "stale_after": cfg.ProductImport.StaleAfter.String()})
 
 	// controllers
 	orderController := controllers.NewOrderController(orderService)
 	productController := controllers.NewProductController(productService)
-	customerController := controllers.NewCustomerController(customerService)
+	productImportController := controllers.NewProductImportController(productImportService)
+	categoryController := controllers.NewCategoryController(categoryService)
+	customerController := controllers.NewCustomerController(customerService, customerDataService)
 	healthController := controllers.NewHealthController([]controllers.HealthChecker{
 		{Name: "mongodb", Check: func(ctx context.Context) error { return mongoClient.Ping(ctx, nil) }},
 		{Name: "redis", Check: func(ctx context.Context) error { return redisClient.Ping(ctx) }},
@@ -102,7 +144,7 @@ func main() {
 	})
 
 	// router
-	router := http.NewRouter(healthController, orderController, productController, customerController, rateLimiter)
+	router := http.NewRouter(healthController, orderController, productController, productImportController, categoryController, customerController, rateLimiter)
 
 	// graceful shutdown
 	go func() {
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -24,6 +27,8 @@ type RabbitMQConfig struct {
 }
 
 type ExchangeConfig struct {
+	// Entity is the entity whose events are published to the exchange, e.g. "order".
+	Entity     string
 	Name       string
 	Type       string // direct, topic, fanout, headers
 	Durable    bool
@@ -41,18 +46,80 @@ type OutboxConfig struct {
 	Interval  time.Duration
 }
 
+type ReservationConfig struct {
+	TTL           time.Duration
+	SweepInterval time.Duration
+	SweepBatch    int
+}
+
+type ProductConfig struct {
+	// LowStockThreshold applies to products without a threshold of their own.
+	LowStockThreshold int
+}
+
+type ProductImportConfig struct {
+	Interval time.Duration
+	// StaleAfter is how long a running import may go without saving progress before another
+	// worker takes it over.
+	StaleAfter time.Duration
+}
+
+type OrderExpiryConfig struct {
+	// MaxAge is how long an order may stay created before it is cancelled.
+	MaxAge    time.Duration
+	Interval  time.Duration
+	BatchSize int
+}
+
+type QuoteConfig struct {
+	// Secret signs quote tokens; every replica must share it for tokens to be accepted anywhere.
+	Secret string
+	TTL    time.Duration
+}
+
+// MinQuoteSecretLength is the shortest quote secret accepted, in bytes.
+const MinQuoteSecretLength = 32
+
+// placeholderQuoteSecrets are values copied from examples that anyone could sign tokens with.
+var placeholderQuoteSecrets = map[string]bool{
+	"change-me":    true,
+	"changeme":     true,
+	"secret":       true,
+	"quote-secret": true,
+}
+
+// Validate rejects quote secrets that are publicly known or too short to resist guessing. An
+// empty secret is valid: a random one is generated at startup.
+func (c QuoteConfig) Validate() error {
+	if c.Secret == "" {
+		return nil
+	}
+	if placeholderQuoteSecrets[strings.ToLower(strings.TrimSpace(c.Secret))] {
+		return errors.New("QUOTE_SECRET is a placeholder value; set a random secret or leave it empty")
+	}
+	if len(c.Secret) < MinQuoteSecretLength {
+		return fmt.Errorf("QUOTE_SECRET must be at least %d bytes long", MinQuoteSecretLength)
+	}
+	return nil
+}
+
 type HTTPConfig struct {
 	Port          string
 	BindInterface string
 }
 
 type Config struct {
-	Mongo    MongoConfig
-	Redis    RedisConfig
-	RabbitMQ RabbitMQConfig
-	Outbox   OutboxConfig
-	HTTP     HTTPConfig
-	Logger   LoggerConfig
+	Mongo         MongoConfig
+	Redis         RedisConfig
+	RabbitMQ      RabbitMQConfig
+	Outbox        OutboxConfig
+	Quote         QuoteConfig
+	Reservation   ReservationConfig
+	OrderExpiry   OrderExpiryConfig
+	Product       ProductConfig
+	ProductImport ProductImportConfig
+	HTTP          HTTPConfig
+	Logger        LoggerConfig
 }
 
 type LoggerConfig struct {
@@ -82,6 +149,27 @@ func NewConfig() *Config {
 			BatchSize: getIntEnv("OUTBOX_BATCH_SIZE", 100),
 			Interval:  time.Duration(getIntEnv("OUTBOX_INTERVAL", 500)) * time.Millisecond,
 		},
+		Quote: QuoteConfig{
+			Secret: getStringEnv("QUOTE_SECRET", ""),
+			TTL:    time.Duration(getIntEnv("QUOTE_TTL", 600)) * time.Second,
+		},
+		Reservation: ReservationConfig{
+			TTL:           time.Duration(getIntEnv("RESERVATION_TTL", 900)) * time.Second,
+			SweepInterval: time.Duration(getIntEnv("RESERVATION_SWEEP_INTERVAL", 30)) * time.Second,
+			SweepBatch:    getIntEnv("RESERVATION_SWEEP_BATCH_SIZE", 100),
+		},
+		OrderExpiry: OrderExpiryConfig{
+			MaxAge:    time.Duration(getIntEnv("ORDER_EXPIRY_MAX_AGE", 3600)) * time.Second,
+			Interval:  time.Duration(getIntEnv("ORDER_EXPIRY_INTERVAL", 60)) * time.Second,
+			BatchSize: getIntEnv("ORDER_EXPIRY_BATCH_SIZE", 100),
+		},
+		Product: ProductConfig{
+			LowStockThreshold: getIntEnv("PRODUCT_LOW_STOCK_THRESHOLD", 10),
+		},
+		ProductImport: ProductImportConfig{
+			Interval:   time.Duration(getIntEnv("PRODUCT_IMPORT_INTERVAL", 5)) * time.Second,
+			StaleAfter: time.Duration(getIntEnv("PRODUCT_IMPORT_STALE_AFTER", 300)) * time.Second,
+		},
 		HTTP: HTTPConfig{
 			Port:          getStringEnv("HTTP_PORT", "8080"),
 			BindInterface: getStringEnv("HTTP_BIND_INTERFACE", "0.0.0.0"),
@@ -92,11 +180,26 @@ func NewConfig() *Config {
 			RetryDelay: time.Duration(getIntEnv("RABBITMQ_RETRY_DELAY", 1)) * time.Second,
 			ExchangeConfigs: []ExchangeConfig{
 				{
+					Entity:     "order",
 					Name:       getStringEnv("RABBITMQ_EXCHANGE_NAME", "exchange.order"),
 					Type:       getStringEnv("RABBITMQ_EXCHANGE_TYPE", "direct"),
 					Durable:    getBoolEnv("RABBITMQ_EXCHANGE_DURABLE", true),
 					AutoDelete: getBoolEnv("RABBITMQ_EXCHANGE_AUTO_DELETE", false),
 				},
+				{
+					Entity:     "product",
+					Name:       getStringEnv("RABBITMQ_PRODUCT_EXCHANGE_NAME", "exchange.product"),
+					Type:       getStringEnv("RABBITMQ_EXCHANGE_TYPE", "direct"),
+					Durable:    getBoolEnv("RABBITMQ_EXCHANGE_DURABLE", true),
+					AutoDelete: getBoolEnv("RABBITMQ_EXCHANGE_AUTO_DELETE", false),
+				},
+				{
+					Entity:     "customer",
+					Name:       getStringEnv("RABBITMQ_CUSTOMER_EXCHANGE_NAME", "exchange.customer"),
+					Type:       getStringEnv("RABBITMQ_EXCHANGE_TYPE", "direct"),
+					Durable:    getBoolEnv("RABBITMQ_EXCHANGE_DURABLE", true),
+					AutoDelete: getBoolEnv("RABBITMQ_EXCHANGE_AUTO_DELETE", false),
+				},
 			},
 		},
 		Logger: LoggerConfig{
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+)
+
+func TestQuoteConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		wantErr bool
+	}{
+		{"empty secret is generated at startup", "", false},
+		{"long random secret", strings.Repeat("k", MinQuoteSecretLength), false},
+		{"placeholder", "change-me", true},
+		{"placeholder in another case", " CHANGEME ", true},
+		{"too short", strings.Repeat("k", MinQuoteSecretLength-1), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := QuoteConfig{Secret: tt.secret}.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestNewConfig_DeclaresAnExchangePerEventEntity guards against events that could not be
+// published because no exchange is configured for their entity.
+func TestNewConfig_DeclaresAnExchangePerEventEntity(t *testing.T) {
+	declared := make(map[string]bool)
+	for _, exchange := range NewConfig().RabbitMQ.ExchangeConfigs {
+		declared[exchange.Entity] = true
+	}
+
+	events := []domain.Event{
+		&domain.OrderCreatedEvent{},
+		&domain.OrderUpdateStatusEvent{},
+		&domain.OrderCancelledEvent{},
+		&domain.ProductLowStockEvent{},
+		&domain.ProductOutOfStockEvent{},
+		&domain.CustomerErasedEvent{},
+	}
+	for _, event := range events {
+		if !declared[event.GetEntityName()] {
+			t.Errorf("no exchange is configured for %T (entity %q)", event, event.GetEntityName())
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rafaelleal24/challenge/internal/adapters/http/handlers"
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/dto"
+	"github.com/rafaelleal24/challenge/internal/core/service"
+	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
+)
+
+type CategoryController struct {
+	categoryService *service.CategoryService
+}
+
+type CategoryResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	ParentID  string    `json:"parent_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CategoryListResponse struct {
+	Items []CategoryResponse `json:"items"`
+}
+
+func NewCategoryResponse(category *domain.Category) CategoryResponse {
+	return CategoryResponse{
+		ID:        string(category.ID),
+		Name:      category.Name,
+		ParentID:  string(category.ParentID),
+		CreatedAt: category.CreatedAt,
+		UpdatedAt: category.UpdatedAt,
+	}
+}
+
+func NewCategoryListResponse(categories []*domain.Category) CategoryListResponse {
+	items := make([]CategoryResponse, len(categories))
+	for i, category := range categories {
+		items[i] = NewCategoryResponse(category)
+	}
+	return CategoryListResponse{Items: items}
+}
+
+func NewCategoryController(categoryService *service.CategoryService) *CategoryController {
+	return &CategoryController{categoryService: categoryService}
+}
+
+// CreateCategory godoc
+// @Summary     Create a category
+// @Description Creates a product category, at the top level or under parent_id. Sibling categories must have distinct names.
+// @Tags        categories
+// @Accept      json
+// @Produce     json
+// @Param       request body     dto.CreateCategoryRequest true "Category data"
+// @Success     201     {object} CategoryResponse
+// @Failure     400     {object} handlers.ErrorResponse
+// @Failure     404     {object} handlers.ErrorResponse
+// @Failure     409     {object} handlers.ErrorResponse
+// @Failure     500     {object} handlers.ErrorResponse
+// @Router      /api/v1/categories [post]
+func (cc *CategoryController) CreateCategory(c *gin.Context) {
+	var request dto.CreateCategoryRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError(err.Error()))
+		return
+	}
+	category, err := cc.categoryService.CreateCategory(c.Request.Context(), &request)
+	if err != nil {
+		handlers.HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, NewCategoryResponse(category))
+}
+
+// ListCategories godoc
+// @Summary     List categories
+// @Description Returns every category by name; clients build the tree from parent_id.
+// @Tags        categories
+// @Produce     json
+// @Success     200 {object} CategoryListResponse
+// @Failure     500 {object} handlers.ErrorResponse
+// @Router      /api/v1/categories [get]
+func (cc *CategoryController) ListCategories(c *gin.Context) {
+	categories, err := cc.categoryService.ListCategories(c.Request.Context())
+	if err != nil {
+		handlers.HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, NewCategoryListResponse(categories))
+}
+
+// GetByID godoc
+// @Summary     Get category by ID
+// @Description Returns a single category by its ID
+// @Tags        categories
+// @Produce     json
+// @Param       id  path     string true "Category ID"
+// @Success     200 {object} CategoryResponse
+// @Failure     400 {object} handlers.ErrorResponse
+// @Failure     404 {object} handlers.ErrorResponse
+// @Failure     500 {object} handlers.ErrorResponse
+// @Router      /api/v1/categories/{id} [get]
+func (cc *CategoryController) GetByID(c *gin.Context) {
+	categoryID := c.Param("id")
+	if !domain.ValidateID(categoryID) {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError("Invalid category ID"))
+		return
+	}
+	category, err := cc.categoryService.GetByID(c.Request.Context(), domain.ID(categoryID))
+	if err != nil {
+		handlers.HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, NewCategoryResponse(category))
+}
+
+// UpdateCategory godoc
+// @Summary     Update a category
+// @Description Renames a category or moves it, with its subcategories, under another parent; an empty parent_id moves it to the top level.
+// @Description A category cannot be moved under itself or one of its subcategories.
+// @Tags        categories
+// @Accept      json
+// @Produce     json
+// @Param       id      path     string                    true "Category ID"
+// @Param       request body     dto.UpdateCategoryRequest true "Fields to update"
+// @Success     200     {object} CategoryResponse
+// @Failure     400     {object} handlers.ErrorResponse
+// @Failure     404     {object} handlers.ErrorResponse
+// @Failure     409     {object} handlers.ErrorResponse
+// @Failure     422     {object} handlers.ErrorResponse
+// @Failure     500     {object} handlers.ErrorResponse
+// @Router      /api/v1/categories/{id} [patch]
+func (cc *CategoryController) UpdateCategory(c *gin.Context) {
+	categoryID := c.Param("id")
+	if !domain.ValidateID(categoryID) {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError("Invalid category ID"))
+		return
+	}
+	var request dto.UpdateCategoryRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError(err.Error()))
+		return
+	}
+	category, err := cc.categoryService.UpdateCategory(c.Request.Context(), domain.ID(categoryID), &request)
+	if err != nil {
+		handlers.HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, NewCategoryResponse(category))
+}
+
+// DeleteCategory godoc
+// @Summary     Delete a category
+// @Description Deletes a category that has no subcategories and no products, archived ones included
+// @Tags        categories
+// @Param       id  path string true "Category ID"
+// @Success     204
+// @Failure     400 {object} handlers.ErrorResponse
+// @Failure     404 {object} handlers.ErrorResponse
+// @Failure     409 {object} handlers.ErrorResponse
+// @Failure     500 {object} handlers.ErrorResponse
+// @Router      /api/v1/categories/{id} [delete]
+func (cc *CategoryController) DeleteCategory(c *gin.Context) {
+	categoryID := c.Param("id")
+	if !domain.ValidateID(categoryID) {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError("Invalid category ID"))
+		return
+	}
+	if err := cc.categoryService.DeleteCategory(c.Request.Context(), domain.ID(categoryID)); err != nil {
+		handlers.HandleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -1,38 +1,337 @@
 package controllers
 
 import (
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rafaelleal24/challenge/internal/adapters/http/handlers"
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/dto"
 	"github.com/rafaelleal24/challenge/internal/core/service"
+	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
 )
 
+type AddressResponse struct {
+	Recipient  string `json:"recipient"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	State      string `json:"state,omitempty"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+}
+
+type CustomerAddressResponse struct {
+	ID    string `json:"id"`
+	Label string `json:"label,omitempty"`
+	AddressResponse
+	Default bool `json:"default"`
+}
+
 type CustomerResponse struct {
-	ID string `json:"id"`
+	ID        string                    `json:"id"`
+	Name      string                    `json:"name"`
+	Email     string                    `json:"email"`
+	Phone     string                    `json:"phone,omitempty"`
+	Addresses []CustomerAddressResponse `json:"addresses"`
+	ErasedAt  *time.Time                `json:"erased_at,omitempty"`
+	CreatedAt time.Time                 `json:"created_at"`
+	UpdatedAt time.Time                 `json:"updated_at"`
+}
+
+type CustomerExportResponse struct {
+	Customer   CustomerResponse `json:"customer"`
+	Orders     []OrderResponse  `json:"orders"`
+	ExportedAt time.Time        `json:"exported_at"`
+}
+
+func NewAddressResponse(address domain.Address) AddressResponse {
+	return AddressResponse{
+		Recipient:  address.Recipient,
+		Line1:      address.Line1,
+		Line2:      address.Line2,
+		City:       address.City,
+		State:      address.State,
+		PostalCode: address.PostalCode,
+		Country:    address.Country,
+	}
+}
+
+func NewCustomerAddressResponse(address *domain.CustomerAddress, defaultAddressID domain.ID) CustomerAddressResponse {
+	return CustomerAddressResponse{
+		ID:              string(address.ID),
+		Label:           address.Label,
+		AddressResponse: NewAddressResponse(address.Address),
+		Default:         address.ID == defaultAddressID,
+	}
+}
+
+func NewCustomerResponse(customer *domain.Customer) CustomerResponse {
+	addresses := make([]CustomerAddressResponse, len(customer.Addresses))
+	for i := range customer.Addresses {
+		addresses[i] = NewCustomerAddressResponse(&customer.Addresses[i], customer.DefaultAddressID)
+	}
+	return CustomerResponse{
+		ID:        string(customer.ID),
+		Name:      customer.Name,
+		Email:     customer.Email,
+		Phone:     customer.Phone,
+		Addresses: addresses,
+		ErasedAt:  customer.ErasedAt,
+		CreatedAt: customer.CreatedAt,
+		UpdatedAt: customer.UpdatedAt,
+	}
+}
+
+func NewCustomerExportResponse(export *domain.CustomerExport) CustomerExportResponse {
+	orders := make([]OrderResponse, len(export.Orders))
+	for i, order := range export.Orders {
+		orders[i] = NewOrderResponse(order)
+	}
+	return CustomerExportResponse{
+		Customer:   NewCustomerResponse(export.Customer),
+		Orders:     orders,
+		ExportedAt: export.ExportedAt,
+	}
 }
 
 type CustomerController struct {
-	customerService *service.CustomerService
+	customerService     *service.CustomerService
+	customerDataService *service.CustomerDataService
 }
 
-func NewCustomerController(customerService *service.CustomerService) *CustomerController {
-	return &CustomerController{customerService: customerService}
+func NewCustomerController(customerService *service.CustomerService, customerDataService *service.CustomerDataService) *CustomerController {
+	return &CustomerController{
+		customerService:     customerService,
+		customerDataService: customerDataService,
+	}
 }
 
 // CreateCustomer godoc
 // @Summary     Create a customer
-// @Description Creates a new customer and returns its ID
+// @Description Creates a customer profile. The email is stored in lower case and must not belong to another customer.
 // @Tags        customers
+// @Accept      json
 // @Produce     json
-// @Success     201 {object} CustomerResponse
-// @Failure     500 {object} handlers.ErrorResponse
+// @Param       request body     dto.CreateCustomerRequest true "Customer data"
+// @Success     201     {object} CustomerResponse
+// @Failure     400     {object} handlers.ErrorResponse
+// @Failure     409     {object} handlers.ErrorResponse
+// @Failure     500     {object} handlers.ErrorResponse
 // @Router      /api/v1/customers [post]
 func (cc *CustomerController) CreateCustomer(c *gin.Context) {
-	id, err := cc.customerService.Create(c.Request.Context())
+	var request dto.CreateCustomerRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError(err.Error()))
+		return
+	}
+	customer, err := cc.customerService.CreateCustomer(c.Request.Context(), &request)
+	if err != nil {
+		handlers.HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, NewCustomerResponse(customer))
+}
+
+// GetByID godoc
+// @Summary     Get customer by ID
+// @Description Returns a single customer profile by its ID
+// @Tags        customers
+// @Produce     json
+// @Param       id  path     string true "Customer ID"
+// @Success     200 {object} CustomerResponse
+// @Failure     400 {object} handlers.ErrorResponse
+// @Failure     404 {object} handlers.ErrorResponse
+// @Failure     500 {object} handlers.ErrorResponse
+// @Router      /api/v1/customers/{id} [get]
+func (cc *CustomerController) GetByID(c *gin.Context) {
+	customerID := c.Param("id")
+	if !domain.ValidateID(customerID) {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError("Invalid customer ID"))
+		return
+	}
+	customer, err := cc.customerService.GetByID(c.Request.Context(), domain.ID(customerID))
+	if err != nil {
+		handlers.HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, NewCustomerResponse(customer))
+}
+
+// UpdateCustomer godoc
+// @Summary     Update a customer
+// @Description Changes the given profile fields; an empty phone removes it. The email must not belong to another customer.
+// @Tags        customers
+// @Accept      json
+// @Produce     json
+// @Param       id      path     string                    true "Customer ID"
+// @Param       request body     dto.UpdateCustomerRequest true "Fields to update"
+// @Success     200     {object} CustomerResponse
+// @Failure     400     {object} handlers.ErrorResponse
+// @Failure     404     {object} handlers.ErrorResponse
+// @Failure     409     {object} handlers.ErrorResponse
+// @Failure     500     {object} handlers.ErrorResponse
+// @Router      /api/v1/customers/{id} [patch]
+func (cc *CustomerController) UpdateCustomer(c *gin.Context) {
+	customerID := c.Param("id")
+	if !domain.ValidateID(customerID) {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError("Invalid customer ID"))
+		return
+	}
+	var request dto.UpdateCustomerRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError(err.Error()))
+		return
+	}
+	customer, err := cc.customerService.UpdateCustomer(c.Request.Context(), domain.ID(customerID), &request)
+	if err != nil {
+		handlers.HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, NewCustomerResponse(customer))
+}
+
+// AddAddress godoc
+// @Summary     Add a customer address
+// @Description Saves an address in the customer's address book. The first address saved, or one saved with default set, becomes the default.
+// @Description A customer can hold up to 20 addresses.
+// @Tags        customers
+// @Accept      json
+// @Produce     json
+// @Param       id      path     string                 true "Customer ID"
+// @Param       request body     dto.SaveAddressRequest true "Address data"
+// @Success     201     {object} CustomerAddressResponse
+// @Failure     400     {object} handlers.ErrorResponse
+// @Failure     404     {object} handlers.ErrorResponse
+// @Failure     422     {object} handlers.ErrorResponse
+// @Failure     500     {object} handlers.ErrorResponse
+// @Router      /api/v1/customers/{id}/addresses [post]
+func (cc *CustomerController) AddAddress(c *gin.Context) {
+	customerID := c.Param("id")
+	if !domain.ValidateID(customerID) {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError("Invalid customer ID"))
+		return
+	}
+	var request dto.SaveAddressRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError(err.Error()))
+		return
+	}
+	customer, address, err := cc.customerService.AddAddress(c.Request.Context(), domain.ID(customerID), &request)
+	if err != nil {
+		handlers.HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, NewCustomerAddressResponse(address, customer.DefaultAddressID))
+}
+
+// ReplaceAddress godoc
+// @Summary     Replace a customer address
+// @Description Overwrites a saved address; orders already placed keep the address they were given.
+// @Description default: true makes it the default address. The default can only be moved to another address, not cleared.
+// @Tags        customers
+// @Accept      json
+// @Produce     json
+// @Param       id        path     string                 true "Customer ID"
+// @Param       addressId path     string                 true "Address ID"
+// @Param       request   body     dto.SaveAddressRequest true "Address data"
+// @Success     200       {object} CustomerAddressResponse
+// @Failure     400       {object} handlers.ErrorResponse
+// @Failure     404       {object} handlers.ErrorResponse
+// @Failure     500       {object} handlers.ErrorResponse
+// @Router      /api/v1/customers/{id}/addresses/{addressId} [put]
+func (cc *CustomerController) ReplaceAddress(c *gin.Context) {
+	customerID := c.Param("id")
+	if !domain.ValidateID(customerID) {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError("Invalid customer ID"))
+		return
+	}
+	var request dto.SaveAddressRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError(err.Error()))
+		return
+	}
+	customer, address, err := cc.customerService.ReplaceAddress(c.Request.Context(), domain.ID(customerID), domain.ID(c.Param("addressId")), &request)
+	if err != nil {
+		handlers.HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, NewCustomerAddressResponse(address, customer.DefaultAddressID))
+}
+
+// RemoveAddress godoc
+// @Summary     Remove a customer address
+// @Description Deletes a saved address. When it was the default, the first address left becomes the default.
+// @Tags        customers
+// @Param       id        path string true "Customer ID"
+// @Param       addressId path string true "Address ID"
+// @Success     204
+// @Failure     400 {object} handlers.ErrorResponse
+// @Failure     404 {object} handlers.ErrorResponse
+// @Failure     500 {object} handlers.ErrorResponse
+// @Router      /api/v1/customers/{id}/addresses/{addressId} [delete]
+func (cc *CustomerController) RemoveAddress(c *gin.Context) {
+	customerID := c.Param("id")
+	if !domain.ValidateID(customerID) {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError("Invalid customer ID"))
+		return
+	}
+	if err := cc.customerService.RemoveAddress(c.Request.Context(), domain.ID(customerID), domain.ID(c.Param("addressId"))); err != nil {
+		handlers.HandleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ExportCustomer godoc
+// @Summary     Export a customer's data
+// @Description Returns everything stored about the customer, their profile and all of their orders, as a JSON download.
+// @Tags        customers
+// @Produce     json
+// @Param       id  path     string true "Customer ID"
+// @Success     200 {object} CustomerExportResponse
+// @Failure     400 {object} handlers.ErrorResponse
+// @Failure     404 {object} handlers.ErrorResponse
+// @Failure     429 {object} handlers.ErrorResponse
+// @Failure     500 {object} handlers.ErrorResponse
+// @Router      /api/v1/customers/{id}/export [get]
+func (cc *CustomerController) ExportCustomer(c *gin.Context) {
+	customerID := c.Param("id")
+	if !domain.ValidateID(customerID) {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError("Invalid customer ID"))
+		return
+	}
+	export, err := cc.customerDataService.ExportCustomer(c.Request.Context(), domain.ID(customerID))
 	if err != nil {
 		handlers.HandleError(c, err)
 		return
 	}
-	c.JSON(http.StatusCreated, CustomerResponse{ID: string(id)})
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="customer-%s.json"`, customerID))
+	c.JSON(http.StatusOK, NewCustomerExportResponse(export))
+}
+
+// EraseCustomer godoc
+// @Summary     Erase a customer's personal data
+// @Description Anonymises the customer: the profile and address book are cleared and the shipping addresses of their orders keep only the country.
+// @Description Orders are kept for accounting. A customer.erased event is published, and erased customers can no longer place orders. Erasing twice has no further effect.
+// @Tags        customers
+// @Param       id  path string true "Customer ID"
+// @Success     204
+// @Failure     400 {object} handlers.ErrorResponse
+// @Failure     404 {object} handlers.ErrorResponse
+// @Failure     500 {object} handlers.ErrorResponse
+// @Router      /api/v1/customers/{id} [delete]
+func (cc *CustomerController) EraseCustomer(c *gin.Context) {
+	customerID := c.Param("id")
+	if !domain.ValidateID(customerID) {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError("Invalid customer ID"))
+		return
+	}
+	if err := cc.customerDataService.EraseCustomer(c.Request.Context(), domain.ID(customerID)); err != nil {
+		handlers.HandleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
 }
@@ -2,12 +2,15 @@ package controllers
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rafaelleal24/challenge/internal/adapters/http/handlers"
 	"github.com/rafaelleal24/challenge/internal/core/domain"
 	"github.com/rafaelleal24/challenge/internal/core/dto"
+	"github.com/rafaelleal24/challenge/internal/core/port"
 	"github.com/rafaelleal24/challenge/internal/core/service"
 	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
 )
@@ -19,6 +22,7 @@ type OrderController struct {
 type OrderItemResponse struct {
 	ID          string `json:"id"`
 	ProductID   string `json:"product_id"`
+	SKU         string `json:"sku,omitempty"`
 	ProductName string `json:"product_name"`
 	Quantity    int    `json:"quantity"`
 	UnitPrice   int    `json:"unit_price"`
@@ -26,45 +30,185 @@ type OrderItemResponse struct {
 
 type UpdateStatusRequest struct {
 	Status string `json:"status"`
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+type CancelOrderRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
 }
 
 type MessageResponse struct {
 	Message string `json:"message"`
 }
 
+type OrderStatusChangeResponse struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	ChangedAt time.Time `json:"changed_at"`
+	Actor     string    `json:"actor,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
 type OrderResponse struct {
-	ID          string              `json:"id"`
-	CustomerID  string              `json:"customer_id"`
-	Items       []OrderItemResponse `json:"items"`
-	Status      string              `json:"status"`
-	CreatedAt   time.Time           `json:"created_at"`
+	ID              string                      `json:"id"`
+	CustomerID      string                      `json:"customer_id"`
+	ShippingAddress *AddressResponse            `json:"shipping_address,omitempty"`
+	Items           []OrderItemResponse         `json:"items"`
+	Status          string                      `json:"status"`
+	StatusHistory   []OrderStatusChangeResponse `json:"status_history"`
+	CreatedAt       time.Time                   `json:"created_at"`
+	TotalAmount     int                         `json:"total_amount"`
+	UpdatedAt       time.Time                   `json:"updated_at"`
+	Version         int64                       `json:"version"`
+}
+
+type OrderListResponse struct {
+	Items      []OrderResponse `json:"items"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+	Total      *int64          `json:"total,omitempty"`
+}
+
+type QuoteItemResponse struct {
+	ProductID      string `json:"product_id"`
+	SKU            string `json:"sku,omitempty"`
+	ProductName    string `json:"product_name"`
+	Quantity       int    `json:"quantity"`
+	UnitPrice      int    `json:"unit_price"`
+	LineTotal      int    `json:"line_total"`
+	AvailableStock int    `json:"available_stock"`
+	Available      bool   `json:"available"`
+}
+
+type QuoteResponse struct {
+	Items       []QuoteItemResponse `json:"items"`
 	TotalAmount int                 `json:"total_amount"`
-	UpdatedAt   time.Time           `json:"updated_at"`
+	Available   bool                `json:"available"`
+	QuoteToken  string              `json:"quote_token"`
+	ExpiresAt   time.Time           `json:"expires_at"`
+}
+
+// actorHeader identifies who performed a change; it is expected to be set by the API gateway.
+const actorHeader = "X-Actor-ID"
+
+func orderETag(order *domain.Order) string {
+	return strconv.Quote(strconv.FormatInt(order.Version, 10))
+}
+
+// parseIfMatch returns the order versions named by the If-Match header, or nil when the
+// header is absent or "*". The header may list several entity tags; tags that are not order
+// versions never match, so a header naming none of them rejects every version.
+func parseIfMatch(c *gin.Context) ([]int64, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+	invalid := serviceerrors.NewInvalidRequestError("invalid If-Match header")
+	versions := []int64{}
+	for rest := header; rest != ""; {
+		rest = strings.TrimPrefix(rest, "W/")
+		if !strings.HasPrefix(rest, `"`) {
+			return nil, invalid
+		}
+		end := strings.IndexByte(rest[1:], '"') + 1
+		if end == 0 {
+			return nil, invalid
+		}
+		if version, err := strconv.ParseInt(rest[1:end], 10, 64); err == nil {
+			versions = append(versions, version)
+		}
+		rest = strings.TrimSpace(rest[end+1:])
+		if rest != "" && !strings.HasPrefix(rest, ",") {
+			return nil, invalid
+		}
+		rest = strings.TrimSpace(strings.TrimPrefix(rest, ","))
+	}
+	return versions, nil
 }
 
 func NewOrderItemResponse(item domain.OrderItem) OrderItemResponse {
 	return OrderItemResponse{
 		ID:          string(item.ID),
 		ProductID:   string(item.ProductID),
+		SKU:         item.SKU,
 		ProductName: item.ProductName,
 		Quantity:    item.Quantity,
 		UnitPrice:   int(item.UnitPrice),
 	}
 }
 
+func NewOrderStatusChangeResponse(change domain.OrderStatusChange) OrderStatusChangeResponse {
+	return OrderStatusChangeResponse{
+		From:      string(change.From),
+		To:        string(change.To),
+		ChangedAt: change.ChangedAt,
+		Actor:     change.Actor,
+		Reason:    change.Reason,
+	}
+}
+
+func NewOrderStatusHistoryResponse(history []domain.OrderStatusChange) []OrderStatusChangeResponse {
+	response := make([]OrderStatusChangeResponse, len(history))
+	for i, change := range history {
+		response[i] = NewOrderStatusChangeResponse(change)
+	}
+	return response
+}
+
 func NewOrderResponse(order *domain.Order) OrderResponse {
 	items := make([]OrderItemResponse, len(order.Items))
 	for i, item := range order.Items {
 		items[i] = NewOrderItemResponse(item)
 	}
-	return OrderResponse{
-		ID:          string(order.ID),
-		CustomerID:  string(order.CustomerID),
+	response := OrderResponse{
+		ID:            string(order.ID),
+		CustomerID:    string(order.CustomerID),
+		Items:         items,
+		Status:        string(order.Status),
+		StatusHistory: NewOrderStatusHistoryResponse(order.StatusHistory),
+		CreatedAt:     order.CreatedAt,
+		TotalAmount:   int(order.TotalAmount),
+		UpdatedAt:     order.UpdatedAt,
+		Version:       order.Version,
+	}
+	if order.ShippingAddress != nil {
+		address := NewAddressResponse(*order.ShippingAddress)
+		response.ShippingAddress = &address
+	}
+	return response
+}
+
+func NewOrderListResponse(page *port.Page[*domain.Order]) OrderListResponse {
+	items := make([]OrderResponse, len(page.Items))
+	for i, order := range page.Items {
+		items[i] = NewOrderResponse(order)
+	}
+	return OrderListResponse{
+		Items:      items,
+		NextCursor: page.NextCursor,
+		Total:      page.Total,
+	}
+}
+
+func NewQuoteResponse(quote *domain.Quote) QuoteResponse {
+	items := make([]QuoteItemResponse, len(quote.Items))
+	for i, item := range quote.Items {
+		items[i] = QuoteItemResponse{
+			ProductID:      string(item.ProductID),
+			SKU:            item.SKU,
+			ProductName:    item.ProductName,
+			Quantity:       item.Quantity,
+			UnitPrice:      int(item.UnitPrice),
+			LineTotal:      int(item.LineTotal),
+			AvailableStock: item.AvailableStock,
+			Available:      item.IsAvailable(),
+		}
+	}
+	return QuoteResponse{
 		Items:       items,
-		Status:      string(order.Status),
-		CreatedAt:   order.CreatedAt,
-		TotalAmount: int(order.TotalAmount),
-		UpdatedAt:   order.UpdatedAt,
+		TotalAmount: int(quote.TotalAmount),
+		Available:   quote.IsAvailable(),
+		QuoteToken:  quote.Token,
+		ExpiresAt:   quote.ExpiresAt,
 	}
 }
 
@@ -74,15 +218,20 @@ func NewOrderController(orderService *service.OrderService) *OrderController {
 
 // UpdateOrderStatus godoc
 // @Summary     Update order status
-// @Description Updates the status of an existing order
+// @Description Updates the status of an existing order following the allowed status transitions.
+// @Description Moving an order to cancelled restores its stock, same as the cancel endpoint.
+// @Description Send the ETag from GET /orders/{id} as If-Match to reject the update if the order changed meanwhile.
 // @Tags        orders
 // @Accept      json
 // @Produce     json
-// @Param       id      path     string              true "Order ID"
-// @Param       request body     UpdateStatusRequest  true "New status"
+// @Param       id         path     string              true  "Order ID"
+// @Param       If-Match   header   string              false "ETags of the order versions the update applies to, or *"
+// @Param       X-Actor-ID header   string              false "Who is changing the status"
+// @Param       request    body     UpdateStatusRequest true  "New status"
 // @Success     200     {object} MessageResponse
 // @Failure     400     {object} handlers.ErrorResponse
 // @Failure     404     {object} handlers.ErrorResponse
+// @Failure     409     {object} handlers.ErrorResponse
 // @Failure     422     {object} handlers.ErrorResponse
 // @Failure     429     {object} handlers.ErrorResponse
 // @Failure     500     {object} handlers.ErrorResponse
@@ -98,16 +247,93 @@ func (orderController *OrderController) UpdateOrderStatus(c *gin.Context) {
 		handlers.HandleError(c, serviceerrors.NewInvalidRequestError(err.Error()))
 		return
 	}
-	if err := orderController.orderService.UpdateOrderStatus(c.Request.Context(), domain.ID(orderID), domain.OrderStatus(request.Status)); err != nil {
+	expectedVersions, err := parseIfMatch(c)
+	if err != nil {
+		handlers.HandleError(c, err)
+		return
+	}
+	actor := c.GetHeader(actorHeader)
+	if err := orderController.orderService.UpdateOrderStatus(c.Request.Context(), domain.ID(orderID), domain.OrderStatus(request.Status), expectedVersions, actor, request.Reason); err != nil {
 		handlers.HandleError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, MessageResponse{Message: "Order status updated successfully"})
 }
 
+// CancelOrder godoc
+// @Summary     Cancel an order
+// @Description Cancels an order and restores the stock of its items
+// @Tags        orders
+// @Accept      json
+// @Produce     json
+// @Param       id         path     string             true  "Order ID"
+// @Param       X-Actor-ID header   string             false "Who is cancelling the order"
+// @Param       request    body     CancelOrderRequest true  "Cancellation reason"
+// @Success     200     {object} OrderResponse
+// @Header      200     {string} ETag "Order version"
+// @Failure     400     {object} handlers.ErrorResponse
+// @Failure     404     {object} handlers.ErrorResponse
+// @Failure     409     {object} handlers.ErrorResponse
+// @Failure     422     {object} handlers.ErrorResponse
+// @Failure     429     {object} handlers.ErrorResponse
+// @Failure     500     {object} handlers.ErrorResponse
+// @Router      /api/v1/orders/{id}/cancel [post]
+func (orderController *OrderController) CancelOrder(c *gin.Context) {
+	orderID := c.Param("id")
+	if !domain.ValidateID(orderID) {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError("Invalid order ID"))
+		return
+	}
+	var request CancelOrderRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError(err.Error()))
+		return
+	}
+	actor := c.GetHeader(actorHeader)
+	order, err := orderController.orderService.CancelOrder(c.Request.Context(), domain.ID(orderID), actor, request.Reason)
+	if err != nil {
+		handlers.HandleError(c, err)
+		return
+	}
+	c.Header("ETag", orderETag(order))
+	c.JSON(http.StatusOK, NewOrderResponse(order))
+}
+
+// QuoteOrder godoc
+// @Summary     Quote an order
+// @Description Prices the items and reports stock availability without reserving stock.
+// @Description The returned quote_token can be sent when creating the order so prices do not rise above the quoted ones until it expires.
+// @Tags        orders
+// @Accept      json
+// @Produce     json
+// @Param       request body     dto.QuoteOrderRequest true "Items to quote"
+// @Success     200     {object} QuoteResponse
+// @Failure     400     {object} handlers.ErrorResponse
+// @Failure     404     {object} handlers.ErrorResponse
+// @Failure     422     {object} handlers.ErrorResponse
+// @Failure     429     {object} handlers.ErrorResponse
+// @Failure     500     {object} handlers.ErrorResponse
+// @Router      /api/v1/orders/quote [post]
+func (orderController *OrderController) QuoteOrder(c *gin.Context) {
+	var request dto.QuoteOrderRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError(err.Error()))
+		return
+	}
+	quote, err := orderController.orderService.QuoteOrder(c.Request.Context(), &request)
+	if err != nil {
+		handlers.HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, NewQuoteResponse(quote))
+}
+
 // CreateOrder godoc
 // @Summary     Create an order
-// @Description Creates a new order with stock deduction and idempotency support
+// @Description Creates a new order with stock deduction and idempotency support.
+// @Description When quote_token is set, each item is charged the lower of its quoted and current price, as long as the quote has not expired and the items match it.
+// @Description The order ships to shipping_address_id, one of the customer's saved addresses, or to an inline shipping_address; the address is copied into the order.
+// @Description Missing products are listed together in details.product_ids (404), and products short of stock in details.insufficient_stock (422).
 // @Tags        orders
 // @Accept      json
 // @Produce     json
@@ -136,6 +362,76 @@ func (OrderController *OrderController) CreateOrder(c *gin.Context) {
 	c.JSON(http.StatusCreated, NewOrderResponse(order))
 }
 
+// ListOrders godoc
+// @Summary     List orders
+// @Description Returns orders matching the given filters, newest first by default.
+// @Description Pass next_cursor from the previous response as cursor to fetch the next page.
+// @Tags        orders
+// @Produce     json
+// @Param       customer_id   query    string  false "Customer ID"
+// @Param       status        query    string  false "Order status" Enums(created, processing, shipped, delivered, cancelled)
+// @Param       created_from  query    string  false "Created at or after (RFC3339)"
+// @Param       created_to    query    string  false "Created at or before (RFC3339)"
+// @Param       min_total     query    int     false "Minimum total amount in cents"
+// @Param       max_total     query    int     false "Maximum total amount in cents"
+// @Param       sort          query    string  false "Sort field" Enums(created_at, total_amount)
+// @Param       order         query    string  false "Sort direction" Enums(asc, desc)
+// @Param       limit         query    int     false "Page size (1-100, default 20)"
+// @Param       cursor        query    string  false "Cursor returned by the previous page"
+// @Param       include_total query    bool    false "Include the total number of matching orders"
+// @Success     200           {object} OrderListResponse
+// @Failure     400           {object} handlers.ErrorResponse
+// @Failure     500           {object} handlers.ErrorResponse
+// @Router      /api/v1/orders [get]
+func (orderController *OrderController) ListOrders(c *gin.Context) {
+	var request dto.ListOrdersRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError(err.Error()))
+		return
+	}
+	page, err := orderController.orderService.ListOrders(c.Request.Context(), &request)
+	if err != nil {
+		handlers.HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, NewOrderListResponse(page))
+}
+
+// ListCustomerOrders godoc
+// @Summary     List a customer's orders
+// @Description Returns the customer's orders newest first, optionally only those in the given status.
+// @Description Pass next_cursor from the previous response as cursor to fetch the next page.
+// @Description Orders of erased customers are kept, with their shipping addresses stripped, and are still listed.
+// @Tags        customers
+// @Produce     json
+// @Param       id     path     string true  "Customer ID"
+// @Param       status query    string false "Order status" Enums(created, processing, shipped, delivered, cancelled)
+// @Param       limit  query    int    false "Page size (1-100, default 20)"
+// @Param       cursor query    string false "Cursor returned by the previous page"
+// @Success     200    {object} OrderListResponse
+// @Failure     400    {object} handlers.ErrorResponse
+// @Failure     404    {object} handlers.ErrorResponse
+// @Failure     500    {object} handlers.ErrorResponse
+// @Router      /api/v1/customers/{id}/orders [get]
+func (orderController *OrderController) ListCustomerOrders(c *gin.Context) {
+	customerID := c.Param("id")
+	if !domain.ValidateID(customerID) {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError("Invalid customer ID"))
+		return
+	}
+	var request dto.ListCustomerOrdersRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError(err.Error()))
+		return
+	}
+	page, err := orderController.orderService.ListCustomerOrders(c.Request.Context(), domain.ID(customerID), &request)
+	if err != nil {
+		handlers.HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, NewOrderListResponse(page))
+}
+
 // GetOrderByID godoc
 // @Summary     Get order by ID
 // @Description Returns a single order by its ID
@@ -143,6 +439,7 @@ func (OrderController *OrderController) CreateOrder(c *gin.Context) {
 // @Produce     json
 // @Param       id  path     string true "Order ID"
 // @Success     200 {object} OrderResponse
+// @Header      200 {string} ETag "Order version, to be sent as If-Match on updates"
 // @Failure     400 {object} handlers.ErrorResponse
 // @Failure     404 {object} handlers.ErrorResponse
 // @Failure     500 {object} handlers.ErrorResponse
@@ -158,5 +455,31 @@ func (orderController *OrderController) GetOrderByID(c *gin.Context) {
 		handlers.HandleError(c, err)
 		return
 	}
+	c.Header("ETag", orderETag(order))
 	c.JSON(http.StatusOK, NewOrderResponse(order))
 }
+
+// GetOrderStatusHistory godoc
+// @Summary     Get order status history
+// @Description Returns the status changes of an order, oldest first
+// @Tags        orders
+// @Produce     json
+// @Param       id  path     string true "Order ID"
+// @Success     200 {array}  OrderStatusChangeResponse
+// @Failure     400 {object} handlers.ErrorResponse
+// @Failure     404 {object} handlers.ErrorResponse
+// @Failure     500 {object} handlers.ErrorResponse
+// @Router      /api/v1/orders/{id}/history [get]
+func (orderController *OrderController) GetOrderStatusHistory(c *gin.Context) {
+	orderID := c.Param("id")
+	if !domain.ValidateID(orderID) {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError("Invalid order ID"))
+		return
+	}
+	history, err := orderController.orderService.GetOrderStatusHistory(c.Request.Context(), domain.ID(orderID))
+	if err != nil {
+		handlers.HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, NewOrderStatusHistoryResponse(history))
+}
@@ -1,13 +1,16 @@
 package controllers
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rafaelleal24/challenge/internal/adapters/http/handlers"
 	"github.com/rafaelleal24/challenge/internal/core/domain"
 	"github.com/rafaelleal24/challenge/internal/core/dto"
+	"github.com/rafaelleal24/challenge/internal/core/port"
 	"github.com/rafaelleal24/challenge/internal/core/service"
 	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
 )
@@ -17,24 +20,121 @@ type ProductController struct {
 }
 
 type ProductResponse struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Price       int       `json:"price"`
-	Stock       int       `json:"stock"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID                string                   `json:"id"`
+	SKU               string                   `json:"sku,omitempty"`
+	Name              string                   `json:"name"`
+	Description       string                   `json:"description"`
+	Price             int                      `json:"price"`
+	Stock             int                      `json:"stock"`
+	Reserved          int                      `json:"reserved_stock"`
+	Available         int                      `json:"available_stock"`
+	LowStockThreshold *int                     `json:"low_stock_threshold,omitempty"`
+	Variants          []ProductVariantResponse `json:"variants,omitempty"`
+	CategoryID        string                   `json:"category_id,omitempty"`
+	Tags              []string                 `json:"tags,omitempty"`
+	ArchivedAt        *time.Time               `json:"archived_at,omitempty"`
+	CreatedAt         time.Time                `json:"created_at"`
+	UpdatedAt         time.Time                `json:"updated_at"`
+}
+
+type ProductVariantResponse struct {
+	SKU       string `json:"sku"`
+	Name      string `json:"name"`
+	Price     int    `json:"price"`
+	Stock     int    `json:"stock"`
+	Reserved  int    `json:"reserved_stock"`
+	Available int    `json:"available_stock"`
 }
 
 func NewProductResponse(product *domain.Product) ProductResponse {
+	var variants []ProductVariantResponse
+	for _, variant := range product.Variants {
+		variants = append(variants, ProductVariantResponse{
+			SKU:       variant.SKU,
+			Name:      variant.Name,
+			Price:     int(product.PriceOf(variant.SKU)),
+			Stock:     variant.Stock,
+			Reserved:  variant.Reserved,
+			Available: variant.AvailableStock(),
+		})
+	}
+
 	return ProductResponse{
-		ID:          string(product.ID),
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       int(product.Price),
-		Stock:       product.Stock,
-		CreatedAt:   product.CreatedAt,
-		UpdatedAt:   product.UpdatedAt,
+		ID:                string(product.ID),
+		SKU:               product.SKU,
+		Name:              product.Name,
+		Description:       product.Description,
+		Price:             int(product.Price),
+		Stock:             product.Stock,
+		Reserved:          product.Reserved,
+		Available:         product.AvailableStock(),
+		LowStockThreshold: product.LowStockThreshold,
+		Variants:          variants,
+		CategoryID:        string(product.CategoryID),
+		Tags:              product.Tags,
+		ArchivedAt:        product.ArchivedAt,
+		CreatedAt:         product.CreatedAt,
+		UpdatedAt:         product.UpdatedAt,
+	}
+}
+
+type StockMovementResponse struct {
+	ID        string    `json:"id"`
+	ProductID string    `json:"product_id"`
+	SKU       string    `json:"sku,omitempty"`
+	OrderID   string    `json:"order_id,omitempty"`
+	Quantity  int       `json:"quantity"`
+	Reason    string    `json:"reason"`
+	Actor     string    `json:"actor,omitempty"`
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type StockMovementListResponse struct {
+	Items      []StockMovementResponse `json:"items"`
+	NextCursor string                  `json:"next_cursor,omitempty"`
+}
+
+func NewStockMovementResponse(movement *domain.StockMovement) StockMovementResponse {
+	return StockMovementResponse{
+		ID:        string(movement.ID),
+		ProductID: string(movement.ProductID),
+		SKU:       movement.SKU,
+		OrderID:   string(movement.OrderID),
+		Quantity:  movement.Quantity,
+		Reason:    string(movement.Reason),
+		Actor:     movement.Actor,
+		Note:      movement.Note,
+		CreatedAt: movement.CreatedAt,
+	}
+}
+
+func NewStockMovementListResponse(page *port.Page[*domain.StockMovement]) StockMovementListResponse {
+	items := make([]StockMovementResponse, len(page.Items))
+	for i, movement := range page.Items {
+		items[i] = NewStockMovementResponse(movement)
+	}
+	return StockMovementListResponse{
+		Items:      items,
+		NextCursor: page.NextCursor,
+	}
+}
+
+type ProductListResponse struct {
+	Items      []ProductResponse `json:"items"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+	Total      *int64            `json:"total,omitempty"`
+}
+
+func NewProductListResponse(page *port.Page[*domain.Product]) ProductListResponse {
+	items := make([]ProductResponse, len(page.Items))
+	for i, product := range page.Items {
+		items[i] = NewProductResponse(product)
+	}
+	return ProductListResponse{
+		Items:      items,
+		NextCursor: page.NextCursor,
+		Total:      page.Total,
 	}
 }
 
@@ -44,13 +144,17 @@ func NewProductController(productService *service.ProductService) *ProductContro
 
 // CreateProduct godoc
 // @Summary     Create a product
-// @Description Creates a new product
+// @Description Creates a new product. Products sold in several sizes or colours list them as variants,
+// @Description each with a SKU unique across products, its own stock and optionally its own price.
+// @Description category_id files the product under an existing category.
 // @Tags        products
 // @Accept      json
 // @Produce     json
 // @Param       request body     dto.CreateProductRequest true "Product data"
 // @Success     201     {object} ProductResponse
 // @Failure     400     {object} handlers.ErrorResponse
+// @Failure     404     {object} handlers.ErrorResponse
+// @Failure     409     {object} handlers.ErrorResponse
 // @Failure     500     {object} handlers.ErrorResponse
 // @Router      /api/v1/products [post]
 func (pc *ProductController) CreateProduct(c *gin.Context) {
@@ -67,25 +171,240 @@ func (pc *ProductController) CreateProduct(c *gin.Context) {
 	c.JSON(http.StatusCreated, NewProductResponse(product))
 }
 
-// GetAll godoc
-// @Summary     List all products
-// @Description Returns all products
+// ListProducts godoc
+// @Summary     List products
+// @Description Returns products that are not archived, matching the given filters, by name by default.
+// @Description Filtering by category also lists the products of its subcategories.
+// @Description Pass next_cursor from the previous response as cursor to fetch the next page.
+// @Description Deprecated: without limit, cursor or include_total the response is a bare array of up to 100 matching products instead of the paginated envelope.
+// @Description When more products match, a Link header with rel="next" points at the next page of the paginated listing.
 // @Tags        products
 // @Produce     json
-// @Success     200 {array} ProductResponse
-// @Failure     500 {object} handlers.ErrorResponse
+// @Param       name_prefix   query    string  false "Name prefix (case-sensitive)"
+// @Param       min_price     query    int     false "Minimum price in cents"
+// @Param       max_price     query    int     false "Maximum price in cents"
+// @Param       in_stock      query    bool    false "Only products with available stock"
+// @Param       category      query    string  false "Category ID, including its subcategories"
+// @Param       tag           query    string  false "Tag (case-insensitive)"
+// @Param       sort          query    string  false "Sort field" Enums(name, price, created_at)
+// @Param       order         query    string  false "Sort direction" Enums(asc, desc)
+// @Param       limit         query    int     false "Page size (1-100, default 20)"
+// @Param       cursor        query    string  false "Cursor returned by the previous page"
+// @Param       include_total query    bool    false "Include the total number of matching products"
+// @Success     200           {object} ProductListResponse
+// @Failure     400           {object} handlers.ErrorResponse
+// @Failure     404           {object} handlers.ErrorResponse
+// @Failure     500           {object} handlers.ErrorResponse
 // @Router      /api/v1/products [get]
-func (pc *ProductController) GetAll(c *gin.Context) {
-	products, err := pc.productService.GetAll(c.Request.Context())
+func (pc *ProductController) ListProducts(c *gin.Context) {
+	var request dto.ListProductsRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError(err.Error()))
+		return
+	}
+	if !request.IsPaginated() {
+		page, err := pc.productService.ListLegacyProducts(c.Request.Context(), &request)
+		if err != nil {
+			handlers.HandleError(c, err)
+			return
+		}
+		if page.NextCursor != "" {
+			next := *c.Request.URL
+			query := next.Query()
+			query.Set("cursor", page.NextCursor)
+			query.Set("limit", strconv.Itoa(len(page.Items)))
+			next.RawQuery = query.Encode()
+			c.Header("Link", fmt.Sprintf(`<%s>; rel="next"`, next.RequestURI()))
+		}
+		response := make([]ProductResponse, len(page.Items))
+		for i, product := range page.Items {
+			response[i] = NewProductResponse(product)
+		}
+		c.JSON(http.StatusOK, response)
+		return
+	}
+	page, err := pc.productService.ListProducts(c.Request.Context(), &request)
+	if err != nil {
+		handlers.HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, NewProductListResponse(page))
+}
+
+// SearchProducts godoc
+// @Summary     Search products
+// @Description Full-text search over product name and description, most relevant first.
+// @Description Archived products are excluded. Pass next_cursor from the previous response as cursor to fetch the next page.
+// @Tags        products
+// @Produce     json
+// @Param       q             query    string  true  "Search terms"
+// @Param       limit         query    int     false "Page size (1-100, default 20)"
+// @Param       cursor        query    string  false "Cursor returned by the previous page"
+// @Param       include_total query    bool    false "Include the total number of matching products"
+// @Success     200           {object} ProductListResponse
+// @Failure     400           {object} handlers.ErrorResponse
+// @Failure     500           {object} handlers.ErrorResponse
+// @Router      /api/v1/products/search [get]
+func (pc *ProductController) SearchProducts(c *gin.Context) {
+	var request dto.SearchProductsRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError(err.Error()))
+		return
+	}
+	page, err := pc.productService.SearchProducts(c.Request.Context(), &request)
+	if err != nil {
+		handlers.HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, NewProductListResponse(page))
+}
+
+// GetByID godoc
+// @Summary     Get product by ID
+// @Description Returns a single product by its ID, including archived products
+// @Tags        products
+// @Produce     json
+// @Param       id  path     string true "Product ID"
+// @Success     200 {object} ProductResponse
+// @Failure     400 {object} handlers.ErrorResponse
+// @Failure     404 {object} handlers.ErrorResponse
+// @Failure     500 {object} handlers.ErrorResponse
+// @Router      /api/v1/products/{id} [get]
+func (pc *ProductController) GetByID(c *gin.Context) {
+	productID := c.Param("id")
+	if !domain.ValidateID(productID) {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError("Invalid product ID"))
+		return
+	}
+	product, err := pc.productService.GetByID(c.Request.Context(), domain.ID(productID))
+	if err != nil {
+		handlers.HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, NewProductResponse(product))
+}
+
+// UpdateProduct godoc
+// @Summary     Update a product
+// @Description Updates the name, description, price, low-stock threshold, category or tags of a product; omitted fields are left unchanged
+// @Tags        products
+// @Accept      json
+// @Produce     json
+// @Param       id      path     string                   true "Product ID"
+// @Param       request body     dto.UpdateProductRequest true "Fields to update"
+// @Success     200     {object} ProductResponse
+// @Failure     400     {object} handlers.ErrorResponse
+// @Failure     404     {object} handlers.ErrorResponse
+// @Failure     422     {object} handlers.ErrorResponse
+// @Failure     500     {object} handlers.ErrorResponse
+// @Router      /api/v1/products/{id} [patch]
+func (pc *ProductController) UpdateProduct(c *gin.Context) {
+	productID := c.Param("id")
+	if !domain.ValidateID(productID) {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError("Invalid product ID"))
+		return
+	}
+	var request dto.UpdateProductRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError(err.Error()))
+		return
+	}
+	product, err := pc.productService.UpdateProduct(c.Request.Context(), domain.ID(productID), &request)
 	if err != nil {
 		handlers.HandleError(c, err)
 		return
 	}
+	c.JSON(http.StatusOK, NewProductResponse(product))
+}
+
+// ArchiveProduct godoc
+// @Summary     Archive a product
+// @Description Archives a product: it is hidden from listings and can no longer be ordered
+// @Tags        products
+// @Param       id  path string true "Product ID"
+// @Success     204
+// @Failure     400 {object} handlers.ErrorResponse
+// @Failure     404 {object} handlers.ErrorResponse
+// @Failure     500 {object} handlers.ErrorResponse
+// @Router      /api/v1/products/{id} [delete]
+func (pc *ProductController) ArchiveProduct(c *gin.Context) {
+	productID := c.Param("id")
+	if !domain.ValidateID(productID) {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError("Invalid product ID"))
+		return
+	}
+	if err := pc.productService.ArchiveProduct(c.Request.Context(), domain.ID(productID)); err != nil {
+		handlers.HandleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
 
-	response := make([]ProductResponse, len(products))
-	for i, product := range products {
-		response[i] = NewProductResponse(product)
+// AdjustStock godoc
+// @Summary     Adjust product stock
+// @Description Adds or removes stock by hand and records the movement. Restocks and returns add stock;
+// @Description corrections may also remove it, but never below the units held by reservations.
+// @Description Products with variants are adjusted one variant at a time, selected by sku.
+// @Tags        products
+// @Accept      json
+// @Produce     json
+// @Param       id         path     string                     true  "Product ID"
+// @Param       X-Actor-ID header   string                     false "Who performs the adjustment"
+// @Param       request    body     dto.StockAdjustmentRequest true  "Stock adjustment"
+// @Success     201        {object} StockMovementResponse
+// @Failure     400        {object} handlers.ErrorResponse
+// @Failure     404        {object} handlers.ErrorResponse
+// @Failure     422        {object} handlers.ErrorResponse
+// @Failure     500        {object} handlers.ErrorResponse
+// @Router      /api/v1/products/{id}/stock-adjustments [post]
+func (pc *ProductController) AdjustStock(c *gin.Context) {
+	productID := c.Param("id")
+	if !domain.ValidateID(productID) {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError("Invalid product ID"))
+		return
+	}
+	var request dto.StockAdjustmentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError(err.Error()))
+		return
+	}
+	movement, err := pc.productService.AdjustStock(c.Request.Context(), domain.ID(productID), &request, c.GetHeader(actorHeader))
+	if err != nil {
+		handlers.HandleError(c, err)
+		return
 	}
+	c.JSON(http.StatusCreated, NewStockMovementResponse(movement))
+}
 
-	c.JSON(http.StatusOK, response)
+// GetStockMovements godoc
+// @Summary     List stock movements
+// @Description Returns the stock movements of a product, newest first.
+// @Description Pass next_cursor from the previous response as cursor to fetch the next page.
+// @Tags        products
+// @Produce     json
+// @Param       id     path     string true  "Product ID"
+// @Param       limit  query    int    false "Page size (1-100, default 20)"
+// @Param       cursor query    string false "Cursor returned by the previous page"
+// @Success     200    {object} StockMovementListResponse
+// @Failure     400    {object} handlers.ErrorResponse
+// @Failure     404    {object} handlers.ErrorResponse
+// @Failure     500    {object} handlers.ErrorResponse
+// @Router      /api/v1/products/{id}/stock-movements [get]
+func (pc *ProductController) GetStockMovements(c *gin.Context) {
+	productID := c.Param("id")
+	if !domain.ValidateID(productID) {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError("Invalid product ID"))
+		return
+	}
+	var request dto.ListStockMovementsRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError(err.Error()))
+		return
+	}
+	page, err := pc.productService.GetStockMovements(c.Request.Context(), domain.ID(productID), &request)
+	if err != nil {
+		handlers.HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, NewStockMovementListResponse(page))
 }
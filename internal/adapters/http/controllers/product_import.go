@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rafaelleal24/challenge/internal/adapters/http/handlers"
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/dto"
+	"github.com/rafaelleal24/challenge/internal/core/logger"
+	"github.com/rafaelleal24/challenge/internal/core/service"
+	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
+)
+
+// productImportMaxBytes bounds an upload; at about 800 bytes a row it leaves room for
+// service.ProductImportMaxRows rows.
+const productImportMaxBytes = 8 << 20
+
+var productImportContentTypes = map[domain.ProductImportFormat]string{
+	domain.ProductImportFormatCSV:    "text/csv",
+	domain.ProductImportFormatNDJSON: "application/x-ndjson",
+}
+
+type ProductImportController struct {
+	importService *service.ProductImportService
+}
+
+type ProductImportResponse struct {
+	ID         string                      `json:"id"`
+	Format     string                      `json:"format"`
+	Status     string                      `json:"status"`
+	Total      int                         `json:"total_rows"`
+	Processed  int                         `json:"processed_rows"`
+	Created    int                         `json:"created"`
+	Updated    int                         `json:"updated"`
+	Failed     int                         `json:"failed"`
+	Errors     []domain.ProductImportError `json:"errors,omitempty"`
+	CreatedAt  time.Time                   `json:"created_at"`
+	StartedAt  *time.Time                  `json:"started_at,omitempty"`
+	FinishedAt *time.Time                  `json:"finished_at,omitempty"`
+}
+
+func NewProductImportResponse(productImport *domain.ProductImport) ProductImportResponse {
+	return ProductImportResponse{
+		ID:         string(productImport.ID),
+		Format:     string(productImport.Format),
+		Status:     string(productImport.Status),
+		Total:      productImport.Total,
+		Processed:  productImport.Processed,
+		Created:    productImport.Created,
+		Updated:    productImport.Updated,
+		Failed:     productImport.Failed,
+		Errors:     productImport.Errors,
+		CreatedAt:  productImport.CreatedAt,
+		StartedAt:  productImport.StartedAt,
+		FinishedAt: productImport.FinishedAt,
+	}
+}
+
+func NewProductImportController(importService *service.ProductImportService) *ProductImportController {
+	return &ProductImportController{importService: importService}
+}
+
+// ImportProducts godoc
+// @Summary     Import products
+// @Description Uploads a catalogue as CSV (with a header row) or NDJSON, one product per row, upserted by SKU.
+// @Description Columns are sku, name, description, price (cents), stock, low_stock_threshold, category_id and tags (separated by |);
+// @Description sku and name are required, and price for new products. A row replaces the catalogue fields of the product with its SKU;
+// @Description a variant row without a price makes the variant sell at its product's price. Stock only applies to new products,
+// @Description where it is recorded as an import stock movement; existing stock changes through stock adjustments.
+// @Description Rows are validated on upload and applied in the background: poll the returned import for progress and per-row errors.
+// @Tags        products
+// @Accept      text/csv
+// @Accept      application/x-ndjson
+// @Produce     json
+// @Param       format query    string false "Upload format, overriding the Content-Type" Enums(csv, ndjson)
+// @Success     202    {object} ProductImportResponse
+// @Header      202    {string} Location "URL of the import status"
+// @Failure     400    {object} handlers.ErrorResponse
+// @Failure     500    {object} handlers.ErrorResponse
+// @Router      /api/v1/products/import [post]
+func (ic *ProductImportController) ImportProducts(c *gin.Context) {
+	var request dto.ProductImportRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError(err.Error()))
+		return
+	}
+	format := domain.ProductImportFormat(request.Format)
+	if format == "" {
+		format = productImportFormatOf(c.ContentType())
+	}
+	if !format.IsValid() {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError("send text/csv or application/x-ndjson, or set the format parameter"))
+		return
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, productImportMaxBytes)
+	productImport, err := ic.importService.StartImport(c.Request.Context(), format, body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			err = serviceerrors.NewInvalidRequestError(fmt.Sprintf("import exceeds %d bytes", productImportMaxBytes))
+		}
+		handlers.HandleError(c, err)
+		return
+	}
+	c.Header("Location", "/api/v1/products/imports/"+string(productImport.ID))
+	c.JSON(http.StatusAccepted, NewProductImportResponse(productImport))
+}
+
+func productImportFormatOf(contentType string) domain.ProductImportFormat {
+	switch contentType {
+	case "text/csv":
+		return domain.ProductImportFormatCSV
+	case "application/x-ndjson", "application/ndjson":
+		return domain.ProductImportFormatNDJSON
+	}
+	return ""
+}
+
+// GetImport godoc
+// @Summary     Get a product import
+// @Description Returns the progress of an import: rows created, updated and failed, with the line and reason of each failure
+// @Description (up to 1000). Status moves from pending to running to completed.
+// @Tags        products
+// @Produce     json
+// @Param       id  path     string true "Import ID"
+// @Success     200 {object} ProductImportResponse
+// @Failure     400 {object} handlers.ErrorResponse
+// @Failure     404 {object} handlers.ErrorResponse
+// @Failure     500 {object} handlers.ErrorResponse
+// @Router      /api/v1/products/imports/{id} [get]
+func (ic *ProductImportController) GetImport(c *gin.Context) {
+	importID := c.Param("id")
+	if !domain.ValidateID(importID) {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError("Invalid import ID"))
+		return
+	}
+	productImport, err := ic.importService.GetImport(c.Request.Context(), domain.ID(importID))
+	if err != nil {
+		handlers.HandleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, NewProductImportResponse(productImport))
+}
+
+// ExportProducts godoc
+// @Summary     Export products
+// @Description Streams every product that is not archived, oldest first, in the import format so it can be edited and imported back.
+// @Description Products sold by variant are exported as a row per variant, with the variant's SKU, name, price and stock; importing such a row updates that variant.
+// @Description A variant sold at its product's price is exported without a price, so importing it back keeps it that way.
+// @Description Products with neither a SKU nor variants cannot be matched on import and are left out.
+// @Tags        products
+// @Produce     text/csv
+// @Produce     application/x-ndjson
+// @Param       format query string false "Export format (default csv)" Enums(csv, ndjson)
+// @Success     200
+// @Failure     400    {object} handlers.ErrorResponse
+// @Router      /api/v1/products/export [get]
+func (ic *ProductImportController) ExportProducts(c *gin.Context) {
+	var request dto.ProductExportRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		handlers.HandleError(c, serviceerrors.NewInvalidRequestError(err.Error()))
+		return
+	}
+	format := domain.ProductImportFormat(request.Format)
+	if format == "" {
+		format = domain.ProductImportFormatCSV
+	}
+
+	c.Header("Content-Type", productImportContentTypes[format])
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="products.%s"`, format))
+	c.Status(http.StatusOK)
+	// The status is sent with the first rows, so a failure past that point can only cut the
+	// stream short.
+	if err := ic.importService.ExportProducts(c.Request.Context(), format, c.Writer); err != nil {
+		logger.Error(c.Request.Context(), "product export failed", err, map[string]any{"format": format})
+		c.Abort()
+	}
+}
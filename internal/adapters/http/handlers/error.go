@@ -9,13 +9,14 @@ import (
 )
 
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error   string `json:"error"`
+	Details any    `json:"details,omitempty"`
 }
 
 func HandleError(c *gin.Context, err error) {
 	var svcErr *serviceerrors.ServiceError
 	if errors.As(err, &svcErr) {
-		c.JSON(mapKindToHTTP(svcErr.Kind), ErrorResponse{Error: svcErr.Message})
+		c.JSON(mapKindToHTTP(svcErr.Kind), ErrorResponse{Error: svcErr.Message, Details: svcErr.Details})
 		return
 	}
 
@@ -26,12 +27,14 @@ func mapKindToHTTP(kind serviceerrors.ErrorKind) int {
 	switch kind {
 	case serviceerrors.KindNotFound:
 		return http.StatusNotFound
-	case serviceerrors.KindConflict:
+	case serviceerrors.KindConflict, serviceerrors.KindInvalidTransition:
 		return http.StatusConflict
 	case serviceerrors.KindUnprocessableEntity:
 		return http.StatusUnprocessableEntity
 	case serviceerrors.KindInvalidRequest:
 		return http.StatusBadRequest
+	case serviceerrors.KindUnavailable:
+		return http.StatusServiceUnavailable
 	default:
 		return http.StatusInternalServerError
 	}
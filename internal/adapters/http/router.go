@@ -16,6 +16,8 @@ type Router struct {
 	healthController   *controllers.HealthController
 	orderController    *controllers.OrderController
 	productController  *controllers.ProductController
+	importController   *controllers.ProductImportController
+	categoryController *controllers.CategoryController
 	customerController *controllers.CustomerController
 	rateLimiter        middleware.RateLimiter
 }
@@ -24,6 +26,8 @@ func NewRouter(
 	healthController *controllers.HealthController,
 	orderController *controllers.OrderController,
 	productController *controllers.ProductController,
+	importController *controllers.ProductImportController,
+	categoryController *controllers.CategoryController,
 	customerController *controllers.CustomerController,
 	rateLimiter middleware.RateLimiter,
 ) *Router {
@@ -31,6 +35,8 @@ func NewRouter(
 		healthController:   healthController,
 		orderController:    orderController,
 		productController:  productController,
+		importController:   importController,
+		categoryController: categoryController,
 		customerController: customerController,
 		rateLimiter:        rateLimiter,
 	}
@@ -46,13 +52,40 @@ func (r *Router) SetupRoutes(router *gin.Engine) {
 		v1Group.GET("/health", r.healthController.Health)
 
 		v1Group.POST("/orders", middleware.RateLimit(rl, 15, 1*time.Minute), r.orderController.CreateOrder)
+		v1Group.POST("/orders/quote", middleware.RateLimit(rl, 60, 1*time.Minute), r.orderController.QuoteOrder)
+		v1Group.GET("/orders", r.orderController.ListOrders)
 		v1Group.GET("/orders/:id", r.orderController.GetOrderByID)
+		v1Group.GET("/orders/:id/history", r.orderController.GetOrderStatusHistory)
 		v1Group.PATCH("/orders/:id/status", middleware.RateLimit(rl, 20, 1*time.Minute), r.orderController.UpdateOrderStatus)
+		v1Group.POST("/orders/:id/cancel", middleware.RateLimit(rl, 20, 1*time.Minute), r.orderController.CancelOrder)
 
 		v1Group.POST("/products", r.productController.CreateProduct)
-		v1Group.GET("/products", r.productController.GetAll)
+		v1Group.GET("/products", r.productController.ListProducts)
+		v1Group.GET("/products/search", r.productController.SearchProducts)
+		v1Group.POST("/products/import", middleware.RateLimit(rl, 5, 1*time.Minute), r.importController.ImportProducts)
+		v1Group.GET("/products/imports/:id", r.importController.GetImport)
+		v1Group.GET("/products/export", middleware.RateLimit(rl, 5, 1*time.Minute), r.importController.ExportProducts)
+		v1Group.GET("/products/:id", r.productController.GetByID)
+		v1Group.PATCH("/products/:id", r.productController.UpdateProduct)
+		v1Group.DELETE("/products/:id", r.productController.ArchiveProduct)
+		v1Group.POST("/products/:id/stock-adjustments", middleware.RateLimit(rl, 30, 1*time.Minute), r.productController.AdjustStock)
+		v1Group.GET("/products/:id/stock-movements", r.productController.GetStockMovements)
+
+		v1Group.POST("/categories", r.categoryController.CreateCategory)
+		v1Group.GET("/categories", r.categoryController.ListCategories)
+		v1Group.GET("/categories/:id", r.categoryController.GetByID)
+		v1Group.PATCH("/categories/:id", r.categoryController.UpdateCategory)
+		v1Group.DELETE("/categories/:id", r.categoryController.DeleteCategory)
 
 		v1Group.POST("/customers", r.customerController.CreateCustomer)
+		v1Group.GET("/customers/:id", r.customerController.GetByID)
+		v1Group.PATCH("/customers/:id", r.customerController.UpdateCustomer)
+		v1Group.DELETE("/customers/:id", r.customerController.EraseCustomer)
+		v1Group.GET("/customers/:id/export", middleware.RateLimit(rl, 5, 1*time.Minute), r.customerController.ExportCustomer)
+		v1Group.GET("/customers/:id/orders", r.orderController.ListCustomerOrders)
+		v1Group.POST("/customers/:id/addresses", r.customerController.AddAddress)
+		v1Group.PUT("/customers/:id/addresses/:addressId", r.customerController.ReplaceAddress)
+		v1Group.DELETE("/customers/:id/addresses/:addressId", r.customerController.RemoveAddress)
 	}
 }
 
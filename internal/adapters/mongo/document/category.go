@@ -0,0 +1,48 @@
+package document
+
+import (
+	"time"
+
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CategoryDocument struct {
+	ID primitive.ObjectID `bson:"_id,omitempty"`
+	// ParentID is stored as null for top-level categories so the unique index on parent and
+	// name also covers them.
+	ParentID  *primitive.ObjectID `bson:"parent_id"`
+	Name      string              `bson:"name"`
+	CreatedAt time.Time           `bson:"created_at"`
+	UpdatedAt time.Time           `bson:"updated_at"`
+}
+
+func (doc CategoryDocument) GetID() primitive.ObjectID {
+	return doc.ID
+}
+
+func (doc *CategoryDocument) ToDomain() *domain.Category {
+	category := &domain.Category{
+		ID:        domain.ID(doc.ID.Hex()),
+		Name:      doc.Name,
+		CreatedAt: doc.CreatedAt,
+		UpdatedAt: doc.UpdatedAt,
+	}
+	if doc.ParentID != nil {
+		category.ParentID = domain.ID(doc.ParentID.Hex())
+	}
+	return category
+}
+
+func ToCategoryDocument(c *domain.Category) (*CategoryDocument, error) {
+	parentID, err := ToOptionalObjectID(c.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	return &CategoryDocument{
+		ParentID:  parentID,
+		Name:      c.Name,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}, nil
+}
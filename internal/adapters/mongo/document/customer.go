@@ -1,11 +1,117 @@
 package document
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AddressDocument struct {
+	Recipient  string `bson:"recipient"`
+	Line1      string `bson:"line1"`
+	Line2      string `bson:"line2,omitempty"`
+	City       string `bson:"city"`
+	State      string `bson:"state,omitempty"`
+	PostalCode string `bson:"postal_code"`
+	Country    string `bson:"country"`
+}
+
+type CustomerAddressDocument struct {
+	ID              primitive.ObjectID `bson:"_id"`
+	Label           string             `bson:"label,omitempty"`
+	AddressDocument `bson:",inline"`
+}
 
 type CustomerDocument struct {
-	ID primitive.ObjectID `bson:"_id,omitempty"`
+	ID               primitive.ObjectID        `bson:"_id,omitempty"`
+	Name             string                    `bson:"name,omitempty"`
+	Email            string                    `bson:"email,omitempty"`
+	Phone            string                    `bson:"phone,omitempty"`
+	Addresses        []CustomerAddressDocument `bson:"addresses,omitempty"`
+	DefaultAddressID *primitive.ObjectID       `bson:"default_address_id,omitempty"`
+	ErasedAt         *time.Time                `bson:"erased_at,omitempty"`
+	CreatedAt        time.Time                 `bson:"created_at"`
+	UpdatedAt        time.Time                 `bson:"updated_at"`
 }
 
 func (doc CustomerDocument) GetID() primitive.ObjectID {
 	return doc.ID
 }
+
+func (doc AddressDocument) ToDomain() domain.Address {
+	return domain.Address{
+		Recipient:  doc.Recipient,
+		Line1:      doc.Line1,
+		Line2:      doc.Line2,
+		City:       doc.City,
+		State:      doc.State,
+		PostalCode: doc.PostalCode,
+		Country:    doc.Country,
+	}
+}
+
+func ToAddressDocument(a domain.Address) AddressDocument {
+	return AddressDocument{
+		Recipient:  a.Recipient,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		State:      a.State,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+	}
+}
+
+func (doc CustomerAddressDocument) ToDomain() domain.CustomerAddress {
+	return domain.CustomerAddress{
+		ID:      domain.ID(doc.ID.Hex()),
+		Label:   doc.Label,
+		Address: doc.AddressDocument.ToDomain(),
+	}
+}
+
+// ToCustomerAddressDocument converts a saved address, giving it a new ID when it has none yet.
+func ToCustomerAddressDocument(a domain.CustomerAddress) (CustomerAddressDocument, error) {
+	id := primitive.NewObjectID()
+	if a.ID != "" {
+		var err error
+		if id, err = primitive.ObjectIDFromHex(string(a.ID)); err != nil {
+			return CustomerAddressDocument{}, err
+		}
+	}
+	return CustomerAddressDocument{
+		ID:              id,
+		Label:           a.Label,
+		AddressDocument: ToAddressDocument(a.Address),
+	}, nil
+}
+
+func (doc *CustomerDocument) ToDomain() *domain.Customer {
+	customer := &domain.Customer{
+		ID:        domain.ID(doc.ID.Hex()),
+		Name:      doc.Name,
+		Email:     doc.Email,
+		Phone:     doc.Phone,
+		ErasedAt:  doc.ErasedAt,
+		CreatedAt: doc.CreatedAt,
+		UpdatedAt: doc.UpdatedAt,
+	}
+	for _, addressDoc := range doc.Addresses {
+		customer.Addresses = append(customer.Addresses, addressDoc.ToDomain())
+	}
+	if doc.DefaultAddressID != nil {
+		customer.DefaultAddressID = domain.ID(doc.DefaultAddressID.Hex())
+	}
+	return customer
+}
+
+func ToCustomerDocument(c *domain.Customer) *CustomerDocument {
+	return &CustomerDocument{
+		Name:      c.Name,
+		Email:     c.Email,
+		Phone:     c.Phone,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
@@ -1,7 +1,22 @@
 package document
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 type Document interface {
 	GetID() primitive.ObjectID
 }
+
+// ToOptionalObjectID converts a reference that may be unset, returning nil for an empty ID.
+func ToOptionalObjectID(id domain.ID) (*primitive.ObjectID, error) {
+	if id == "" {
+		return nil, nil
+	}
+	objectID, err := primitive.ObjectIDFromHex(string(id))
+	if err != nil {
+		return nil, err
+	}
+	return &objectID, nil
+}
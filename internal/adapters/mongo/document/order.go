@@ -10,19 +10,52 @@ import (
 type OrderItemDocument struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty"`
 	ProductID   primitive.ObjectID `bson:"product_id"`
+	SKU         string             `bson:"sku,omitempty"`
 	ProductName string             `bson:"product_name"`
 	Quantity    int                `bson:"quantity"`
 	UnitPrice   int64              `bson:"unit_price"`
 }
 
+type OrderStatusChangeDocument struct {
+	From      string    `bson:"from"`
+	To        string    `bson:"to"`
+	ChangedAt time.Time `bson:"changed_at"`
+	Actor     string    `bson:"actor,omitempty"`
+	Reason    string    `bson:"reason,omitempty"`
+}
+
 type OrderDocument struct {
-	ID          primitive.ObjectID  `bson:"_id,omitempty"`
-	CustomerID  primitive.ObjectID  `bson:"customer_id"`
-	Items       []OrderItemDocument `bson:"items"`
-	Status      string              `bson:"status"`
-	TotalAmount int64               `bson:"total_amount"`
-	CreatedAt   time.Time           `bson:"created_at"`
-	UpdatedAt   time.Time           `bson:"updated_at"`
+	ID              primitive.ObjectID          `bson:"_id,omitempty"`
+	CustomerID      primitive.ObjectID          `bson:"customer_id"`
+	Items           []OrderItemDocument         `bson:"items"`
+	Status          string                      `bson:"status"`
+	StatusHistory   []OrderStatusChangeDocument `bson:"status_history,omitempty"`
+	TotalAmount     int64                       `bson:"total_amount"`
+	ShippingAddress *AddressDocument            `bson:"shipping_address,omitempty"`
+	IdempotencyKey  string                      `bson:"idempotency_key,omitempty"`
+	CreatedAt       time.Time                   `bson:"created_at"`
+	UpdatedAt       time.Time                   `bson:"updated_at"`
+	Version         int64                       `bson:"version"`
+}
+
+func (doc OrderStatusChangeDocument) ToDomain() domain.OrderStatusChange {
+	return domain.OrderStatusChange{
+		From:      domain.OrderStatus(doc.From),
+		To:        domain.OrderStatus(doc.To),
+		ChangedAt: doc.ChangedAt,
+		Actor:     doc.Actor,
+		Reason:    doc.Reason,
+	}
+}
+
+func ToStatusChangeDocument(change domain.OrderStatusChange) OrderStatusChangeDocument {
+	return OrderStatusChangeDocument{
+		From:      string(change.From),
+		To:        string(change.To),
+		ChangedAt: change.ChangedAt,
+		Actor:     change.Actor,
+		Reason:    change.Reason,
+	}
 }
 
 func (doc OrderDocument) GetID() primitive.ObjectID {
@@ -35,27 +68,42 @@ func (doc *OrderDocument) ToDomain() *domain.Order {
 		items[i] = domain.OrderItem{
 			ID:          domain.ID(itemDoc.ID.Hex()),
 			ProductID:   domain.ID(itemDoc.ProductID.Hex()),
+			SKU:         itemDoc.SKU,
 			ProductName: itemDoc.ProductName,
 			Quantity:    itemDoc.Quantity,
 			UnitPrice:   domain.Amount(itemDoc.UnitPrice),
 		}
 	}
 
-	return &domain.Order{
-		ID:          domain.ID(doc.ID.Hex()),
-		CustomerID:  domain.ID(doc.CustomerID.Hex()),
-		Items:       items,
-		Status:      domain.OrderStatus(doc.Status),
-		TotalAmount: domain.Amount(doc.TotalAmount),
-		CreatedAt:   doc.CreatedAt,
-		UpdatedAt:   doc.UpdatedAt,
+	history := make([]domain.OrderStatusChange, len(doc.StatusHistory))
+	for i, changeDoc := range doc.StatusHistory {
+		history[i] = changeDoc.ToDomain()
 	}
+
+	order := &domain.Order{
+		ID:             domain.ID(doc.ID.Hex()),
+		CustomerID:     domain.ID(doc.CustomerID.Hex()),
+		Items:          items,
+		Status:         domain.OrderStatus(doc.Status),
+		StatusHistory:  history,
+		TotalAmount:    domain.Amount(doc.TotalAmount),
+		IdempotencyKey: doc.IdempotencyKey,
+		CreatedAt:      doc.CreatedAt,
+		UpdatedAt:      doc.UpdatedAt,
+		Version:        doc.Version,
+	}
+	if doc.ShippingAddress != nil {
+		address := doc.ShippingAddress.ToDomain()
+		order.ShippingAddress = &address
+	}
+	return order
 }
 
 func ToDocument(order *domain.Order) *OrderDocument {
 	items := make([]OrderItemDocument, len(order.Items))
 	for i, item := range order.Items {
 		itemDoc := OrderItemDocument{
+			SKU:         item.SKU,
 			ProductName: item.ProductName,
 			Quantity:    item.Quantity,
 			UnitPrice:   int64(item.UnitPrice),
@@ -76,12 +124,25 @@ func ToDocument(order *domain.Order) *OrderDocument {
 		items[i] = itemDoc
 	}
 
+	history := make([]OrderStatusChangeDocument, len(order.StatusHistory))
+	for i, change := range order.StatusHistory {
+		history[i] = ToStatusChangeDocument(change)
+	}
+
 	doc := &OrderDocument{
-		Items:       items,
-		Status:      string(order.Status),
-		TotalAmount: int64(order.TotalAmount),
-		CreatedAt:   order.CreatedAt,
-		UpdatedAt:   order.UpdatedAt,
+		Items:          items,
+		StatusHistory:  history,
+		Status:         string(order.Status),
+		TotalAmount:    int64(order.TotalAmount),
+		IdempotencyKey: order.IdempotencyKey,
+		CreatedAt:      order.CreatedAt,
+		UpdatedAt:      order.UpdatedAt,
+		Version:        order.Version,
+	}
+
+	if order.ShippingAddress != nil {
+		address := ToAddressDocument(*order.ShippingAddress)
+		doc.ShippingAddress = &address
 	}
 
 	if order.ID != "" {
@@ -8,13 +8,56 @@ import (
 )
 
 type ProductDocument struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty"`
-	Name        string             `bson:"name"`
-	Description string             `bson:"description"`
-	Price       int64              `bson:"price"`
-	Stock       int                `bson:"stock"`
-	CreatedAt   time.Time          `bson:"created_at"`
-	UpdatedAt   time.Time          `bson:"updated_at"`
+	ID                primitive.ObjectID       `bson:"_id,omitempty"`
+	SKU               string                   `bson:"sku,omitempty"`
+	Name              string                   `bson:"name"`
+	Description       string                   `bson:"description"`
+	Price             int64                    `bson:"price"`
+	Stock             int                      `bson:"stock"`
+	Reserved          int                      `bson:"reserved"`
+	LowStockThreshold *int                     `bson:"low_stock_threshold,omitempty"`
+	Variants          []ProductVariantDocument `bson:"variants,omitempty"`
+	CategoryID        *primitive.ObjectID      `bson:"category_id,omitempty"`
+	Tags              []string                 `bson:"tags,omitempty"`
+	ArchivedAt        *time.Time               `bson:"archived_at,omitempty"`
+	CreatedAt         time.Time                `bson:"created_at"`
+	UpdatedAt         time.Time                `bson:"updated_at"`
+}
+
+type ProductVariantDocument struct {
+	SKU      string `bson:"sku"`
+	Name     string `bson:"name"`
+	Price    *int64 `bson:"price,omitempty"`
+	Stock    int    `bson:"stock"`
+	Reserved int    `bson:"reserved"`
+}
+
+func (doc ProductVariantDocument) ToDomain() domain.ProductVariant {
+	variant := domain.ProductVariant{
+		SKU:      doc.SKU,
+		Name:     doc.Name,
+		Stock:    doc.Stock,
+		Reserved: doc.Reserved,
+	}
+	if doc.Price != nil {
+		price := domain.Amount(*doc.Price)
+		variant.Price = &price
+	}
+	return variant
+}
+
+func ToProductVariantDocument(v domain.ProductVariant) ProductVariantDocument {
+	doc := ProductVariantDocument{
+		SKU:      v.SKU,
+		Name:     v.Name,
+		Stock:    v.Stock,
+		Reserved: v.Reserved,
+	}
+	if v.Price != nil {
+		price := int64(*v.Price)
+		doc.Price = &price
+	}
+	return doc
 }
 
 func (doc ProductDocument) GetID() primitive.ObjectID {
@@ -22,24 +65,56 @@ func (doc ProductDocument) GetID() primitive.ObjectID {
 }
 
 func (doc *ProductDocument) ToDomain() *domain.Product {
-	return &domain.Product{
-		ID:          domain.ID(doc.ID.Hex()),
-		Name:        doc.Name,
-		Description: doc.Description,
-		Price:       domain.Amount(doc.Price),
-		Stock:       doc.Stock,
-		CreatedAt:   doc.CreatedAt,
-		UpdatedAt:   doc.UpdatedAt,
+	var variants []domain.ProductVariant
+	for _, variantDoc := range doc.Variants {
+		variants = append(variants, variantDoc.ToDomain())
+	}
+
+	product := &domain.Product{
+		ID:                domain.ID(doc.ID.Hex()),
+		SKU:               doc.SKU,
+		Name:              doc.Name,
+		Description:       doc.Description,
+		Price:             domain.Amount(doc.Price),
+		Stock:             doc.Stock,
+		Reserved:          doc.Reserved,
+		LowStockThreshold: doc.LowStockThreshold,
+		Variants:          variants,
+		Tags:              doc.Tags,
+		ArchivedAt:        doc.ArchivedAt,
+		CreatedAt:         doc.CreatedAt,
+		UpdatedAt:         doc.UpdatedAt,
 	}
+	if doc.CategoryID != nil {
+		product.CategoryID = domain.ID(doc.CategoryID.Hex())
+	}
+	return product
 }
 
-func ToProductDocument(p *domain.Product) *ProductDocument {
-	return &ProductDocument{
-		Name:        p.Name,
-		Description: p.Description,
-		Price:       int64(p.Price),
-		Stock:       p.Stock,
-		CreatedAt:   p.CreatedAt,
-		UpdatedAt:   p.UpdatedAt,
+func ToProductDocument(p *domain.Product) (*ProductDocument, error) {
+	categoryID, err := ToOptionalObjectID(p.CategoryID)
+	if err != nil {
+		return nil, err
 	}
+
+	var variants []ProductVariantDocument
+	for _, variant := range p.Variants {
+		variants = append(variants, ToProductVariantDocument(variant))
+	}
+
+	return &ProductDocument{
+		SKU:               p.SKU,
+		Name:              p.Name,
+		Description:       p.Description,
+		Price:             int64(p.Price),
+		Stock:             p.Stock,
+		Reserved:          p.Reserved,
+		LowStockThreshold: p.LowStockThreshold,
+		Variants:          variants,
+		CategoryID:        categoryID,
+		Tags:              p.Tags,
+		ArchivedAt:        p.ArchivedAt,
+		CreatedAt:         p.CreatedAt,
+		UpdatedAt:         p.UpdatedAt,
+	}, nil
 }
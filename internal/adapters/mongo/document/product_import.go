@@ -0,0 +1,145 @@
+package document
+
+import (
+	"time"
+
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ProductImportDocument struct {
+	ID         primitive.ObjectID           `bson:"_id,omitempty"`
+	Format     string                       `bson:"format"`
+	Status     string                       `bson:"status"`
+	LeaseID    string                       `bson:"lease_id,omitempty"`
+	Rows       []ProductImportRowDocument   `bson:"rows,omitempty"`
+	Total      int                          `bson:"total"`
+	Processed  int                          `bson:"processed"`
+	Created    int                          `bson:"created"`
+	Updated    int                          `bson:"updated"`
+	Failed     int                          `bson:"failed"`
+	Errors     []ProductImportErrorDocument `bson:"errors,omitempty"`
+	CreatedAt  time.Time                    `bson:"created_at"`
+	StartedAt  *time.Time                   `bson:"started_at,omitempty"`
+	FinishedAt *time.Time                   `bson:"finished_at,omitempty"`
+	UpdatedAt  time.Time                    `bson:"updated_at"`
+}
+
+type ProductImportRowDocument struct {
+	Line              int      `bson:"line"`
+	SKU               string   `bson:"sku"`
+	Name              string   `bson:"name"`
+	Description       string   `bson:"description,omitempty"`
+	Price             *int64   `bson:"price,omitempty"`
+	Stock             int      `bson:"stock"`
+	LowStockThreshold *int     `bson:"low_stock_threshold,omitempty"`
+	CategoryID        string   `bson:"category_id,omitempty"`
+	Tags              []string `bson:"tags,omitempty"`
+}
+
+type ProductImportErrorDocument struct {
+	Line    int    `bson:"line"`
+	SKU     string `bson:"sku,omitempty"`
+	Message string `bson:"message"`
+}
+
+func (doc ProductImportDocument) GetID() primitive.ObjectID {
+	return doc.ID
+}
+
+func (doc *ProductImportDocument) ToDomain() *domain.ProductImport {
+	var rows []domain.ProductImportRow
+	for _, row := range doc.Rows {
+		domainRow := domain.ProductImportRow{
+			Line:              row.Line,
+			SKU:               row.SKU,
+			Name:              row.Name,
+			Description:       row.Description,
+			Stock:             row.Stock,
+			LowStockThreshold: row.LowStockThreshold,
+			CategoryID:        domain.ID(row.CategoryID),
+			Tags:              row.Tags,
+		}
+		if row.Price != nil {
+			price := domain.Amount(*row.Price)
+			domainRow.Price = &price
+		}
+		rows = append(rows, domainRow)
+	}
+
+	var rowErrors []domain.ProductImportError
+	for _, rowError := range doc.Errors {
+		rowErrors = append(rowErrors, domain.ProductImportError{
+			Line:    rowError.Line,
+			SKU:     rowError.SKU,
+			Message: rowError.Message,
+		})
+	}
+
+	return &domain.ProductImport{
+		ID:         domain.ID(doc.ID.Hex()),
+		Format:     domain.ProductImportFormat(doc.Format),
+		Status:     domain.ProductImportStatus(doc.Status),
+		LeaseID:    doc.LeaseID,
+		Rows:       rows,
+		Total:      doc.Total,
+		Processed:  doc.Processed,
+		Created:    doc.Created,
+		Updated:    doc.Updated,
+		Failed:     doc.Failed,
+		Errors:     rowErrors,
+		CreatedAt:  doc.CreatedAt,
+		StartedAt:  doc.StartedAt,
+		FinishedAt: doc.FinishedAt,
+		UpdatedAt:  doc.UpdatedAt,
+	}
+}
+
+func ToProductImportErrorDocuments(rowErrors []domain.ProductImportError) []ProductImportErrorDocument {
+	var docs []ProductImportErrorDocument
+	for _, rowError := range rowErrors {
+		docs = append(docs, ProductImportErrorDocument{
+			Line:    rowError.Line,
+			SKU:     rowError.SKU,
+			Message: rowError.Message,
+		})
+	}
+	return docs
+}
+
+func ToProductImportDocument(i *domain.ProductImport) *ProductImportDocument {
+	var rows []ProductImportRowDocument
+	for _, row := range i.Rows {
+		rowDoc := ProductImportRowDocument{
+			Line:              row.Line,
+			SKU:               row.SKU,
+			Name:              row.Name,
+			Description:       row.Description,
+			Stock:             row.Stock,
+			LowStockThreshold: row.LowStockThreshold,
+			CategoryID:        string(row.CategoryID),
+			Tags:              row.Tags,
+		}
+		if row.Price != nil {
+			price := int64(*row.Price)
+			rowDoc.Price = &price
+		}
+		rows = append(rows, rowDoc)
+	}
+
+	return &ProductImportDocument{
+		Format:     string(i.Format),
+		Status:     string(i.Status),
+		Rows:       rows,
+		Total:      i.Total,
+		Processed:  i.Processed,
+		Created:    i.Created,
+		Updated:    i.Updated,
+		Failed:     i.Failed,
+		Errors:     ToProductImportErrorDocuments(i.Errors),
+		CreatedAt:  i.CreatedAt,
+		StartedAt:  i.StartedAt,
+		FinishedAt: i.FinishedAt,
+		UpdatedAt:  i.UpdatedAt,
+	}
+}
@@ -0,0 +1,59 @@
+package document
+
+import (
+	"time"
+
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReservationDocument struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	OrderID   primitive.ObjectID `bson:"order_id"`
+	ProductID primitive.ObjectID `bson:"product_id"`
+	SKU       string             `bson:"sku,omitempty"`
+	Quantity  int                `bson:"quantity"`
+	Status    string             `bson:"status"`
+	ExpiresAt time.Time          `bson:"expires_at"`
+	CreatedAt time.Time          `bson:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at"`
+}
+
+func (doc ReservationDocument) GetID() primitive.ObjectID {
+	return doc.ID
+}
+
+func (doc *ReservationDocument) ToDomain() *domain.Reservation {
+	return &domain.Reservation{
+		ID:        domain.ID(doc.ID.Hex()),
+		OrderID:   domain.ID(doc.OrderID.Hex()),
+		ProductID: domain.ID(doc.ProductID.Hex()),
+		SKU:       doc.SKU,
+		Quantity:  doc.Quantity,
+		Status:    domain.ReservationStatus(doc.Status),
+		ExpiresAt: doc.ExpiresAt,
+		CreatedAt: doc.CreatedAt,
+		UpdatedAt: doc.UpdatedAt,
+	}
+}
+
+func ToReservationDocument(r *domain.Reservation) (*ReservationDocument, error) {
+	orderID, err := primitive.ObjectIDFromHex(string(r.OrderID))
+	if err != nil {
+		return nil, err
+	}
+	productID, err := primitive.ObjectIDFromHex(string(r.ProductID))
+	if err != nil {
+		return nil, err
+	}
+	return &ReservationDocument{
+		OrderID:   orderID,
+		ProductID: productID,
+		SKU:       r.SKU,
+		Quantity:  r.Quantity,
+		Status:    string(r.Status),
+		ExpiresAt: r.ExpiresAt,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}, nil
+}
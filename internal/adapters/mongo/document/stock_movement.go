@@ -0,0 +1,65 @@
+package document
+
+import (
+	"time"
+
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type StockMovementDocument struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty"`
+	ProductID primitive.ObjectID  `bson:"product_id"`
+	SKU       string              `bson:"sku,omitempty"`
+	OrderID   *primitive.ObjectID `bson:"order_id,omitempty"`
+	Quantity  int                 `bson:"quantity"`
+	Reason    string              `bson:"reason"`
+	Actor     string              `bson:"actor,omitempty"`
+	Note      string              `bson:"note,omitempty"`
+	CreatedAt time.Time           `bson:"created_at"`
+}
+
+func (doc StockMovementDocument) GetID() primitive.ObjectID {
+	return doc.ID
+}
+
+func (doc *StockMovementDocument) ToDomain() *domain.StockMovement {
+	movement := &domain.StockMovement{
+		ID:        domain.ID(doc.ID.Hex()),
+		ProductID: domain.ID(doc.ProductID.Hex()),
+		SKU:       doc.SKU,
+		Quantity:  doc.Quantity,
+		Reason:    domain.StockMovementReason(doc.Reason),
+		Actor:     doc.Actor,
+		Note:      doc.Note,
+		CreatedAt: doc.CreatedAt,
+	}
+	if doc.OrderID != nil {
+		movement.OrderID = domain.ID(doc.OrderID.Hex())
+	}
+	return movement
+}
+
+func ToStockMovementDocument(m *domain.StockMovement) (*StockMovementDocument, error) {
+	productID, err := primitive.ObjectIDFromHex(string(m.ProductID))
+	if err != nil {
+		return nil, err
+	}
+	doc := &StockMovementDocument{
+		ProductID: productID,
+		SKU:       m.SKU,
+		Quantity:  m.Quantity,
+		Reason:    string(m.Reason),
+		Actor:     m.Actor,
+		Note:      m.Note,
+		CreatedAt: m.CreatedAt,
+	}
+	if m.OrderID != "" {
+		orderID, err := primitive.ObjectIDFromHex(string(m.OrderID))
+		if err != nil {
+			return nil, err
+		}
+		doc.OrderID = &orderID
+	}
+	return doc, nil
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/rafaelleal24/challenge/internal/adapters/mongo/document"
+	"github.com/rafaelleal24/challenge/internal/core/port"
 	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -54,6 +55,60 @@ func (r *BaseRepository[T]) Find(ctx context.Context, filter bson.M, opts ...*op
 	return entities, nil
 }
 
+// FindPage returns up to page.Limit entities matching filter ordered by sortField and _id.
+// Pages are keyed on the last entity returned instead of an offset, so they stay stable
+// while documents are inserted and cost the same however deep the client pages.
+func (r *BaseRepository[T]) FindPage(ctx context.Context, filter bson.M, sortField string, page port.PageRequest) (*port.Page[T], error) {
+	if page.Limit <= 0 {
+		return nil, serviceerrors.NewInvalidRequestError("page limit must be positive")
+	}
+
+	direction := 1
+	if page.Descending {
+		direction = -1
+	}
+
+	query := filter
+	if page.Cursor != "" {
+		cursor, err := decodePageCursor(page.Cursor, sortField)
+		if err != nil {
+			return nil, err
+		}
+		query = bson.M{"$and": bson.A{filter, cursor.after(direction)}}
+	}
+
+	opts := options.Find().
+		SetLimit(page.Limit + 1).
+		SetSort(bson.D{{Key: sortField, Value: direction}, {Key: "_id", Value: direction}})
+
+	entities, err := r.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &port.Page[T]{Items: entities}
+	if int64(len(entities)) > page.Limit {
+		result.Items = entities[:page.Limit]
+		cursor, err := newPageCursor(result.Items[page.Limit-1], sortField)
+		if err != nil {
+			return nil, err
+		}
+		if result.NextCursor, err = cursor.encode(); err != nil {
+			return nil, err
+		}
+	}
+
+	if page.IncludeTotal {
+		total, err := r.collection.CountDocuments(ctx, filter)
+		if err != nil {
+			return nil, parseError(err)
+		}
+		result.Total = &total
+	}
+
+	return result, nil
+}
+
 func (r *BaseRepository[T]) FindOne(ctx context.Context, filter bson.M) (*T, error) {
 
 	var entity T
@@ -115,6 +170,26 @@ func (r *BaseRepository[T]) DeleteByID(ctx context.Context, id string) error {
 	return nil
 }
 
+// withTransaction runs fn inside a transaction, reusing the session already bound to ctx
+// so repository writes can take part in a transaction opened by the TransactionManager.
+func withTransaction(ctx context.Context, client *mongo.Client, fn func(ctx context.Context) error) error {
+	if mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	session, err := client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessCtx)
+	})
+
+	return err
+}
+
 func parseError(err error) error {
 	if errors.Is(err, mongo.ErrNoDocuments) {
 		return serviceerrors.NewNotFoundError("entity not found")
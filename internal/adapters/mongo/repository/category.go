@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/rafaelleal24/challenge/internal/adapters/mongo/document"
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/logger"
+	"github.com/rafaelleal24/challenge/internal/core/port"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CategoryRepository struct {
+	*BaseRepository[document.CategoryDocument]
+	collection *mongo.Collection
+}
+
+func NewCategoryRepository(db *mongo.Database) port.CategoryPort {
+	repo := &CategoryRepository{
+		BaseRepository: NewBaseRepository[document.CategoryDocument](db, "categories"),
+		collection:     db.Collection("categories"),
+	}
+
+	if err := repo.createIndexes(context.Background()); err != nil {
+		logger.Error(context.Background(), "failed to create indexes", err, map[string]any{
+			"collection": "categories",
+		})
+	}
+
+	return repo
+}
+
+func (r *CategoryRepository) createIndexes(ctx context.Context) error {
+	// Sibling categories must have distinct names; the same name may appear under different parents.
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "parent_id", Value: 1},
+				{Key: "name", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+func (r *CategoryRepository) Create(ctx context.Context, category *domain.Category) error {
+	doc, err := document.ToCategoryDocument(category)
+	if err != nil {
+		return parseError(err)
+	}
+
+	result, err := r.collection.InsertOne(ctx, doc)
+	if err != nil {
+		return parseError(err)
+	}
+
+	category.ID = domain.ID(result.InsertedID.(primitive.ObjectID).Hex())
+	return nil
+}
+
+func (r *CategoryRepository) GetByID(ctx context.Context, id domain.ID) (*domain.Category, error) {
+	doc, err := r.FindByID(ctx, string(id))
+	if err != nil {
+		return nil, err
+	}
+
+	return doc.ToDomain(), nil
+}
+
+func (r *CategoryRepository) List(ctx context.Context) ([]*domain.Category, error) {
+	docs, err := r.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make([]*domain.Category, len(docs))
+	for i, doc := range docs {
+		categories[i] = doc.ToDomain()
+	}
+
+	return categories, nil
+}
+
+func (r *CategoryRepository) Update(ctx context.Context, id domain.ID, update port.CategoryUpdate) (*domain.Category, error) {
+	objectID, err := primitive.ObjectIDFromHex(string(id))
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	set := bson.M{"updated_at": time.Now()}
+	if update.Name != nil {
+		set["name"] = *update.Name
+	}
+	if update.ParentID != nil {
+		parentID, err := document.ToOptionalObjectID(*update.ParentID)
+		if err != nil {
+			return nil, parseError(err)
+		}
+		set["parent_id"] = parentID
+	}
+
+	var doc document.CategoryDocument
+	err = r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": objectID},
+		bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&doc)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return doc.ToDomain(), nil
+}
+
+// Lock bumps a counter the domain never reads: Mongo only detects conflicts between
+// transactions that write the same document.
+func (r *CategoryRepository) Lock(ctx context.Context, id domain.ID) error {
+	objectID, err := primitive.ObjectIDFromHex(string(id))
+	if err != nil {
+		return parseError(err)
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$inc": bson.M{"lock_version": 1}})
+	if err != nil {
+		return parseError(err)
+	}
+	if result.MatchedCount == 0 {
+		return parseError(mongo.ErrNoDocuments)
+	}
+	return nil
+}
+
+func (r *CategoryRepository) Delete(ctx context.Context, id domain.ID) error {
+	return r.DeleteByID(ctx, string(id))
+}
@@ -0,0 +1,113 @@
+package repository_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/rafaelleal24/challenge/internal/adapters/mongo/repository"
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/port"
+	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
+)
+
+func TestCategoryRepository(t *testing.T) {
+	freshDB := testClient.Database("test_categories")
+	repo := repository.NewCategoryRepository(freshDB)
+	ctx := context.Background()
+
+	clothing := domain.NewCategory("Clothing", "")
+	books := domain.NewCategory("Books", "")
+	for _, c := range []*domain.Category{clothing, books} {
+		if err := repo.Create(ctx, c); err != nil {
+			t.Fatalf("setup: create category failed: %v", err)
+		}
+	}
+	shirts := domain.NewCategory("Shirts", clothing.ID)
+	if err := repo.Create(ctx, shirts); err != nil {
+		t.Fatalf("setup: create category failed: %v", err)
+	}
+
+	t.Run("gets a category with its parent", func(t *testing.T) {
+		found, err := repo.GetByID(ctx, shirts.ID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if found.Name != "Shirts" || found.ParentID != clothing.ID {
+			t.Fatalf("unexpected category %+v", found)
+		}
+	})
+
+	t.Run("lists every category by name", func(t *testing.T) {
+		categories, err := repo.List(ctx)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		names := make([]string, len(categories))
+		for i, c := range categories {
+			names[i] = c.Name
+		}
+		if !slices.Equal(names, []string{"Books", "Clothing", "Shirts"}) {
+			t.Fatalf("unexpected categories %v", names)
+		}
+	})
+
+	t.Run("rejects duplicate names among siblings only", func(t *testing.T) {
+		err := repo.Create(ctx, domain.NewCategory("Books", ""))
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			t.Fatalf("expected KindConflict, got %v", err)
+		}
+		if err := repo.Create(ctx, domain.NewCategory("Books", clothing.ID)); err != nil {
+			t.Fatalf("expected a nested category to reuse the name, got %v", err)
+		}
+	})
+
+	t.Run("moves a category to the top level", func(t *testing.T) {
+		var top domain.ID
+		updated, err := repo.Update(ctx, shirts.ID, port.CategoryUpdate{ParentID: &top})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if updated.ParentID != "" {
+			t.Fatalf("expected a top-level category, got parent %s", updated.ParentID)
+		}
+	})
+
+	t.Run("deletes a category", func(t *testing.T) {
+		if err := repo.Delete(ctx, books.ID); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		_, err := repo.GetByID(ctx, books.ID)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+
+	t.Run("update of a missing category", func(t *testing.T) {
+		name := "Gone"
+		_, err := repo.Update(ctx, books.ID, port.CategoryUpdate{Name: &name})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+	t.Run("locks a category without changing it", func(t *testing.T) {
+		if err := repo.Lock(ctx, clothing.ID); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		found, err := repo.GetByID(ctx, clothing.ID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if found.Name != "Clothing" || !found.UpdatedAt.Equal(clothing.UpdatedAt.Truncate(time.Millisecond)) {
+			t.Fatalf("expected the category unchanged, got %+v", found)
+		}
+	})
+
+	t.Run("lock of a missing category", func(t *testing.T) {
+		err := repo.Lock(ctx, books.ID)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+}
@@ -2,40 +2,316 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/rafaelleal24/challenge/internal/adapters/mongo/document"
+	"github.com/rafaelleal24/challenge/internal/adapters/outbox"
 	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/logger"
 	"github.com/rafaelleal24/challenge/internal/core/port"
+	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type CustomerRepository struct {
 	*BaseRepository[document.CustomerDocument]
+	db         *mongo.Database
 	collection *mongo.Collection
+	outbox     outbox.Repository
 }
 
-func NewCustomerRepository(db *mongo.Database) port.CustomerPort {
-	return &CustomerRepository{
+func NewCustomerRepository(db *mongo.Database, outbox outbox.Repository) port.CustomerPort {
+	repo := &CustomerRepository{
 		BaseRepository: NewBaseRepository[document.CustomerDocument](db, "customers"),
+		db:             db,
 		collection:     db.Collection("customers"),
+		outbox:         outbox,
 	}
+
+	if err := repo.createIndexes(context.Background()); err != nil {
+		logger.Error(context.Background(), "failed to create indexes", err, map[string]any{
+			"collection": "customers",
+		})
+	}
+
+	return repo
 }
 
-func (r *CustomerRepository) Create(ctx context.Context) (domain.ID, error) {
-	doc := document.CustomerDocument{}
-	result, err := r.collection.InsertOne(ctx, doc)
+func (r *CustomerRepository) createIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			// Customers created before profiles existed have no email, so only those that
+			// have one are indexed.
+			Keys: bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"email": bson.M{"$exists": true}}),
+		},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+func (r *CustomerRepository) Create(ctx context.Context, customer *domain.Customer) error {
+	result, err := r.collection.InsertOne(ctx, document.ToCustomerDocument(customer))
 	if err != nil {
-		return "", parseError(err)
+		return parseError(err)
 	}
-	return domain.ID(result.InsertedID.(primitive.ObjectID).Hex()), nil
+
+	customer.ID = domain.ID(result.InsertedID.(primitive.ObjectID).Hex())
+	return nil
+}
+
+func (r *CustomerRepository) GetByID(ctx context.Context, id domain.ID) (*domain.Customer, error) {
+	doc, err := r.FindByID(ctx, string(id))
+	if err != nil {
+		return nil, err
+	}
+
+	return doc.ToDomain(), nil
+}
+
+func (r *CustomerRepository) Update(ctx context.Context, id domain.ID, update port.CustomerUpdate) (*domain.Customer, error) {
+	objectID, err := primitive.ObjectIDFromHex(string(id))
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	set := bson.M{"updated_at": time.Now()}
+	unset := bson.M{}
+	if update.Name != nil {
+		set["name"] = *update.Name
+	}
+	if update.Email != nil {
+		set["email"] = *update.Email
+	}
+	if update.Phone != nil {
+		if *update.Phone == "" {
+			unset["phone"] = ""
+		} else {
+			set["phone"] = *update.Phone
+		}
+	}
+
+	changes := bson.M{"$set": set}
+	if len(unset) > 0 {
+		changes["$unset"] = unset
+	}
+
+	// An erased profile stays erased: it no longer matches, so updates report it as not found.
+	var doc document.CustomerDocument
+	err = r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": objectID, "erased_at": nil},
+		changes,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&doc)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return doc.ToDomain(), nil
 }
 
 func (r *CustomerRepository) Exists(ctx context.Context, id domain.ID) (bool, error) {
-	_, err := r.FindByID(ctx, string(id))
+	objectID, err := primitive.ObjectIDFromHex(string(id))
 	if err != nil {
 		return false, parseError(err)
 	}
 
+	_, err = r.FindOne(ctx, bson.M{"_id": objectID, "erased_at": nil})
+	if err != nil {
+		return false, err
+	}
+
 	return true, nil
 }
+
+func (r *CustomerRepository) AddAddress(ctx context.Context, customerID domain.ID, address *domain.CustomerAddress, makeDefault bool) (*domain.Customer, error) {
+	objectID, err := primitive.ObjectIDFromHex(string(customerID))
+	if err != nil {
+		return nil, parseError(err)
+	}
+	addressDoc, err := document.ToCustomerAddressDocument(*address)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	var defaultAddressID any = bson.M{"$ifNull": bson.A{"$default_address_id", addressDoc.ID}}
+	if makeDefault {
+		defaultAddressID = addressDoc.ID
+	}
+
+	// The address is wrapped in $literal so that values starting with "$" are not read as
+	// field paths by the update pipeline.
+	var doc document.CustomerDocument
+	err = r.collection.FindOneAndUpdate(ctx,
+		bson.M{
+			"_id":       objectID,
+			"erased_at": nil,
+			fmt.Sprintf("addresses.%d", domain.MaxCustomerAddresses-1): bson.M{"$exists": false},
+		},
+		mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{
+				"addresses": bson.M{"$concatArrays": bson.A{
+					bson.M{"$ifNull": bson.A{"$addresses", bson.A{}}},
+					bson.A{bson.M{"$literal": addressDoc}},
+				}},
+				"default_address_id": defaultAddressID,
+				"updated_at":         time.Now(),
+			}}},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		if _, err := r.FindOne(ctx, bson.M{"_id": objectID, "erased_at": nil}); err != nil {
+			return nil, err
+		}
+		return nil, serviceerrors.NewUnprocessableEntityError(fmt.Sprintf("a customer can have at most %d addresses", domain.MaxCustomerAddresses))
+	}
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	address.ID = domain.ID(addressDoc.ID.Hex())
+	return doc.ToDomain(), nil
+}
+
+func (r *CustomerRepository) ReplaceAddress(ctx context.Context, customerID domain.ID, address domain.CustomerAddress, makeDefault bool) (*domain.Customer, error) {
+	objectID, err := primitive.ObjectIDFromHex(string(customerID))
+	if err != nil {
+		return nil, parseError(err)
+	}
+	addressDoc, err := document.ToCustomerAddressDocument(address)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	set := bson.M{
+		"addresses.$": addressDoc,
+		"updated_at":  time.Now(),
+	}
+	if makeDefault {
+		set["default_address_id"] = addressDoc.ID
+	}
+
+	var doc document.CustomerDocument
+	err = r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": objectID, "addresses._id": addressDoc.ID},
+		bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&doc)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return doc.ToDomain(), nil
+}
+
+func (r *CustomerRepository) RemoveAddress(ctx context.Context, customerID, addressID domain.ID) (*domain.Customer, error) {
+	objectID, err := primitive.ObjectIDFromHex(string(customerID))
+	if err != nil {
+		return nil, parseError(err)
+	}
+	addressObjectID, err := primitive.ObjectIDFromHex(string(addressID))
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	var doc document.CustomerDocument
+	err = r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": objectID, "addresses._id": addressObjectID},
+		mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{
+				"addresses": bson.M{"$filter": bson.M{
+					"input": "$addresses",
+					"cond":  bson.M{"$ne": bson.A{"$$this._id", addressObjectID}},
+				}},
+				"updated_at": time.Now(),
+			}}},
+			// Removing the default address hands the default over to the first one left, or
+			// drops it when the address book is now empty.
+			{{Key: "$set", Value: bson.M{
+				"default_address_id": bson.M{"$cond": bson.A{
+					bson.M{"$eq": bson.A{"$default_address_id", addressObjectID}},
+					bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$addresses._id", 0}}, "$$REMOVE"}},
+					"$default_address_id",
+				}},
+			}}},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&doc)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return doc.ToDomain(), nil
+}
+
+func (r *CustomerRepository) Erase(ctx context.Context, id domain.ID, erasedAt time.Time, event domain.Event) error {
+	objectID, err := primitive.ObjectIDFromHex(string(id))
+	if err != nil {
+		return parseError(err)
+	}
+	eventData, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return withTransaction(ctx, r.db.Client(), func(txCtx context.Context) error {
+		result, err := r.collection.UpdateOne(txCtx,
+			bson.M{"_id": objectID, "erased_at": nil},
+			bson.M{
+				"$unset": bson.M{
+					"name":               "",
+					"email":              "",
+					"phone":              "",
+					"addresses":          "",
+					"default_address_id": "",
+				},
+				"$set": bson.M{
+					"erased_at":  erasedAt,
+					"updated_at": erasedAt,
+				},
+			},
+		)
+		if err != nil {
+			return parseError(err)
+		}
+		if result.MatchedCount == 0 {
+			return serviceerrors.NewNotFoundError("entity not found")
+		}
+
+		return r.outbox.Insert(txCtx, outbox.Entry{
+			EventName:  event.GetName(),
+			EntityName: event.GetEntityName(),
+			EventData:  eventData,
+		})
+	})
+}
+
+// Lock bumps a counter the domain never reads: Mongo only detects conflicts between
+// transactions that write the same document.
+func (r *CustomerRepository) Lock(ctx context.Context, id domain.ID) error {
+	objectID, err := primitive.ObjectIDFromHex(string(id))
+	if err != nil {
+		return parseError(err)
+	}
+
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": objectID, "erased_at": nil},
+		bson.M{"$inc": bson.M{"lock_version": 1}},
+	)
+	if err != nil {
+		return parseError(err)
+	}
+	if result.MatchedCount == 0 {
+		return parseError(mongo.ErrNoDocuments)
+	}
+	return nil
+}
@@ -3,40 +3,245 @@ package repository_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/rafaelleal24/challenge/internal/adapters/mongo/repository"
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/port"
 	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+func newTestCustomer() *domain.Customer {
+	return domain.NewCustomer("Ada Lovelace", primitive.NewObjectID().Hex()+"@example.com", "+5511999999999")
+}
+
 func TestCustomerRepository_Create(t *testing.T) {
-	repo := repository.NewCustomerRepository(testDB)
+	repo := repository.NewCustomerRepository(testDB, repository.NewOutboxRepository(testDB))
+	ctx := context.Background()
+
+	t.Run("creates customer and sets a valid ID", func(t *testing.T) {
+		customer := newTestCustomer()
+		if err := repo.Create(ctx, customer); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(string(customer.ID)) != 24 {
+			t.Fatalf("expected 24-char hex ID, got %q (len=%d)", customer.ID, len(string(customer.ID)))
+		}
+
+		found, err := repo.GetByID(ctx, customer.ID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if found.Name != customer.Name || found.Email != customer.Email || found.Phone != customer.Phone {
+			t.Fatalf("expected %+v, got %+v", customer, found)
+		}
+	})
+
+	t.Run("rejects a duplicate email", func(t *testing.T) {
+		customer := newTestCustomer()
+		if err := repo.Create(ctx, customer); err != nil {
+			t.Fatalf("setup: create failed: %v", err)
+		}
+
+		err := repo.Create(ctx, domain.NewCustomer("Someone Else", customer.Email, ""))
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			t.Fatalf("expected KindConflict, got %v", err)
+		}
+	})
+}
+
+func TestCustomerRepository_GetByID(t *testing.T) {
+	repo := repository.NewCustomerRepository(testDB, repository.NewOutboxRepository(testDB))
+	ctx := context.Background()
+
+	_, err := repo.GetByID(ctx, "aabbccddee112233aabbccdd")
+	if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+		t.Fatalf("expected KindNotFound, got %v", err)
+	}
+}
+
+func TestCustomerRepository_Update(t *testing.T) {
+	repo := repository.NewCustomerRepository(testDB, repository.NewOutboxRepository(testDB))
+	ctx := context.Background()
+
+	t.Run("updates given fields and removes an empty phone", func(t *testing.T) {
+		customer := newTestCustomer()
+		if err := repo.Create(ctx, customer); err != nil {
+			t.Fatalf("setup: create failed: %v", err)
+		}
+
+		name, phone := "Ada King", ""
+		updated, err := repo.Update(ctx, customer.ID, port.CustomerUpdate{Name: &name, Phone: &phone})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if updated.Name != name || updated.Email != customer.Email || updated.Phone != "" {
+			t.Fatalf("unexpected customer after update: %+v", updated)
+		}
+		if !updated.UpdatedAt.After(customer.UpdatedAt) {
+			t.Fatal("expected updated_at to move forward")
+		}
+	})
+
+	t.Run("rejects an email in use", func(t *testing.T) {
+		first, second := newTestCustomer(), newTestCustomer()
+		for _, customer := range []*domain.Customer{first, second} {
+			if err := repo.Create(ctx, customer); err != nil {
+				t.Fatalf("setup: create failed: %v", err)
+			}
+		}
+
+		_, err := repo.Update(ctx, second.ID, port.CustomerUpdate{Email: &first.Email})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			t.Fatalf("expected KindConflict, got %v", err)
+		}
+	})
+
+	t.Run("returns not found for unknown customer", func(t *testing.T) {
+		name := "Nobody"
+		_, err := repo.Update(ctx, "aabbccddee112233aabbccdd", port.CustomerUpdate{Name: &name})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+}
+
+func newTestCustomerAddress(line1 string) *domain.CustomerAddress {
+	return &domain.CustomerAddress{
+		Label: "home",
+		Address: domain.Address{
+			Recipient:  "Ada Lovelace",
+			Line1:      line1,
+			City:       "São Paulo",
+			PostalCode: "01000-000",
+			Country:    "BR",
+		},
+	}
+}
+
+func TestCustomerRepository_Addresses(t *testing.T) {
+	repo := repository.NewCustomerRepository(testDB, repository.NewOutboxRepository(testDB))
 	ctx := context.Background()
 
-	t.Run("creates customer and returns valid ID", func(t *testing.T) {
-		id, err := repo.Create(ctx)
+	t.Run("first address becomes the default", func(t *testing.T) {
+		customer := newTestCustomer()
+		if err := repo.Create(ctx, customer); err != nil {
+			t.Fatalf("setup: create failed: %v", err)
+		}
+
+		first, second := newTestCustomerAddress("Rua A, 1"), newTestCustomerAddress("$where")
+		if _, err := repo.AddAddress(ctx, customer.ID, first, false); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		updated, err := repo.AddAddress(ctx, customer.ID, second, false)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if first.ID == "" || second.ID == "" {
+			t.Fatal("expected address IDs to be set")
+		}
+		if len(updated.Addresses) != 2 || updated.DefaultAddressID != first.ID {
+			t.Fatalf("expected 2 addresses with %s as default, got %+v", first.ID, updated)
+		}
+		if saved := updated.FindAddress(second.ID); saved == nil || saved.Line1 != "$where" {
+			t.Fatalf("expected the address to be stored as given, got %+v", saved)
+		}
+	})
+
+	t.Run("moves the default and replaces an address", func(t *testing.T) {
+		customer := newTestCustomer()
+		if err := repo.Create(ctx, customer); err != nil {
+			t.Fatalf("setup: create failed: %v", err)
+		}
+		first, second := newTestCustomerAddress("Rua A, 1"), newTestCustomerAddress("Rua B, 2")
+		for _, address := range []*domain.CustomerAddress{first, second} {
+			if _, err := repo.AddAddress(ctx, customer.ID, address, false); err != nil {
+				t.Fatalf("setup: add address failed: %v", err)
+			}
+		}
+
+		replacement := *second
+		replacement.Line1 = "Rua C, 3"
+		updated, err := repo.ReplaceAddress(ctx, customer.ID, replacement, true)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if updated.DefaultAddressID != second.ID || updated.FindAddress(second.ID).Line1 != "Rua C, 3" {
+			t.Fatalf("expected %s replaced and default, got %+v", second.ID, updated)
+		}
+	})
+
+	t.Run("removing the default hands it to the next address", func(t *testing.T) {
+		customer := newTestCustomer()
+		if err := repo.Create(ctx, customer); err != nil {
+			t.Fatalf("setup: create failed: %v", err)
+		}
+		first, second := newTestCustomerAddress("Rua A, 1"), newTestCustomerAddress("Rua B, 2")
+		for _, address := range []*domain.CustomerAddress{first, second} {
+			if _, err := repo.AddAddress(ctx, customer.ID, address, false); err != nil {
+				t.Fatalf("setup: add address failed: %v", err)
+			}
+		}
+
+		updated, err := repo.RemoveAddress(ctx, customer.ID, first.ID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(updated.Addresses) != 1 || updated.DefaultAddressID != second.ID {
+			t.Fatalf("expected %s left as default, got %+v", second.ID, updated)
+		}
+
+		updated, err = repo.RemoveAddress(ctx, customer.ID, second.ID)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
-		if id == "" {
-			t.Fatal("expected non-empty ID")
+		if len(updated.Addresses) != 0 || updated.DefaultAddressID != "" {
+			t.Fatalf("expected an empty address book without default, got %+v", updated)
+		}
+
+		_, err = repo.RemoveAddress(ctx, customer.ID, second.ID)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+
+	t.Run("limits the address book", func(t *testing.T) {
+		customer := newTestCustomer()
+		if err := repo.Create(ctx, customer); err != nil {
+			t.Fatalf("setup: create failed: %v", err)
+		}
+		for i := 0; i < domain.MaxCustomerAddresses; i++ {
+			if _, err := repo.AddAddress(ctx, customer.ID, newTestCustomerAddress("Rua A, 1"), false); err != nil {
+				t.Fatalf("setup: add address failed: %v", err)
+			}
+		}
+
+		_, err := repo.AddAddress(ctx, customer.ID, newTestCustomerAddress("Rua A, 1"), false)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindUnprocessableEntity) {
+			t.Fatalf("expected KindUnprocessableEntity, got %v", err)
 		}
-		if len(string(id)) != 24 {
-			t.Fatalf("expected 24-char hex ID, got %q (len=%d)", id, len(string(id)))
+	})
+
+	t.Run("returns not found for unknown customer", func(t *testing.T) {
+		_, err := repo.AddAddress(ctx, "aabbccddee112233aabbccdd", newTestCustomerAddress("Rua A, 1"), false)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
 		}
 	})
 }
 
 func TestCustomerRepository_Exists(t *testing.T) {
-	repo := repository.NewCustomerRepository(testDB)
+	repo := repository.NewCustomerRepository(testDB, repository.NewOutboxRepository(testDB))
 	ctx := context.Background()
 
 	t.Run("returns true for existing customer", func(t *testing.T) {
-		id, err := repo.Create(ctx)
-		if err != nil {
+		customer := newTestCustomer()
+		if err := repo.Create(ctx, customer); err != nil {
 			t.Fatalf("setup: create failed: %v", err)
 		}
 
-		exists, err := repo.Exists(ctx, id)
+		exists, err := repo.Exists(ctx, customer.ID)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
@@ -65,3 +270,100 @@ func TestCustomerRepository_Exists(t *testing.T) {
 		}
 	})
 }
+
+func TestCustomerRepository_Lock(t *testing.T) {
+	repo := repository.NewCustomerRepository(testDB, repository.NewOutboxRepository(testDB))
+	ctx := context.Background()
+
+	t.Run("locks a customer without changing them", func(t *testing.T) {
+		customer := newTestCustomer()
+		if err := repo.Create(ctx, customer); err != nil {
+			t.Fatalf("setup: create failed: %v", err)
+		}
+
+		if err := repo.Lock(ctx, customer.ID); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		found, err := repo.GetByID(ctx, customer.ID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if found.Name != customer.Name || !found.UpdatedAt.Equal(customer.UpdatedAt.Truncate(time.Millisecond)) {
+			t.Fatalf("expected the customer unchanged, got %+v", found)
+		}
+	})
+
+	t.Run("returns not found for unknown customer", func(t *testing.T) {
+		err := repo.Lock(ctx, "aabbccddee112233aabbccdd")
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+}
+
+func TestCustomerRepository_Erase(t *testing.T) {
+	freshDB := testClient.Database("test_customer_erase")
+	outboxRepo := repository.NewOutboxRepository(freshDB)
+	repo := repository.NewCustomerRepository(freshDB, outboxRepo)
+	ctx := context.Background()
+
+	customer := newTestCustomer()
+	if err := repo.Create(ctx, customer); err != nil {
+		t.Fatalf("setup: create failed: %v", err)
+	}
+	if _, err := repo.AddAddress(ctx, customer.ID, newTestCustomerAddress("Rua A, 1"), false); err != nil {
+		t.Fatalf("setup: add address failed: %v", err)
+	}
+
+	erasedAt := time.Now().UTC().Truncate(time.Millisecond)
+	if err := repo.Erase(ctx, customer.ID, erasedAt, domain.NewCustomerErasedEvent(customer.ID, erasedAt)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	erased, err := repo.GetByID(ctx, customer.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if erased.Name != "" || erased.Email != "" || erased.Phone != "" || len(erased.Addresses) != 0 || erased.DefaultAddressID != "" {
+		t.Fatalf("expected personal data to be removed, got %+v", erased)
+	}
+	if erased.ErasedAt == nil || !erased.ErasedAt.Equal(erasedAt) {
+		t.Fatalf("expected erased at %v, got %v", erasedAt, erased.ErasedAt)
+	}
+
+	entries, err := outboxRepo.FetchPending(ctx, 100)
+	if err != nil {
+		t.Fatalf("expected no error fetching outbox, got %v", err)
+	}
+	if len(entries) != 1 || entries[0].EventName != "customer.erased" {
+		t.Fatalf("expected one customer.erased outbox entry, got %+v", entries)
+	}
+
+	if _, err := repo.Exists(ctx, customer.ID); !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+		t.Fatalf("expected erased customer to no longer exist, got %v", err)
+	}
+
+	err = repo.Erase(ctx, customer.ID, erasedAt, domain.NewCustomerErasedEvent(customer.ID, erasedAt))
+	if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+		t.Fatalf("expected KindNotFound when erasing twice, got %v", err)
+	}
+
+	name := "Ada Again"
+	if _, err := repo.Update(ctx, customer.ID, port.CustomerUpdate{Name: &name}); !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+		t.Fatalf("expected KindNotFound when updating an erased customer, got %v", err)
+	}
+	if _, err := repo.AddAddress(ctx, customer.ID, newTestCustomerAddress("Rua B, 2"), false); !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+		t.Fatalf("expected KindNotFound when adding an address to an erased customer, got %v", err)
+	}
+	if err := repo.Lock(ctx, customer.ID); !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+		t.Fatalf("expected KindNotFound when locking an erased customer, got %v", err)
+	}
+	if erased, err := repo.GetByID(ctx, customer.ID); err != nil || erased.Name != "" || len(erased.Addresses) != 0 {
+		t.Fatalf("expected the erased profile to stay empty, got %+v (%v)", erased, err)
+	}
+
+	reused := domain.NewCustomer("Someone Else", customer.Email, "")
+	if err := repo.Create(ctx, reused); err != nil {
+		t.Fatalf("expected the erased email to be free again, got %v", err)
+	}
+}
@@ -19,6 +19,22 @@ import (
 	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
 )
 
+const (
+	orderCustomerStatusIndex = "customer_id_status_created_at_id"
+	// indexNotFoundCode is the server error code of dropping an index that does not exist.
+	indexNotFoundCode = 27
+)
+
+// legacyOrderIndexes are the indexes built for offset pagination, which the keyset
+// listing indexes replace.
+var legacyOrderIndexes = []string{
+	"customer_id_1",
+	"status_1",
+	"customer_id_1_status_1",
+	"created_at_-1",
+	"total_amount_1",
+}
+
 type OrderRepository struct {
 	*BaseRepository[document.OrderDocument]
 	db         *mongo.Database
@@ -46,26 +62,66 @@ func NewOrderRepository(db *mongo.Database, outbox outbox.Repository) port.Order
 }
 
 func (r *OrderRepository) createIndexes(ctx context.Context) error {
+	// Listing indexes end with _id so keyset pages can seek straight to the cursor.
 	indexes := []mongo.IndexModel{
 		{
-			Keys:    bson.D{{Key: "customer_id", Value: 1}},
+			Keys: bson.D{
+				{Key: "customer_id", Value: 1},
+				{Key: "created_at", Value: -1},
+				{Key: "_id", Value: -1},
+			},
 			Options: options.Index().SetUnique(false),
 		},
 		{
-			Keys:    bson.D{{Key: "status", Value: 1}},
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "created_at", Value: -1},
+				{Key: "_id", Value: -1},
+			},
 			Options: options.Index().SetUnique(false),
 		},
 		{
 			Keys: bson.D{
 				{Key: "customer_id", Value: 1},
 				{Key: "status", Value: 1},
+				{Key: "created_at", Value: -1},
+				{Key: "_id", Value: -1},
+			},
+			Options: options.Index().SetName(orderCustomerStatusIndex).SetUnique(false),
+		},
+		{
+			Keys: bson.D{
+				{Key: "created_at", Value: -1},
+				{Key: "_id", Value: -1},
 			},
 			Options: options.Index().SetUnique(false),
 		},
+		{
+			Keys: bson.D{
+				{Key: "total_amount", Value: 1},
+				{Key: "_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(false),
+		},
+	}
+
+	if _, err := r.collection.Indexes().CreateMany(ctx, indexes); err != nil {
+		return err
 	}
 
-	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
-	return err
+	// Indexes are replaced rather than changed in place, so the old ones are dropped once
+	// the new ones are built.
+	for _, name := range legacyOrderIndexes {
+		_, err := r.collection.Indexes().DropOne(ctx, name)
+		var serverErr mongo.ServerError
+		if errors.As(err, &serverErr) && serverErr.HasErrorCode(indexNotFoundCode) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (r *OrderRepository) Create(ctx context.Context, order *domain.Order) error {
@@ -93,6 +149,27 @@ func (r *OrderRepository) Create(ctx context.Context, order *domain.Order) error
 	return nil
 }
 
+func (r *OrderRepository) CreateWithOutbox(ctx context.Context, order *domain.Order, buildEvent func(order *domain.Order) domain.Event) error {
+	return withTransaction(ctx, r.db.Client(), func(txCtx context.Context) error {
+		if err := r.Create(txCtx, order); err != nil {
+			return err
+		}
+
+		event := buildEvent(order)
+		eventData, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+
+		entry := outbox.Entry{
+			EventName:  event.GetName(),
+			EntityName: event.GetEntityName(),
+			EventData:  eventData,
+		}
+		return r.outbox.Insert(txCtx, entry)
+	})
+}
+
 func (r *OrderRepository) GetByID(ctx context.Context, id domain.ID) (*domain.Order, error) {
 	doc, err := r.FindByID(ctx, string(id))
 	if err != nil {
@@ -102,54 +179,101 @@ func (r *OrderRepository) GetByID(ctx context.Context, id domain.ID) (*domain.Or
 	return doc.ToDomain(), nil
 }
 
-func (r *OrderRepository) GetByCustomerID(ctx context.Context, customerID domain.ID, limit, offset int64) ([]*domain.Order, error) {
-	objectID, err := primitive.ObjectIDFromHex(string(customerID))
+func (r *OrderRepository) GetByCustomerID(ctx context.Context, customerID domain.ID, status domain.OrderStatus, limit int64, cursor string) (*port.Page[*domain.Order], error) {
+	return r.List(ctx, port.OrderFilter{CustomerID: customerID, Status: status}, port.PageRequest{
+		Limit:      limit,
+		Cursor:     cursor,
+		SortBy:     port.OrderSortCreatedAt,
+		Descending: true,
+	})
+}
+
+func (r *OrderRepository) GetByStatus(ctx context.Context, status domain.OrderStatus, limit int64, cursor string) (*port.Page[*domain.Order], error) {
+	return r.List(ctx, port.OrderFilter{Status: status}, port.PageRequest{
+		Limit:      limit,
+		Cursor:     cursor,
+		SortBy:     port.OrderSortCreatedAt,
+		Descending: true,
+	})
+}
+
+var orderSortFields = map[string]string{
+	port.OrderSortCreatedAt:   "created_at",
+	port.OrderSortTotalAmount: "total_amount",
+}
+
+func (r *OrderRepository) List(ctx context.Context, filter port.OrderFilter, page port.PageRequest) (*port.Page[*domain.Order], error) {
+	query, err := toOrderQuery(filter)
 	if err != nil {
-		return nil, parseError(err)
+		return nil, err
 	}
 
-	opts := options.Find().
-		SetLimit(limit).
-		SetSkip(offset).
-		SetSort(bson.D{{Key: "created_at", Value: -1}})
-
-	filter := bson.M{"customer_id": objectID}
+	sortField, ok := orderSortFields[page.SortBy]
+	if !ok {
+		return nil, serviceerrors.NewInvalidRequestError("invalid sort field")
+	}
 
-	docs, err := r.Find(ctx, filter, opts)
+	docs, err := r.FindPage(ctx, query, sortField, page)
 	if err != nil {
 		return nil, err
 	}
 
-	orders := make([]*domain.Order, len(docs))
-	for i, doc := range docs {
+	orders := make([]*domain.Order, len(docs.Items))
+	for i, doc := range docs.Items {
 		orders[i] = doc.ToDomain()
 	}
 
-	return orders, nil
+	return &port.Page[*domain.Order]{
+		Items:      orders,
+		NextCursor: docs.NextCursor,
+		Total:      docs.Total,
+	}, nil
 }
 
-func (r *OrderRepository) GetByStatus(ctx context.Context, status domain.OrderStatus, limit, offset int64) ([]*domain.Order, error) {
-	opts := options.Find().
-		SetLimit(limit).
-		SetSkip(offset).
-		SetSort(bson.D{{Key: "created_at", Value: -1}})
-
-	filter := bson.M{"status": string(status)}
+func toOrderQuery(filter port.OrderFilter) (bson.M, error) {
+	query := bson.M{}
+	if filter.CustomerID != "" {
+		objectID, err := primitive.ObjectIDFromHex(string(filter.CustomerID))
+		if err != nil {
+			return nil, parseError(err)
+		}
+		query["customer_id"] = objectID
+	}
+	if filter.Status != "" {
+		query["status"] = string(filter.Status)
+	}
 
-	docs, err := r.Find(ctx, filter, opts)
-	if err != nil {
-		return nil, err
+	createdAt := bson.M{}
+	if !filter.CreatedFrom.IsZero() {
+		createdAt["$gte"] = filter.CreatedFrom
+	}
+	if !filter.CreatedTo.IsZero() {
+		createdAt["$lte"] = filter.CreatedTo
+	}
+	if !filter.CreatedBefore.IsZero() {
+		createdAt["$lt"] = filter.CreatedBefore
+	}
+	if len(createdAt) > 0 {
+		query["created_at"] = createdAt
 	}
 
-	orders := make([]*domain.Order, len(docs))
-	for i, doc := range docs {
-		orders[i] = doc.ToDomain()
+	totalAmount := bson.M{}
+	if filter.MinTotal != nil {
+		totalAmount["$gte"] = int64(*filter.MinTotal)
+	}
+	if filter.MaxTotal != nil {
+		totalAmount["$lte"] = int64(*filter.MaxTotal)
+	}
+	if len(totalAmount) > 0 {
+		query["total_amount"] = totalAmount
 	}
 
-	return orders, nil
+	return query, nil
 }
 
-func (r *OrderRepository) UpdateStatusWithOutbox(ctx context.Context, id domain.ID, status domain.OrderStatus, event domain.Event) error {
+// UpdateStatusWithOutbox only applies the change while the order still has the expected
+// version and previous status, so a writer acting on a stale read gets a conflict.
+func (r *OrderRepository) UpdateStatusWithOutbox(ctx context.Context, id domain.ID, expectedVersion int64, change domain.OrderStatusChange, event domain.Event) error {
 	objectID, err := primitive.ObjectIDFromHex(string(id))
 	if err != nil {
 		return parseError(err)
@@ -160,24 +284,31 @@ func (r *OrderRepository) UpdateStatusWithOutbox(ctx context.Context, id domain.
 		return err
 	}
 
-	session, err := r.db.Client().StartSession()
-	if err != nil {
-		return err
+	filter := bson.M{
+		"_id":     objectID,
+		"status":  string(change.From),
+		"version": versionFilter(expectedVersion),
 	}
-	defer session.EndSession(ctx)
 
-	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
-		result, err := r.collection.UpdateOne(sessCtx, bson.M{"_id": objectID}, bson.M{
+	return withTransaction(ctx, r.db.Client(), func(txCtx context.Context) error {
+		result, err := r.collection.UpdateOne(txCtx, filter, bson.M{
 			"$set": bson.M{
-				"status":     string(status),
-				"updated_at": time.Now(),
+				"status":     string(change.To),
+				"updated_at": change.ChangedAt,
+			},
+			"$push": bson.M{
+				"status_history": document.ToStatusChangeDocument(change),
 			},
+			"$inc": bson.M{"version": 1},
 		})
 		if err != nil {
-			return nil, parseError(err)
+			return parseError(err)
 		}
 		if result.MatchedCount == 0 {
-			return nil, serviceerrors.NewNotFoundError("entity not found")
+			if _, err := r.FindByID(txCtx, string(id)); err != nil {
+				return err
+			}
+			return serviceerrors.NewConflictError("order was modified by another request")
 		}
 
 		entry := outbox.Entry{
@@ -185,16 +316,46 @@ func (r *OrderRepository) UpdateStatusWithOutbox(ctx context.Context, id domain.
 			EntityName: event.GetEntityName(),
 			EventData:  eventData,
 		}
-		if err := r.outbox.Insert(sessCtx, entry); err != nil {
-			return nil, err
-		}
-
-		return nil, nil
+		return r.outbox.Insert(txCtx, entry)
 	})
+}
 
-	return err
+// versionFilter matches orders stored before versioning was introduced as version 0.
+func versionFilter(expectedVersion int64) any {
+	if expectedVersion == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+	return expectedVersion
 }
 
 func (r *OrderRepository) Delete(ctx context.Context, id domain.ID) error {
 	return r.DeleteByID(ctx, string(id))
 }
+
+func (r *OrderRepository) EraseShippingAddresses(ctx context.Context, customerID domain.ID) error {
+	objectID, err := primitive.ObjectIDFromHex(string(customerID))
+	if err != nil {
+		return parseError(err)
+	}
+
+	_, err = r.collection.UpdateMany(ctx,
+		bson.M{"customer_id": objectID, "shipping_address": bson.M{"$exists": true}},
+		bson.M{
+			"$unset": bson.M{
+				"shipping_address.recipient":   "",
+				"shipping_address.line1":       "",
+				"shipping_address.line2":       "",
+				"shipping_address.city":        "",
+				"shipping_address.state":       "",
+				"shipping_address.postal_code": "",
+			},
+			"$set": bson.M{"updated_at": time.Now()},
+			"$inc": bson.M{"version": 1},
+		},
+	)
+	if err != nil {
+		return parseError(err)
+	}
+
+	return nil
+}
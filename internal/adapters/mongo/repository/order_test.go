@@ -7,7 +7,10 @@ import (
 
 	"github.com/rafaelleal24/challenge/internal/adapters/mongo/repository"
 	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/port"
 	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func createTestOrder(t *testing.T, orderRepo interface {
@@ -83,6 +86,53 @@ func TestOrderRepository_Create(t *testing.T) {
 	})
 }
 
+func TestOrderRepository_CreateWithOutbox(t *testing.T) {
+	freshDB := testClient.Database("test_order_create_outbox")
+	outboxRepo := repository.NewOutboxRepository(freshDB)
+	orderRepo := repository.NewOrderRepository(freshDB, outboxRepo)
+	ctx := context.Background()
+
+	t.Run("creates order and outbox entry", func(t *testing.T) {
+		items := []domain.OrderItem{
+			*domain.NewOrderItem("aabbccddee112233aabbccd1", "Product A", 2, domain.Amount(1500)),
+		}
+		order := domain.NewOrder("ccddaabbee112233aabbccdd", domain.OrderStatusCreated, items)
+
+		err := orderRepo.CreateWithOutbox(ctx, order, func(created *domain.Order) domain.Event {
+			return domain.NewOrderCreatedEvent(created)
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if order.ID == "" {
+			t.Fatal("expected order ID to be assigned")
+		}
+
+		entries, err := outboxRepo.FetchPending(ctx, 100)
+		if err != nil {
+			t.Fatalf("expected no error fetching outbox, got %v", err)
+		}
+		if len(entries) != 1 || entries[0].EventName != "order.created" {
+			t.Fatalf("expected one order.created outbox entry, got %+v", entries)
+		}
+	})
+
+	t.Run("rejects order with pre-existing ID", func(t *testing.T) {
+		items := []domain.OrderItem{
+			*domain.NewOrderItem("aabbccddee112233aabbccd1", "Product A", 1, domain.Amount(500)),
+		}
+		order := domain.NewOrder("ccddaabbee112233aabbccdd", domain.OrderStatusCreated, items)
+		order.ID = "aabbccddee112233aabbccdd"
+
+		err := orderRepo.CreateWithOutbox(ctx, order, func(created *domain.Order) domain.Event {
+			return domain.NewOrderCreatedEvent(created)
+		})
+		if err == nil {
+			t.Fatal("expected error for order with existing ID, got nil")
+		}
+	})
+}
+
 func TestOrderRepository_GetByID(t *testing.T) {
 	outboxRepo := repository.NewOutboxRepository(testDB)
 	orderRepo := repository.NewOrderRepository(testDB, outboxRepo)
@@ -110,6 +160,25 @@ func TestOrderRepository_GetByID(t *testing.T) {
 		}
 	})
 
+	t.Run("keeps the shipping address", func(t *testing.T) {
+		address := &domain.Address{Recipient: "Ada", Line1: "Rua A, 1", City: "São Paulo", PostalCode: "01000-000", Country: "BR"}
+		order := domain.NewOrder(customerID, domain.OrderStatusCreated, []domain.OrderItem{
+			*domain.NewOrderItem("aabbccddee112233aabbccd1", "Product A", 1, domain.Amount(1000)),
+		})
+		order.ShippingAddress = address
+		if err := orderRepo.Create(ctx, order); err != nil {
+			t.Fatalf("setup: create order failed: %v", err)
+		}
+
+		found, err := orderRepo.GetByID(ctx, order.ID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if found.ShippingAddress == nil || *found.ShippingAddress != *address {
+			t.Fatalf("expected shipping address %+v, got %+v", address, found.ShippingAddress)
+		}
+	})
+
 	t.Run("returns not found for non-existing order", func(t *testing.T) {
 		_, err := orderRepo.GetByID(ctx, "aabbccddee112233aabb0000")
 		if err == nil {
@@ -131,6 +200,41 @@ func TestOrderRepository_GetByID(t *testing.T) {
 	})
 }
 
+func TestOrderRepository_ReplacesLegacyIndexes(t *testing.T) {
+	freshDB := testClient.Database("test_order_indexes")
+	ctx := context.Background()
+	if _, err := freshDB.Collection("orders").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "customer_id", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}}},
+		{Keys: bson.D{{Key: "customer_id", Value: 1}, {Key: "status", Value: 1}}},
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "total_amount", Value: 1}}},
+	}); err != nil {
+		t.Fatalf("setup: create legacy indexes failed: %v", err)
+	}
+
+	repository.NewOrderRepository(freshDB, repository.NewOutboxRepository(freshDB))
+
+	specs, err := freshDB.Collection("orders").Indexes().ListSpecifications(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	names := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		names[spec.Name] = true
+	}
+	for _, legacy := range []string{"customer_id_1", "status_1", "customer_id_1_status_1", "created_at_-1", "total_amount_1"} {
+		if names[legacy] {
+			t.Fatalf("expected legacy index %s to be dropped, got %v", legacy, names)
+		}
+	}
+	for _, keyset := range []string{"customer_id_status_created_at_id", "created_at_-1__id_-1", "total_amount_1__id_1"} {
+		if !names[keyset] {
+			t.Fatalf("expected keyset index %s, got %v", keyset, names)
+		}
+	}
+}
+
 func TestOrderRepository_GetByCustomerID(t *testing.T) {
 	freshDB := testClient.Database("test_order_by_customer")
 	outboxRepo := repository.NewOutboxRepository(freshDB)
@@ -139,12 +243,12 @@ func TestOrderRepository_GetByCustomerID(t *testing.T) {
 	customerID := domain.ID("ccddaabbee112233aabbcc01")
 
 	t.Run("returns empty list when no orders", func(t *testing.T) {
-		orders, err := orderRepo.GetByCustomerID(ctx, customerID, 10, 0)
+		orders, err := orderRepo.GetByCustomerID(ctx, customerID, "", 10, "")
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
-		if len(orders) != 0 {
-			t.Fatalf("expected 0 orders, got %d", len(orders))
+		if len(orders.Items) != 0 {
+			t.Fatalf("expected 0 orders, got %d", len(orders.Items))
 		}
 	})
 
@@ -155,14 +259,105 @@ func TestOrderRepository_GetByCustomerID(t *testing.T) {
 		otherCustomer := domain.ID("ccddaabbee112233aabbcc02")
 		createTestOrder(t, orderRepo, otherCustomer)
 
-		orders, err := orderRepo.GetByCustomerID(ctx, customerID, 10, 0)
+		orders, err := orderRepo.GetByCustomerID(ctx, customerID, "", 10, "")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(orders.Items) != 2 {
+			t.Fatalf("expected 2 orders for customer, got %d", len(orders.Items))
+		}
+	})
+
+	t.Run("pages with cursor newest first", func(t *testing.T) {
+		pagedCustomer := domain.ID("ccddaabbee112233aabbcc03")
+		oldest := createTestOrder(t, orderRepo, pagedCustomer)
+		middle := createTestOrder(t, orderRepo, pagedCustomer)
+		newest := createTestOrder(t, orderRepo, pagedCustomer)
+
+		first, err := orderRepo.GetByCustomerID(ctx, pagedCustomer, "", 2, "")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(first.Items) != 2 || first.Items[0].ID != newest.ID || first.Items[1].ID != middle.ID {
+			t.Fatalf("expected newest and middle orders on first page, got %+v", first.Items)
+		}
+		if first.NextCursor == "" {
+			t.Fatal("expected next cursor")
+		}
+
+		// Orders placed after the first page must not shift the following pages.
+		createTestOrder(t, orderRepo, pagedCustomer)
+
+		second, err := orderRepo.GetByCustomerID(ctx, pagedCustomer, "", 2, first.NextCursor)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
-		if len(orders) != 2 {
-			t.Fatalf("expected 2 orders for customer, got %d", len(orders))
+		if len(second.Items) != 1 || second.Items[0].ID != oldest.ID {
+			t.Fatalf("expected only the oldest order on second page, got %+v", second.Items)
+		}
+		if second.NextCursor != "" {
+			t.Fatalf("expected no next cursor, got %q", second.NextCursor)
 		}
 	})
+
+	t.Run("filters by status", func(t *testing.T) {
+		statusCustomer := domain.ID("ccddaabbee112233aabbcc04")
+		createTestOrder(t, orderRepo, statusCustomer)
+		cancelled := domain.NewOrder(statusCustomer, domain.OrderStatusCancelled, []domain.OrderItem{
+			*domain.NewOrderItem("aabbccddee112233aabbccd1", "Product A", 1, domain.Amount(1000)),
+		})
+		if err := orderRepo.Create(ctx, cancelled); err != nil {
+			t.Fatalf("setup: create order failed: %v", err)
+		}
+
+		orders, err := orderRepo.GetByCustomerID(ctx, statusCustomer, domain.OrderStatusCancelled, 10, "")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(orders.Items) != 1 || orders.Items[0].ID != cancelled.ID {
+			t.Fatalf("expected only the cancelled order, got %+v", orders.Items)
+		}
+	})
+}
+
+func TestOrderRepository_EraseShippingAddresses(t *testing.T) {
+	freshDB := testClient.Database("test_order_erase_addresses")
+	outboxRepo := repository.NewOutboxRepository(freshDB)
+	orderRepo := repository.NewOrderRepository(freshDB, outboxRepo)
+	ctx := context.Background()
+	customerID := domain.ID("ccddaabbee112233aabbcc05")
+
+	shipped := domain.NewOrder(customerID, domain.OrderStatusCreated, []domain.OrderItem{
+		*domain.NewOrderItem("aabbccddee112233aabbccd1", "Product A", 1, domain.Amount(1000)),
+	})
+	shipped.ShippingAddress = &domain.Address{Recipient: "Ada", Line1: "Rua A, 1", City: "São Paulo", PostalCode: "01000-000", Country: "BR"}
+	if err := orderRepo.Create(ctx, shipped); err != nil {
+		t.Fatalf("setup: create order failed: %v", err)
+	}
+	withoutAddress := createTestOrder(t, orderRepo, customerID)
+
+	if err := orderRepo.EraseShippingAddresses(ctx, customerID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	found, err := orderRepo.GetByID(ctx, shipped.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if found.ShippingAddress == nil || *found.ShippingAddress != (domain.Address{Country: "BR"}) {
+		t.Fatalf("expected only the country to be kept, got %+v", found.ShippingAddress)
+	}
+	if found.Version != shipped.Version+1 {
+		t.Fatalf("expected version %d, got %d", shipped.Version+1, found.Version)
+	}
+
+	untouched, err := orderRepo.GetByID(ctx, withoutAddress.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if untouched.ShippingAddress != nil || untouched.Version != withoutAddress.Version {
+		t.Fatalf("expected the order without address to be left alone, got %+v", untouched)
+	}
 }
 
 func TestOrderRepository_GetByStatus(t *testing.T) {
@@ -173,32 +368,158 @@ func TestOrderRepository_GetByStatus(t *testing.T) {
 	customerID := domain.ID("ccddaabbee112233aabbccdd")
 
 	t.Run("returns empty for status with no orders", func(t *testing.T) {
-		orders, err := orderRepo.GetByStatus(ctx, domain.OrderStatusShipped, 10, 0)
+		orders, err := orderRepo.GetByStatus(ctx, domain.OrderStatusShipped, 10, "")
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
-		if len(orders) != 0 {
-			t.Fatalf("expected 0 orders, got %d", len(orders))
+		if len(orders.Items) != 0 {
+			t.Fatalf("expected 0 orders, got %d", len(orders.Items))
 		}
 	})
 
 	t.Run("filters by status", func(t *testing.T) {
 		createTestOrder(t, orderRepo, customerID)
 
-		orders, err := orderRepo.GetByStatus(ctx, domain.OrderStatusCreated, 10, 0)
+		orders, err := orderRepo.GetByStatus(ctx, domain.OrderStatusCreated, 10, "")
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
-		if len(orders) < 1 {
+		if len(orders.Items) < 1 {
 			t.Fatal("expected at least 1 order with status 'created'")
 		}
 
-		shipped, err := orderRepo.GetByStatus(ctx, domain.OrderStatusShipped, 10, 0)
+		shipped, err := orderRepo.GetByStatus(ctx, domain.OrderStatusShipped, 10, "")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(shipped.Items) != 0 {
+			t.Fatalf("expected 0 shipped orders, got %d", len(shipped.Items))
+		}
+	})
+}
+
+func TestOrderRepository_List(t *testing.T) {
+	freshDB := testClient.Database("test_order_list")
+	outboxRepo := repository.NewOutboxRepository(freshDB)
+	orderRepo := repository.NewOrderRepository(freshDB, outboxRepo)
+	ctx := context.Background()
+	customerID := domain.ID("ccddaabbee112233aabbcc11")
+	otherCustomer := domain.ID("ccddaabbee112233aabbcc12")
+
+	for i := 0; i < 3; i++ {
+		createTestOrder(t, orderRepo, customerID)
+	}
+	createTestOrder(t, orderRepo, otherCustomer)
+
+	t.Run("filters by customer and pages with cursor", func(t *testing.T) {
+		filter := port.OrderFilter{CustomerID: customerID}
+		page := port.PageRequest{Limit: 2, SortBy: port.OrderSortCreatedAt, Descending: true, IncludeTotal: true}
+
+		first, err := orderRepo.List(ctx, filter, page)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(first.Items) != 2 {
+			t.Fatalf("expected 2 orders, got %d", len(first.Items))
+		}
+		if first.NextCursor == "" {
+			t.Fatal("expected next cursor")
+		}
+		if first.Total == nil || *first.Total != 3 {
+			t.Fatalf("expected total 3, got %v", first.Total)
+		}
+
+		page.Cursor = first.NextCursor
+		second, err := orderRepo.List(ctx, filter, page)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(second.Items) != 1 {
+			t.Fatalf("expected 1 order, got %d", len(second.Items))
+		}
+		if second.NextCursor != "" {
+			t.Fatalf("expected no next cursor, got %q", second.NextCursor)
+		}
+		for _, order := range append(first.Items, second.Items...) {
+			if order.CustomerID != customerID {
+				t.Fatalf("expected customer %s, got %s", customerID, order.CustomerID)
+			}
+		}
+	})
+
+	t.Run("created before excludes orders placed at the cutoff", func(t *testing.T) {
+		all, err := orderRepo.List(ctx, port.OrderFilter{CustomerID: customerID}, port.PageRequest{Limit: 10, SortBy: port.OrderSortCreatedAt})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		newest := all.Items[len(all.Items)-1]
+
+		result, err := orderRepo.List(ctx,
+			port.OrderFilter{CustomerID: customerID, CreatedBefore: newest.CreatedAt},
+			port.PageRequest{Limit: 10, SortBy: port.OrderSortCreatedAt},
+		)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		for _, order := range result.Items {
+			if !order.CreatedAt.Before(newest.CreatedAt) {
+				t.Fatalf("expected orders placed before %v, got one at %v", newest.CreatedAt, order.CreatedAt)
+			}
+		}
+	})
+
+	t.Run("filters by total amount range", func(t *testing.T) {
+		minTotal := domain.Amount(5000)
+		result, err := orderRepo.List(ctx, port.OrderFilter{MinTotal: &minTotal}, port.PageRequest{Limit: 10, SortBy: port.OrderSortTotalAmount})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(result.Items) != 0 {
+			t.Fatalf("expected 0 orders above 5000, got %d", len(result.Items))
+		}
+	})
+
+	t.Run("pages by total amount with cursor", func(t *testing.T) {
+		page := port.PageRequest{Limit: 3, SortBy: port.OrderSortTotalAmount}
+
+		first, err := orderRepo.List(ctx, port.OrderFilter{}, page)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		page.Cursor = first.NextCursor
+		second, err := orderRepo.List(ctx, port.OrderFilter{}, page)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
-		if len(shipped) != 0 {
-			t.Fatalf("expected 0 shipped orders, got %d", len(shipped))
+
+		seen := map[domain.ID]bool{}
+		for _, order := range append(first.Items, second.Items...) {
+			if seen[order.ID] {
+				t.Fatalf("order %s returned on both pages", order.ID)
+			}
+			seen[order.ID] = true
+		}
+		if len(seen) != 4 {
+			t.Fatalf("expected 4 distinct orders, got %d", len(seen))
+		}
+	})
+
+	t.Run("rejects invalid cursor", func(t *testing.T) {
+		_, err := orderRepo.List(ctx, port.OrderFilter{}, port.PageRequest{Limit: 10, SortBy: port.OrderSortCreatedAt, Cursor: "%%%"})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+
+	t.Run("rejects cursor issued for another sort field", func(t *testing.T) {
+		first, err := orderRepo.List(ctx, port.OrderFilter{}, port.PageRequest{Limit: 1, SortBy: port.OrderSortCreatedAt})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		_, err = orderRepo.List(ctx, port.OrderFilter{}, port.PageRequest{Limit: 1, SortBy: port.OrderSortTotalAmount, Cursor: first.NextCursor})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
 		}
 	})
 }
@@ -215,7 +536,8 @@ func TestOrderRepository_UpdateStatusWithOutbox(t *testing.T) {
 		event := domain.NewOrderUpdateStatusEvent(
 			order.ID, domain.OrderStatusProcessing, domain.OrderStatusCreated, order.CreatedAt, customerID,
 		)
-		err := orderRepo.UpdateStatusWithOutbox(ctx, order.ID, domain.OrderStatusProcessing, event)
+		change := domain.NewOrderStatusChange(domain.OrderStatusCreated, domain.OrderStatusProcessing, "ops", "paid")
+		err := orderRepo.UpdateStatusWithOutbox(ctx, order.ID, order.Version, change, event)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
@@ -224,6 +546,12 @@ func TestOrderRepository_UpdateStatusWithOutbox(t *testing.T) {
 		if updated.Status != domain.OrderStatusProcessing {
 			t.Fatalf("expected status %s, got %s", domain.OrderStatusProcessing, updated.Status)
 		}
+		if len(updated.StatusHistory) != 1 {
+			t.Fatalf("expected 1 status history entry, got %d", len(updated.StatusHistory))
+		}
+		if updated.StatusHistory[0].Actor != "ops" || updated.StatusHistory[0].Reason != "paid" {
+			t.Fatalf("unexpected status history entry: %+v", updated.StatusHistory[0])
+		}
 
 		entries, err := outboxRepo.FetchPending(ctx, 100)
 		if err != nil {
@@ -241,12 +569,50 @@ func TestOrderRepository_UpdateStatusWithOutbox(t *testing.T) {
 		}
 	})
 
+	t.Run("returns conflict when version is stale", func(t *testing.T) {
+		order := createTestOrder(t, orderRepo, customerID)
+		event := domain.NewOrderUpdateStatusEvent(
+			order.ID, domain.OrderStatusProcessing, domain.OrderStatusCreated, time.Now(), customerID,
+		)
+		change := domain.NewOrderStatusChange(domain.OrderStatusCreated, domain.OrderStatusProcessing, "", "")
+		if err := orderRepo.UpdateStatusWithOutbox(ctx, order.ID, order.Version, change, event); err != nil {
+			t.Fatalf("expected no error on first update, got %v", err)
+		}
+
+		// A second writer that read the same version must not apply its change.
+		err := orderRepo.UpdateStatusWithOutbox(ctx, order.ID, order.Version, change, event)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			t.Fatalf("expected KindConflict, got %v", err)
+		}
+
+		updated, _ := orderRepo.GetByID(ctx, order.ID)
+		if updated.Version != order.Version+1 {
+			t.Fatalf("expected version %d, got %d", order.Version+1, updated.Version)
+		}
+		if len(updated.StatusHistory) != 1 {
+			t.Fatalf("expected 1 status history entry, got %d", len(updated.StatusHistory))
+		}
+	})
+
+	t.Run("returns conflict when previous status does not match", func(t *testing.T) {
+		order := createTestOrder(t, orderRepo, customerID)
+		event := domain.NewOrderUpdateStatusEvent(
+			order.ID, domain.OrderStatusShipped, domain.OrderStatusProcessing, time.Now(), customerID,
+		)
+		change := domain.NewOrderStatusChange(domain.OrderStatusProcessing, domain.OrderStatusShipped, "", "")
+		err := orderRepo.UpdateStatusWithOutbox(ctx, order.ID, order.Version, change, event)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			t.Fatalf("expected KindConflict, got %v", err)
+		}
+	})
+
 	t.Run("returns not found for non-existing order", func(t *testing.T) {
 		nonExistingID := domain.ID("aabbccddee112233aabb0000")
 		event := domain.NewOrderUpdateStatusEvent(
 			nonExistingID, domain.OrderStatusProcessing, domain.OrderStatusCreated, time.Now(), customerID,
 		)
-		err := orderRepo.UpdateStatusWithOutbox(ctx, nonExistingID, domain.OrderStatusProcessing, event)
+		change := domain.NewOrderStatusChange(domain.OrderStatusCreated, domain.OrderStatusProcessing, "", "")
+		err := orderRepo.UpdateStatusWithOutbox(ctx, nonExistingID, 1, change, event)
 		if err == nil {
 			t.Fatal("expected error, got nil")
 		}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"encoding/base64"
+	"errors"
+
+	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// pageCursor marks the position after the last document of a page: the value of the
+// sort field and the _id used to break ties between documents sharing that value.
+type pageCursor struct {
+	Field string             `bson:"f"`
+	Value bson.RawValue      `bson:"v"`
+	ID    primitive.ObjectID `bson:"id"`
+}
+
+func newPageCursor(doc any, sortField string) (*pageCursor, error) {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	value, err := bson.Raw(raw).LookupErr(sortField)
+	if err != nil {
+		return nil, err
+	}
+	id, ok := bson.Raw(raw).Lookup("_id").ObjectIDOK()
+	if !ok {
+		return nil, errors.New("cannot build cursor: document has no ObjectID")
+	}
+	return &pageCursor{Field: sortField, Value: value, ID: id}, nil
+}
+
+func (c *pageCursor) encode() (string, error) {
+	raw, err := bson.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodePageCursor(cursor, sortField string) (*pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, serviceerrors.NewInvalidRequestError("invalid cursor")
+	}
+	var c pageCursor
+	if err := bson.Unmarshal(raw, &c); err != nil || c.Field != sortField || c.ID.IsZero() {
+		return nil, serviceerrors.NewInvalidRequestError("invalid cursor")
+	}
+	return &c, nil
+}
+
+// after matches the documents that come after the cursor in the given sort direction.
+func (c *pageCursor) after(direction int) bson.M {
+	op := "$gt"
+	if direction < 0 {
+		op = "$lt"
+	}
+	return bson.M{"$or": bson.A{
+		bson.M{c.Field: bson.M{op: c.Value}},
+		bson.M{c.Field: c.Value, "_id": bson.M{op: c.ID}},
+	}}
+}
@@ -2,31 +2,126 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/rafaelleal24/challenge/internal/adapters/mongo/document"
+	"github.com/rafaelleal24/challenge/internal/adapters/outbox"
 	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/logger"
 	"github.com/rafaelleal24/challenge/internal/core/port"
 	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type ProductRepository struct {
 	*BaseRepository[document.ProductDocument]
+	db         *mongo.Database
 	collection *mongo.Collection
+	outbox     outbox.Repository
 }
 
-func NewProductRepository(db *mongo.Database) port.ProductPort {
-	return &ProductRepository{
+func NewProductRepository(db *mongo.Database, outbox outbox.Repository) port.ProductPort {
+	repo := &ProductRepository{
 		BaseRepository: NewBaseRepository[document.ProductDocument](db, "products"),
+		db:             db,
 		collection:     db.Collection("products"),
+		outbox:         outbox,
 	}
+
+	if err := repo.createIndexes(context.Background()); err != nil {
+		logger.Error(context.Background(), "failed to create indexes", err, map[string]any{
+			"collection": "products",
+		})
+	}
+
+	return repo
+}
+
+func (r *ProductRepository) createIndexes(ctx context.Context) error {
+	// Listings only show products that are not archived, so every sort index leads with
+	// archived_at and ends with _id for keyset pagination.
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "archived_at", Value: 1},
+				{Key: "name", Value: 1},
+				{Key: "_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(false),
+		},
+		{
+			Keys: bson.D{
+				{Key: "archived_at", Value: 1},
+				{Key: "price", Value: 1},
+				{Key: "_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(false),
+		},
+		{
+			Keys: bson.D{
+				{Key: "archived_at", Value: 1},
+				{Key: "created_at", Value: -1},
+				{Key: "_id", Value: -1},
+			},
+			Options: options.Index().SetUnique(false),
+		},
+		{
+			Keys: bson.D{
+				{Key: "category_id", Value: 1},
+				{Key: "archived_at", Value: 1},
+			},
+			Options: options.Index().SetUnique(false),
+		},
+		{
+			Keys: bson.D{
+				{Key: "tags", Value: 1},
+				{Key: "archived_at", Value: 1},
+			},
+			Options: options.Index().SetUnique(false),
+		},
+		{
+			// Product SKUs are optional, so only products that have one are indexed.
+			Keys: bson.D{{Key: "sku", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"sku": bson.M{"$exists": true}}),
+		},
+		{
+			// SKUs are unique across products. Products without variants are left out, as they
+			// would otherwise all index a null SKU.
+			Keys: bson.D{{Key: "variants.sku", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"variants.sku": bson.M{"$exists": true}}),
+		},
+		{
+			Keys: bson.D{
+				{Key: "name", Value: "text"},
+				{Key: "description", Value: "text"},
+			},
+			Options: options.Index().SetWeights(bson.D{
+				{Key: "name", Value: 5},
+				{Key: "description", Value: 1},
+			}),
+		},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	return err
 }
 
 func (r *ProductRepository) Create(ctx context.Context, product *domain.Product) error {
-	doc := document.ToProductDocument(product)
+	doc, err := document.ToProductDocument(product)
+	if err != nil {
+		return parseError(err)
+	}
 
 	result, err := r.collection.InsertOne(ctx, doc)
 	if err != nil {
@@ -46,36 +141,720 @@ func (r *ProductRepository) GetByID(ctx context.Context, id domain.ID) (*domain.
 	return doc.ToDomain(), nil
 }
 
-func (r *ProductRepository) DeductStock(ctx context.Context, id domain.ID, quantity int) error {
+func (r *ProductRepository) GetByIDs(ctx context.Context, ids []domain.ID) ([]*domain.Product, error) {
+	objectIDs := make([]primitive.ObjectID, len(ids))
+	for i, id := range ids {
+		objectID, err := primitive.ObjectIDFromHex(string(id))
+		if err != nil {
+			return nil, parseError(err)
+		}
+		objectIDs[i] = objectID
+	}
+
+	docs, err := r.Find(ctx, bson.M{"_id": bson.M{"$in": objectIDs}})
+	if err != nil {
+		return nil, err
+	}
+
+	products := make([]*domain.Product, len(docs))
+	for i, doc := range docs {
+		products[i] = doc.ToDomain()
+	}
+
+	return products, nil
+}
+
+func (r *ProductRepository) Update(ctx context.Context, id domain.ID, update port.ProductUpdate) (*domain.Product, error) {
+	objectID, err := primitive.ObjectIDFromHex(string(id))
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	set := bson.M{"updated_at": time.Now()}
+	if update.Name != nil {
+		set["name"] = *update.Name
+	}
+	if update.Description != nil {
+		set["description"] = *update.Description
+	}
+	if update.Price != nil {
+		set["price"] = int64(*update.Price)
+	}
+	if update.LowStockThreshold != nil {
+		set["low_stock_threshold"] = *update.LowStockThreshold
+	}
+	unset := bson.M{}
+	if update.CategoryID != nil {
+		categoryID, err := document.ToOptionalObjectID(*update.CategoryID)
+		if err != nil {
+			return nil, parseError(err)
+		}
+		if categoryID != nil {
+			set["category_id"] = *categoryID
+		} else {
+			unset["category_id"] = ""
+		}
+	}
+	if update.Tags != nil {
+		if len(*update.Tags) > 0 {
+			set["tags"] = *update.Tags
+		} else {
+			unset["tags"] = ""
+		}
+	}
+	changes := bson.M{"$set": set}
+	if len(unset) > 0 {
+		changes["$unset"] = unset
+	}
+
+	var doc document.ProductDocument
+	err = r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": objectID, "archived_at": nil},
+		changes,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return nil, r.archivedOrNotFound(ctx, id)
+	}
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return doc.ToDomain(), nil
+}
+
+// Archive hides the product from listings and new orders. Archiving an archived product is a no-op.
+func (r *ProductRepository) Archive(ctx context.Context, id domain.ID) error {
 	objectID, err := primitive.ObjectIDFromHex(string(id))
 	if err != nil {
 		return parseError(err)
 	}
 
-	result := r.collection.FindOneAndUpdate(ctx,
-		bson.M{"_id": objectID, "stock": bson.M{"$gte": quantity}},
-		bson.M{"$inc": bson.M{"stock": -quantity}},
+	now := time.Now()
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": objectID, "archived_at": nil},
+		bson.M{"$set": bson.M{"archived_at": now, "updated_at": now}},
 	)
-	if result.Err() != nil {
-		if result.Err() == mongo.ErrNoDocuments {
-			return serviceerrors.NewUnprocessableEntityError(fmt.Sprintf("insufficient stock for product %s", id))
+	if err != nil {
+		return parseError(err)
+	}
+	if result.MatchedCount == 0 {
+		_, err := r.FindByID(ctx, string(id))
+		return err
+	}
+
+	return nil
+}
+
+// UpsertBySKU resolves the SKU against product and variant SKUs alike, as they share one
+// namespace. A duplicate key means another writer took the SKU between resolving and
+// inserting it, so the upsert is resolved once more against what that writer stored.
+func (r *ProductRepository) UpsertBySKU(ctx context.Context, product *domain.Product) (bool, error) {
+	created, err := r.upsertBySKU(ctx, product)
+	if mongo.IsDuplicateKeyError(err) {
+		created, err = r.upsertBySKU(ctx, product)
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		return false, serviceerrors.NewConflictError(fmt.Sprintf("sku %s is already in use", product.SKU))
+	}
+	if err != nil {
+		return false, parseError(err)
+	}
+	return created, nil
+}
+
+func (r *ProductRepository) upsertBySKU(ctx context.Context, product *domain.Product) (bool, error) {
+	categoryID, err := document.ToOptionalObjectID(product.CategoryID)
+	if err != nil {
+		return false, err
+	}
+
+	var existing document.ProductDocument
+	err = r.collection.FindOne(ctx, bson.M{"$or": bson.A{
+		bson.M{"sku": product.SKU},
+		bson.M{"variants.sku": product.SKU},
+	}}).Decode(&existing)
+	if err == mongo.ErrNoDocuments {
+		if product.Price == 0 {
+			return false, serviceerrors.NewUnprocessableEntityError(fmt.Sprintf("sku %s is new, a price is required", product.SKU))
+		}
+		doc, err := document.ToProductDocument(product)
+		if err != nil {
+			return false, err
+		}
+		result, err := r.collection.InsertOne(ctx, doc)
+		if err != nil {
+			return false, err
 		}
-		return result.Err()
+		product.ID = domain.ID(result.InsertedID.(primitive.ObjectID).Hex())
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	// An archived product keeps its SKUs, so they are neither brought back nor reused.
+	if existing.ArchivedAt != nil {
+		return false, serviceerrors.NewConflictError(fmt.Sprintf("sku %s belongs to an archived product", product.SKU))
+	}
+
+	set := bson.M{
+		"description": product.Description,
+		"updated_at":  time.Now(),
+	}
+	unset := bson.M{}
+	if product.LowStockThreshold != nil {
+		set["low_stock_threshold"] = *product.LowStockThreshold
+	} else {
+		unset["low_stock_threshold"] = ""
+	}
+	if categoryID != nil {
+		set["category_id"] = *categoryID
+	} else {
+		unset["category_id"] = ""
+	}
+	if len(product.Tags) > 0 {
+		set["tags"] = product.Tags
+	} else {
+		unset["tags"] = ""
+	}
+
+	// A variant SKU names the variant and prices it; the other fields belong to its product.
+	filter := bson.M{"_id": existing.ID, "archived_at": nil}
+	if existing.SKU == product.SKU {
+		set["name"] = product.Name
+		if product.Price != 0 {
+			set["price"] = int64(product.Price)
+		}
+	} else {
+		filter["variants.sku"] = product.SKU
+		set["variants.$.name"] = product.Name
+		if product.Price != 0 {
+			set["variants.$.price"] = int64(product.Price)
+		} else {
+			unset["variants.$.price"] = ""
+		}
+	}
+	changes := bson.M{"$set": set}
+	if len(unset) > 0 {
+		changes["$unset"] = unset
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, changes)
+	if err != nil {
+		return false, err
+	}
+	if result.MatchedCount == 0 {
+		return false, serviceerrors.NewConflictError(fmt.Sprintf("sku %s belongs to an archived product", product.SKU))
+	}
+	return false, nil
+}
+
+func (r *ProductRepository) Stream(ctx context.Context, fn func(product *domain.Product) error) error {
+	cursor, err := r.collection.Find(ctx, bson.M{"archived_at": nil}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return parseError(err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc document.ProductDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return parseError(err)
+		}
+		if err := fn(doc.ToDomain()); err != nil {
+			return err
+		}
+	}
+
+	return parseError(cursor.Err())
+}
+
+func (r *ProductRepository) ExistsInCategory(ctx context.Context, categoryID domain.ID) (bool, error) {
+	objectID, err := primitive.ObjectIDFromHex(string(categoryID))
+	if err != nil {
+		return false, parseError(err)
+	}
+
+	count, err := r.collection.CountDocuments(ctx, bson.M{"category_id": objectID}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, parseError(err)
+	}
+
+	return count > 0, nil
+}
+
+func (r *ProductRepository) archivedOrNotFound(ctx context.Context, id domain.ID) error {
+	if _, err := r.FindByID(ctx, string(id)); err != nil {
+		return err
+	}
+	return serviceerrors.NewUnprocessableEntityError(fmt.Sprintf("product %s is archived", id))
+}
+
+func (r *ProductRepository) DeductStock(ctx context.Context, id domain.ID, sku string, quantity int, alert port.StockAlertBuilder) error {
+	objectID, err := primitive.ObjectIDFromHex(string(id))
+	if err != nil {
+		return parseError(err)
+	}
+
+	return r.deductWithAlert(ctx,
+		availableStockFilter(objectID, sku, quantity),
+		stockIncrement(sku, bson.M{"stock": -quantity}),
+		sku,
+		alert,
+		func() error {
+			return serviceerrors.NewUnprocessableEntityError(fmt.Sprintf("insufficient stock for %s", stockSubject(id, sku)))
+		},
+	)
+}
+
+func (r *ProductRepository) RestoreStock(ctx context.Context, id domain.ID, sku string, quantity int) error {
+	objectID, err := primitive.ObjectIDFromHex(string(id))
+	if err != nil {
+		return parseError(err)
+	}
+
+	result, err := r.collection.UpdateOne(ctx,
+		stockCountersFilter(objectID, sku, nil),
+		stockIncrement(sku, bson.M{"stock": quantity}),
+	)
+	if err != nil {
+		return parseError(err)
+	}
+	if result.MatchedCount == 0 {
+		return serviceerrors.NewNotFoundError(fmt.Sprintf("%s not found", stockSubject(id, sku)))
 	}
 
 	return nil
 }
 
-func (r *ProductRepository) GetAll(ctx context.Context) ([]*domain.Product, error) {
-	docs, err := r.Find(ctx, bson.M{})
+func (r *ProductRepository) ReserveStock(ctx context.Context, id domain.ID, sku string, quantity int) error {
+	objectID, err := primitive.ObjectIDFromHex(string(id))
+	if err != nil {
+		return parseError(err)
+	}
+
+	result, err := r.collection.UpdateOne(ctx,
+		availableStockFilter(objectID, sku, quantity),
+		stockIncrement(sku, bson.M{"reserved": quantity}),
+	)
+	if err != nil {
+		return parseError(err)
+	}
+	if result.MatchedCount == 0 {
+		return serviceerrors.NewUnprocessableEntityError(fmt.Sprintf("insufficient stock for %s", stockSubject(id, sku)))
+	}
+
+	return nil
+}
+
+func (r *ProductRepository) ReleaseReservedStock(ctx context.Context, id domain.ID, sku string, quantity int) error {
+	objectID, err := primitive.ObjectIDFromHex(string(id))
+	if err != nil {
+		return parseError(err)
+	}
+
+	result, err := r.collection.UpdateOne(ctx,
+		stockCountersFilter(objectID, sku, bson.M{"reserved": bson.M{"$gte": quantity}}),
+		stockIncrement(sku, bson.M{"reserved": -quantity}),
+	)
+	if err != nil {
+		return parseError(err)
+	}
+	if result.MatchedCount == 0 {
+		return serviceerrors.NewConflictError(fmt.Sprintf("%s does not have %d units reserved", stockSubject(id, sku), quantity))
+	}
+
+	return nil
+}
+
+func (r *ProductRepository) CommitReservedStock(ctx context.Context, id domain.ID, sku string, quantity int) error {
+	objectID, err := primitive.ObjectIDFromHex(string(id))
+	if err != nil {
+		return parseError(err)
+	}
+
+	result, err := r.collection.UpdateOne(ctx,
+		stockCountersFilter(objectID, sku, bson.M{"reserved": bson.M{"$gte": quantity}, "stock": bson.M{"$gte": quantity}}),
+		stockIncrement(sku, bson.M{"stock": -quantity, "reserved": -quantity}),
+	)
+	if err != nil {
+		return parseError(err)
+	}
+	if result.MatchedCount == 0 {
+		return serviceerrors.NewConflictError(fmt.Sprintf("%s does not have %d units reserved", stockSubject(id, sku), quantity))
+	}
+
+	return nil
+}
+
+// deductWithAlert applies a stock deduction of the product or its variant sku and writes the
+// resulting alert, if any, to the outbox in one transaction. notMatched builds the error
+// returned when filter matches nothing.
+func (r *ProductRepository) deductWithAlert(ctx context.Context, filter, update bson.M, sku string, alert port.StockAlertBuilder, notMatched func() error) error {
+	return withTransaction(ctx, r.db.Client(), func(txCtx context.Context) error {
+		var doc document.ProductDocument
+		err := r.collection.FindOneAndUpdate(txCtx, filter, update,
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&doc)
+		if err == mongo.ErrNoDocuments {
+			return notMatched()
+		}
+		if err != nil {
+			return parseError(err)
+		}
+
+		return r.writeStockAlert(txCtx, alert(doc.ToDomain(), sku))
+	})
+}
+
+func (r *ProductRepository) ReserveStockBatch(ctx context.Context, lines []port.StockLine, alert port.StockAlertBuilder) error {
+	return r.applyStockBatch(ctx, lines, "reserved", 1, alert)
+}
+
+func (r *ProductRepository) DeductStockBatch(ctx context.Context, lines []port.StockLine, alert port.StockAlertBuilder) error {
+	return r.applyStockBatch(ctx, lines, "stock", -1, alert)
+}
+
+// applyStockBatch checks every product or variant against the available stock its lines ask
+// for and, when all of them have enough, changes counter by sign times each line quantity
+// with one conditional bulk write. Lines for the same product and SKU are merged. The check
+// and the write share a transaction, so a concurrent change to any product aborts and retries
+// the whole batch. The events alert builds from the products as changed, one per merged line,
+// go to the outbox in the same transaction.
+func (r *ProductRepository) applyStockBatch(ctx context.Context, lines []port.StockLine, counter string, sign int, alert port.StockAlertBuilder) error {
+	lines = mergeStockLines(lines)
+	var ids []domain.ID
+	objectIDs := make(map[domain.ID]primitive.ObjectID, len(lines))
+	for _, line := range lines {
+		if _, ok := objectIDs[line.ProductID]; ok {
+			continue
+		}
+		objectID, err := primitive.ObjectIDFromHex(string(line.ProductID))
+		if err != nil {
+			return parseError(err)
+		}
+		objectIDs[line.ProductID] = objectID
+		ids = append(ids, line.ProductID)
+	}
+	inIDs := make([]primitive.ObjectID, len(ids))
+	for i, id := range ids {
+		inIDs[i] = objectIDs[id]
+	}
+
+	return withTransaction(ctx, r.db.Client(), func(txCtx context.Context) error {
+		docs, err := r.Find(txCtx, bson.M{"_id": bson.M{"$in": inIDs}})
+		if err != nil {
+			return err
+		}
+		products := make(map[domain.ID]*domain.Product, len(docs))
+		for _, doc := range docs {
+			product := doc.ToDomain()
+			products[product.ID] = product
+		}
+
+		var shortages []domain.StockShortage
+		for _, line := range lines {
+			product, ok := products[line.ProductID]
+			if !ok || (line.SKU != "" && product.Variant(line.SKU) == nil) {
+				return serviceerrors.NewNotFoundError(fmt.Sprintf("%s not found", stockSubject(line.ProductID, line.SKU)))
+			}
+			if available := product.AvailableStockOf(line.SKU); available < line.Quantity {
+				shortages = append(shortages, domain.StockShortage{
+					ProductID: line.ProductID,
+					SKU:       line.SKU,
+					Requested: line.Quantity,
+					Available: max(available, 0),
+				})
+			}
+		}
+		if len(shortages) > 0 {
+			return newInsufficientStockError(shortages)
+		}
+
+		models := make([]mongo.WriteModel, len(lines))
+		for i, line := range lines {
+			models[i] = mongo.NewUpdateOneModel().
+				SetFilter(availableStockFilter(objectIDs[line.ProductID], line.SKU, line.Quantity)).
+				SetUpdate(stockIncrement(line.SKU, bson.M{counter: sign * line.Quantity}))
+		}
+		result, err := r.collection.BulkWrite(txCtx, models, options.BulkWrite().SetOrdered(false))
+		if err != nil {
+			return parseError(err)
+		}
+		if result.MatchedCount != int64(len(models)) {
+			return serviceerrors.NewConflictError("product stock changed while it was being updated")
+		}
+
+		for _, line := range lines {
+			product := products[line.ProductID]
+			if counter == "reserved" {
+				product.Reserved += sign * line.Quantity
+			} else {
+				product.Stock += sign * line.Quantity
+			}
+		}
+		for _, line := range lines {
+			if err := r.writeStockAlert(txCtx, alert(products[line.ProductID], line.SKU)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// mergeStockLines sums the quantities of lines per product and SKU, keeping them in the order
+// they first appear.
+func mergeStockLines(lines []port.StockLine) []port.StockLine {
+	type stockKey struct {
+		productID domain.ID
+		sku       string
+	}
+	var merged []port.StockLine
+	positions := make(map[stockKey]int, len(lines))
+	for _, line := range lines {
+		key := stockKey{line.ProductID, line.SKU}
+		if i, ok := positions[key]; ok {
+			merged[i].Quantity += line.Quantity
+			continue
+		}
+		positions[key] = len(merged)
+		merged = append(merged, line)
+	}
+	return merged
+}
+
+func newInsufficientStockError(shortages []domain.StockShortage) error {
+	subjects := make([]string, len(shortages))
+	for i, shortage := range shortages {
+		subjects[i] = stockSubject(shortage.ProductID, shortage.SKU)
+	}
+	return serviceerrors.NewUnprocessableEntityErrorWithDetails(
+		fmt.Sprintf("insufficient stock for %s", strings.Join(subjects, ", ")),
+		map[string]any{"insufficient_stock": shortages},
+	)
+}
+
+func (r *ProductRepository) writeStockAlert(ctx context.Context, event domain.Event) error {
+	if event == nil {
+		return nil
+	}
+	eventData, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return r.outbox.Insert(ctx, outbox.Entry{
+		EventName:  event.GetName(),
+		EntityName: event.GetEntityName(),
+		EventData:  eventData,
+	})
+}
+
+// stockSubject names the product, or its variant, a stock error is about.
+func stockSubject(id domain.ID, sku string) string {
+	if sku == "" {
+		return fmt.Sprintf("product %s", id)
+	}
+	return fmt.Sprintf("product %s variant %s", id, sku)
+}
+
+// stockIncrement changes the product counters by deltas and, when sku is set, the counters of
+// the variant matched by the filter by the same amounts, so the product keeps the totals.
+func stockIncrement(sku string, deltas bson.M) bson.M {
+	inc := bson.M{}
+	for field, delta := range deltas {
+		inc[field] = delta
+		if sku != "" {
+			inc["variants.$."+field] = delta
+		}
+	}
+	return bson.M{"$inc": inc, "$set": bson.M{"updated_at": time.Now()}}
+}
+
+// stockCountersFilter matches the product, or its variant sku, whose counters satisfy
+// conditions.
+func stockCountersFilter(objectID primitive.ObjectID, sku string, conditions bson.M) bson.M {
+	if sku == "" {
+		filter := bson.M{"_id": objectID}
+		for field, condition := range conditions {
+			filter[field] = condition
+		}
+		return filter
+	}
+
+	variant := bson.M{"sku": sku}
+	for field, condition := range conditions {
+		variant[field] = condition
+	}
+	return bson.M{"_id": objectID, "variants": bson.M{"$elemMatch": variant}}
+}
+
+// availableStockFilter matches the product, or its variant sku, when at least quantity units
+// are not held by reservations.
+func availableStockFilter(objectID primitive.ObjectID, sku string, quantity int) bson.M {
+	if sku == "" {
+		return bson.M{"_id": objectID, "$expr": availableStockAtLeast(quantity)}
+	}
+	return bson.M{"_id": objectID, "variants.sku": sku, "$expr": variantAvailableStockAtLeast(sku, quantity)}
+}
+
+// availableStockAtLeast matches products whose stock not held by reservations covers quantity.
+// Products created before reservations existed have no reserved field and count it as zero.
+func availableStockAtLeast(quantity int) bson.M {
+	return bson.M{"$gte": bson.A{
+		bson.M{"$subtract": bson.A{"$stock", bson.M{"$ifNull": bson.A{"$reserved", 0}}}},
+		quantity,
+	}}
+}
+
+// variantAvailableStockAtLeast is availableStockAtLeast for the variant sku.
+func variantAvailableStockAtLeast(sku string, quantity int) bson.M {
+	return bson.M{"$anyElementTrue": bson.A{bson.M{"$map": bson.M{
+		"input": "$variants",
+		"in": bson.M{"$and": bson.A{
+			bson.M{"$eq": bson.A{"$$this.sku", sku}},
+			bson.M{"$gte": bson.A{bson.M{"$subtract": bson.A{"$$this.stock", "$$this.reserved"}}, quantity}},
+		}},
+	}}}}
+}
+
+var productSortFields = map[string]string{
+	port.ProductSortName:      "name",
+	port.ProductSortPrice:     "price",
+	port.ProductSortCreatedAt: "created_at",
+}
+
+func (r *ProductRepository) List(ctx context.Context, filter port.ProductFilter, page port.PageRequest) (*port.Page[*domain.Product], error) {
+	sortField, ok := productSortFields[page.SortBy]
+	if !ok {
+		return nil, serviceerrors.NewInvalidRequestError("invalid sort field")
+	}
+
+	query, err := toProductQuery(filter)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	docs, err := r.FindPage(ctx, query, sortField, page)
 	if err != nil {
 		return nil, err
 	}
 
-	products := make([]*domain.Product, len(docs))
-	for i, doc := range docs {
+	products := make([]*domain.Product, len(docs.Items))
+	for i, doc := range docs.Items {
 		products[i] = doc.ToDomain()
 	}
 
-	return products, nil
+	return &port.Page[*domain.Product]{
+		Items:      products,
+		NextCursor: docs.NextCursor,
+		Total:      docs.Total,
+	}, nil
+}
+
+// productSearchResult is a product with its text search relevance, which search pages are keyed on.
+type productSearchResult struct {
+	document.ProductDocument `bson:",inline"`
+	Score                    float64 `bson:"score"`
+}
+
+func (r *ProductRepository) Search(ctx context.Context, query string, page port.PageRequest) (*port.Page[*domain.Product], error) {
+	if page.Limit <= 0 {
+		return nil, serviceerrors.NewInvalidRequestError("page limit must be positive")
+	}
+
+	match := bson.M{"$text": bson.M{"$search": query}, "archived_at": nil}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$addFields", Value: bson.M{"score": bson.M{"$meta": "textScore"}}}},
+	}
+	if page.Cursor != "" {
+		cursor, err := decodePageCursor(page.Cursor, "score")
+		if err != nil {
+			return nil, err
+		}
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: cursor.after(-1)}})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "score", Value: -1}, {Key: "_id", Value: -1}}}},
+		bson.D{{Key: "$limit", Value: page.Limit + 1}},
+	)
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []productSearchResult
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, parseError(err)
+	}
+
+	result := &port.Page[*domain.Product]{}
+	if int64(len(results)) > page.Limit {
+		results = results[:page.Limit]
+		next, err := newPageCursor(results[page.Limit-1], "score")
+		if err != nil {
+			return nil, err
+		}
+		if result.NextCursor, err = next.encode(); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Items = make([]*domain.Product, len(results))
+	for i := range results {
+		result.Items[i] = results[i].ToDomain()
+	}
+
+	if page.IncludeTotal {
+		total, err := r.collection.CountDocuments(ctx, match)
+		if err != nil {
+			return nil, parseError(err)
+		}
+		result.Total = &total
+	}
+
+	return result, nil
+}
+
+func toProductQuery(filter port.ProductFilter) (bson.M, error) {
+	query := bson.M{"archived_at": nil}
+	if filter.NamePrefix != "" {
+		query["name"] = bson.M{"$regex": "^" + regexp.QuoteMeta(filter.NamePrefix)}
+	}
+
+	price := bson.M{}
+	if filter.MinPrice != nil {
+		price["$gte"] = int64(*filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		price["$lte"] = int64(*filter.MaxPrice)
+	}
+	if len(price) > 0 {
+		query["price"] = price
+	}
+
+	if filter.InStockOnly {
+		// The stock bound narrows the scan before the reserved-aware expression runs.
+		query["stock"] = bson.M{"$gt": 0}
+		query["$expr"] = availableStockAtLeast(1)
+	}
+
+	if len(filter.CategoryIDs) > 0 {
+		categoryIDs := make([]primitive.ObjectID, len(filter.CategoryIDs))
+		for i, id := range filter.CategoryIDs {
+			objectID, err := primitive.ObjectIDFromHex(string(id))
+			if err != nil {
+				return nil, err
+			}
+			categoryIDs[i] = objectID
+		}
+		query["category_id"] = bson.M{"$in": categoryIDs}
+	}
+	if filter.Tag != "" {
+		query["tags"] = filter.Tag
+	}
+
+	return query, nil
 }
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/rafaelleal24/challenge/internal/adapters/mongo/document"
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/logger"
+	"github.com/rafaelleal24/challenge/internal/core/port"
+	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ProductImportRepository struct {
+	*BaseRepository[document.ProductImportDocument]
+	collection *mongo.Collection
+}
+
+func NewProductImportRepository(db *mongo.Database) port.ProductImportPort {
+	repo := &ProductImportRepository{
+		BaseRepository: NewBaseRepository[document.ProductImportDocument](db, "product_imports"),
+		collection:     db.Collection("product_imports"),
+	}
+
+	if err := repo.createIndexes(context.Background()); err != nil {
+		logger.Error(context.Background(), "failed to create indexes", err, map[string]any{
+			"collection": "product_imports",
+		})
+	}
+
+	return repo
+}
+
+func (r *ProductImportRepository) createIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "updated_at", Value: 1},
+			},
+			Options: options.Index().SetUnique(false),
+		},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+func (r *ProductImportRepository) Create(ctx context.Context, productImport *domain.ProductImport) error {
+	result, err := r.collection.InsertOne(ctx, document.ToProductImportDocument(productImport))
+	if err != nil {
+		return parseError(err)
+	}
+
+	productImport.ID = domain.ID(result.InsertedID.(primitive.ObjectID).Hex())
+	return nil
+}
+
+func (r *ProductImportRepository) GetByID(ctx context.Context, id domain.ID) (*domain.ProductImport, error) {
+	objectID, err := primitive.ObjectIDFromHex(string(id))
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	var doc document.ProductImportDocument
+	err = r.collection.FindOne(ctx,
+		bson.M{"_id": objectID},
+		options.FindOne().SetProjection(bson.M{"rows": 0}),
+	).Decode(&doc)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return doc.ToDomain(), nil
+}
+
+func (r *ProductImportRepository) ClaimNext(ctx context.Context, staleBefore time.Time) (*domain.ProductImport, error) {
+	now := time.Now()
+	var doc document.ProductImportDocument
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"$or": []bson.M{
+			{"status": string(domain.ProductImportStatusPending)},
+			{"status": string(domain.ProductImportStatusRunning), "updated_at": bson.M{"$lt": staleBefore}},
+		}},
+		mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{
+				"status":     string(domain.ProductImportStatusRunning),
+				"lease_id":   primitive.NewObjectID().Hex(),
+				"started_at": bson.M{"$ifNull": bson.A{"$started_at", now}},
+				"updated_at": now,
+			}}},
+		},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "created_at", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return doc.ToDomain(), nil
+}
+
+func (r *ProductImportRepository) SaveProgress(ctx context.Context, productImport *domain.ProductImport) error {
+	objectID, err := primitive.ObjectIDFromHex(string(productImport.ID))
+	if err != nil {
+		return parseError(err)
+	}
+
+	productImport.UpdatedAt = time.Now()
+	set := bson.M{
+		"status":      string(productImport.Status),
+		"processed":   productImport.Processed,
+		"created":     productImport.Created,
+		"updated":     productImport.Updated,
+		"failed":      productImport.Failed,
+		"errors":      document.ToProductImportErrorDocuments(productImport.Errors),
+		"finished_at": productImport.FinishedAt,
+		"updated_at":  productImport.UpdatedAt,
+	}
+	changes := bson.M{"$set": set}
+	if productImport.Status == domain.ProductImportStatusCompleted {
+		changes["$unset"] = bson.M{"rows": ""}
+	}
+
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": objectID, "status": string(domain.ProductImportStatusRunning), "lease_id": productImport.LeaseID},
+		changes,
+	)
+	if err != nil {
+		return parseError(err)
+	}
+	if result.MatchedCount == 0 {
+		return serviceerrors.NewConflictError("product import is not running under this lease")
+	}
+
+	return nil
+}
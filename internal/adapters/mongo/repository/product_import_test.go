@@ -0,0 +1,121 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rafaelleal24/challenge/internal/adapters/mongo/repository"
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
+)
+
+func TestProductImportRepository(t *testing.T) {
+	freshDB := testClient.Database("test_product_imports")
+	repo := repository.NewProductImportRepository(freshDB)
+	ctx := context.Background()
+
+	teePrice, capPrice := domain.Amount(1500), domain.Amount(900)
+	first := domain.NewProductImport(domain.ProductImportFormatCSV, []domain.ProductImportRow{
+		{Line: 2, SKU: "TEE-1", Name: "Tee", Price: &teePrice, Stock: 3, Tags: []string{"sale"}},
+		{Line: 3, SKU: "MUG-1", Name: "Mug"},
+	}, 3, []domain.ProductImportError{{Line: 4, Message: "name is required"}})
+	second := domain.NewProductImport(domain.ProductImportFormatNDJSON, []domain.ProductImportRow{
+		{Line: 1, SKU: "CAP-1", Name: "Cap", Price: &capPrice},
+	}, 1, nil)
+	second.CreatedAt = first.CreatedAt.Add(time.Second)
+	for _, productImport := range []*domain.ProductImport{first, second} {
+		if err := repo.Create(ctx, productImport); err != nil {
+			t.Fatalf("setup: create import failed: %v", err)
+		}
+	}
+
+	t.Run("gets an import without its rows", func(t *testing.T) {
+		found, err := repo.GetByID(ctx, first.ID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if found.Status != domain.ProductImportStatusPending || found.Total != 3 || found.Failed != 1 || len(found.Errors) != 1 {
+			t.Fatalf("unexpected import %+v", found)
+		}
+		if len(found.Rows) != 0 {
+			t.Fatalf("expected rows to be left out, got %d", len(found.Rows))
+		}
+	})
+
+	t.Run("claims the oldest pending import with its rows", func(t *testing.T) {
+		claimed, err := repo.ClaimNext(ctx, time.Now().Add(-time.Minute))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if claimed == nil || claimed.ID != first.ID {
+			t.Fatalf("expected the first import, got %+v", claimed)
+		}
+		if claimed.Status != domain.ProductImportStatusRunning || claimed.StartedAt == nil || claimed.LeaseID == "" {
+			t.Fatalf("expected a running import, got %+v", claimed)
+		}
+		if len(claimed.Rows) != 2 || claimed.Rows[0].Tags[0] != "sale" || *claimed.Rows[0].Price != teePrice || claimed.Rows[1].Price != nil {
+			t.Fatalf("unexpected rows %+v", claimed.Rows)
+		}
+
+		next, err := repo.ClaimNext(ctx, time.Now().Add(-time.Minute))
+		if err != nil || next == nil || next.ID != second.ID {
+			t.Fatalf("expected the second import, got %+v (err %v)", next, err)
+		}
+		none, err := repo.ClaimNext(ctx, time.Now().Add(-time.Minute))
+		if err != nil || none != nil {
+			t.Fatalf("expected nothing to claim, got %+v (err %v)", none, err)
+		}
+	})
+
+	t.Run("reclaims a stale running import", func(t *testing.T) {
+		claimed, err := repo.ClaimNext(ctx, time.Now().Add(time.Minute))
+		if err != nil || claimed == nil {
+			t.Fatalf("expected a stale import, got %+v (err %v)", claimed, err)
+		}
+	})
+
+	t.Run("saves progress and drops rows on completion", func(t *testing.T) {
+		superseded, err := repo.ClaimNext(ctx, time.Now().Add(time.Minute))
+		if err != nil || superseded == nil {
+			t.Fatalf("setup: claim failed: %+v (err %v)", superseded, err)
+		}
+		claimed, err := repo.ClaimNext(ctx, time.Now().Add(time.Minute))
+		if err != nil || claimed == nil || claimed.ID != superseded.ID || claimed.LeaseID == superseded.LeaseID {
+			t.Fatalf("setup: expected the import to be claimed again under a new lease, got %+v (err %v)", claimed, err)
+		}
+		superseded.Processed = 1
+		if err := repo.SaveProgress(ctx, superseded); !serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			t.Fatalf("expected KindConflict for a superseded lease, got %v", err)
+		}
+
+		finishedAt := time.Now()
+		claimed.Processed = len(claimed.Rows)
+		claimed.Created = len(claimed.Rows)
+		claimed.Status = domain.ProductImportStatusCompleted
+		claimed.FinishedAt = &finishedAt
+		if err := repo.SaveProgress(ctx, claimed); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		found, err := repo.GetByID(ctx, claimed.ID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if found.Status != domain.ProductImportStatusCompleted || found.Created != claimed.Created || found.FinishedAt == nil {
+			t.Fatalf("unexpected import %+v", found)
+		}
+
+		err = repo.SaveProgress(ctx, claimed)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			t.Fatalf("expected KindConflict once completed, got %v", err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := repo.GetByID(ctx, "aabbccddee112233aabbcc00")
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+}
@@ -2,13 +2,19 @@ package repository_test
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/rafaelleal24/challenge/internal/adapters/mongo/repository"
 	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/port"
 	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
 )
 
+func noStockAlert(*domain.Product, string) domain.Event { return nil }
+
 func createTestProduct(t *testing.T, repo interface {
 	Create(ctx context.Context, product *domain.Product) error
 }) *domain.Product {
@@ -21,7 +27,7 @@ func createTestProduct(t *testing.T, repo interface {
 }
 
 func TestProductRepository_Create(t *testing.T) {
-	repo := repository.NewProductRepository(testDB)
+	repo := repository.NewProductRepository(testDB, repository.NewOutboxRepository(testDB))
 	ctx := context.Background()
 
 	t.Run("creates product and assigns ID", func(t *testing.T) {
@@ -41,7 +47,7 @@ func TestProductRepository_Create(t *testing.T) {
 }
 
 func TestProductRepository_GetByID(t *testing.T) {
-	repo := repository.NewProductRepository(testDB)
+	repo := repository.NewProductRepository(testDB, repository.NewOutboxRepository(testDB))
 	ctx := context.Background()
 
 	t.Run("returns product by ID", func(t *testing.T) {
@@ -86,47 +92,522 @@ func TestProductRepository_GetByID(t *testing.T) {
 	})
 }
 
-func TestProductRepository_GetAll(t *testing.T) {
+func TestProductRepository_GetByIDs(t *testing.T) {
+	repo := repository.NewProductRepository(testDB, repository.NewOutboxRepository(testDB))
+	ctx := context.Background()
+
+	t.Run("returns only the products that exist", func(t *testing.T) {
+		first := createTestProduct(t, repo)
+		second := createTestProduct(t, repo)
+
+		found, err := repo.GetByIDs(ctx, []domain.ID{first.ID, "aabbccddee112233aabbccdd", second.ID})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(found) != 2 {
+			t.Fatalf("expected 2 products, got %d", len(found))
+		}
+		ids := map[domain.ID]bool{found[0].ID: true, found[1].ID: true}
+		if !ids[first.ID] || !ids[second.ID] {
+			t.Fatalf("expected products %s and %s, got %v", first.ID, second.ID, ids)
+		}
+	})
+
+	t.Run("returns empty slice when nothing matches", func(t *testing.T) {
+		found, err := repo.GetByIDs(ctx, []domain.ID{"aabbccddee112233aabbccdd"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(found) != 0 {
+			t.Fatalf("expected no products, got %d", len(found))
+		}
+	})
+
+	t.Run("returns error for invalid ID", func(t *testing.T) {
+		_, err := repo.GetByIDs(ctx, []domain.ID{"bad-id"})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+}
+
+func TestProductRepository_List(t *testing.T) {
 	// Use a fresh database to avoid pollution from other tests
-	freshDB := testClient.Database("test_product_getall")
-	repo := repository.NewProductRepository(freshDB)
+	freshDB := testClient.Database("test_product_list")
+	repo := repository.NewProductRepository(freshDB, repository.NewOutboxRepository(freshDB))
+	ctx := context.Background()
+	byName := port.PageRequest{Limit: 10, SortBy: port.ProductSortName}
+
+	t.Run("returns empty page when no products", func(t *testing.T) {
+		page, err := repo.List(ctx, port.ProductFilter{}, byName)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(page.Items) != 0 {
+			t.Fatalf("expected 0 products, got %d", len(page.Items))
+		}
+	})
+
+	widget := domain.NewProduct("Widget", "", domain.NewAmountFromCents(1000), 10)
+	gadget := domain.NewProduct("Gadget", "", domain.NewAmountFromCents(3000), 5)
+	widgetPro := domain.NewProduct("Widget Pro", "", domain.NewAmountFromCents(5000), 2)
+	archived := domain.NewProduct("Widget Old", "", domain.NewAmountFromCents(500), 10)
+	for _, p := range []*domain.Product{widget, gadget, widgetPro, archived} {
+		if err := repo.Create(ctx, p); err != nil {
+			t.Fatalf("setup: create product failed: %v", err)
+		}
+	}
+	_ = repo.ReserveStock(ctx, widgetPro.ID, "", 2)
+	_ = repo.Archive(ctx, archived.ID)
+
+	names := func(products []*domain.Product) []string {
+		result := make([]string, len(products))
+		for i, p := range products {
+			result[i] = p.Name
+		}
+		return result
+	}
+
+	t.Run("excludes archived products and sorts by name", func(t *testing.T) {
+		page, err := repo.List(ctx, port.ProductFilter{}, byName)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got := names(page.Items); !slices.Equal(got, []string{"Gadget", "Widget", "Widget Pro"}) {
+			t.Fatalf("unexpected products %v", got)
+		}
+	})
+
+	t.Run("filters by name prefix and price range", func(t *testing.T) {
+		minPrice, maxPrice := domain.NewAmountFromCents(2000), domain.NewAmountFromCents(6000)
+		page, err := repo.List(ctx, port.ProductFilter{NamePrefix: "Wid", MinPrice: &minPrice, MaxPrice: &maxPrice}, byName)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got := names(page.Items); !slices.Equal(got, []string{"Widget Pro"}) {
+			t.Fatalf("unexpected products %v", got)
+		}
+	})
+
+	t.Run("in stock only ignores reserved stock", func(t *testing.T) {
+		page, err := repo.List(ctx, port.ProductFilter{InStockOnly: true}, byName)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got := names(page.Items); !slices.Equal(got, []string{"Gadget", "Widget"}) {
+			t.Fatalf("unexpected products %v", got)
+		}
+	})
+
+	t.Run("pages by price descending with total", func(t *testing.T) {
+		request := port.PageRequest{Limit: 2, SortBy: port.ProductSortPrice, Descending: true, IncludeTotal: true}
+		first, err := repo.List(ctx, port.ProductFilter{}, request)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got := names(first.Items); !slices.Equal(got, []string{"Widget Pro", "Gadget"}) {
+			t.Fatalf("unexpected first page %v", got)
+		}
+		if first.Total == nil || *first.Total != 3 {
+			t.Fatalf("expected total 3, got %v", first.Total)
+		}
+
+		request.Cursor = first.NextCursor
+		second, err := repo.List(ctx, port.ProductFilter{}, request)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got := names(second.Items); !slices.Equal(got, []string{"Widget"}) || second.NextCursor != "" {
+			t.Fatalf("unexpected second page %v (next cursor %q)", got, second.NextCursor)
+		}
+	})
+
+	t.Run("rejects unknown sort field", func(t *testing.T) {
+		_, err := repo.List(ctx, port.ProductFilter{}, port.PageRequest{Limit: 10, SortBy: "stock"})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+}
+
+func TestProductRepository_CategoriesAndTags(t *testing.T) {
+	freshDB := testClient.Database("test_product_categories")
+	repo := repository.NewProductRepository(freshDB, repository.NewOutboxRepository(freshDB))
 	ctx := context.Background()
+	byName := port.PageRequest{Limit: 10, SortBy: port.ProductSortName}
+	shirts := domain.ID("aabbccddee112233aabb3001")
+	polos := domain.ID("aabbccddee112233aabb3002")
+
+	tee := domain.NewProduct("Tee", "", domain.NewAmountFromCents(1000), 10)
+	tee.CategoryID = shirts
+	tee.Tags = []string{"sale", "summer"}
+	polo := domain.NewProduct("Polo", "", domain.NewAmountFromCents(3000), 5)
+	polo.CategoryID = polos
+	polo.Tags = []string{"summer"}
+	mug := domain.NewProduct("Mug", "", domain.NewAmountFromCents(500), 5)
+	for _, p := range []*domain.Product{tee, polo, mug} {
+		if err := repo.Create(ctx, p); err != nil {
+			t.Fatalf("setup: create product failed: %v", err)
+		}
+	}
+
+	names := func(products []*domain.Product) []string {
+		result := make([]string, len(products))
+		for i, p := range products {
+			result[i] = p.Name
+		}
+		return result
+	}
+
+	t.Run("round-trips category and tags", func(t *testing.T) {
+		found, err := repo.GetByID(ctx, tee.ID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if found.CategoryID != shirts || !slices.Equal(found.Tags, []string{"sale", "summer"}) {
+			t.Fatalf("unexpected category %s and tags %v", found.CategoryID, found.Tags)
+		}
+	})
+
+	t.Run("filters by any of the categories", func(t *testing.T) {
+		page, err := repo.List(ctx, port.ProductFilter{CategoryIDs: []domain.ID{shirts, polos}}, byName)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got := names(page.Items); !slices.Equal(got, []string{"Polo", "Tee"}) {
+			t.Fatalf("unexpected products %v", got)
+		}
+	})
 
-	t.Run("returns empty list when no products", func(t *testing.T) {
-		products, err := repo.GetAll(ctx)
+	t.Run("filters by tag", func(t *testing.T) {
+		page, err := repo.List(ctx, port.ProductFilter{Tag: "sale"}, byName)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
-		if len(products) != 0 {
-			t.Fatalf("expected 0 products, got %d", len(products))
+		if got := names(page.Items); !slices.Equal(got, []string{"Tee"}) {
+			t.Fatalf("unexpected products %v", got)
 		}
 	})
 
-	t.Run("returns all created products", func(t *testing.T) {
-		p1 := domain.NewProduct("Product 1", "Desc 1", domain.NewAmountFromCents(1000), 10)
-		p2 := domain.NewProduct("Product 2", "Desc 2", domain.NewAmountFromCents(2000), 20)
-		_ = repo.Create(ctx, p1)
-		_ = repo.Create(ctx, p2)
+	t.Run("reports whether a category has products", func(t *testing.T) {
+		exists, err := repo.ExistsInCategory(ctx, polos)
+		if err != nil || !exists {
+			t.Fatalf("expected products in category, got %v (err %v)", exists, err)
+		}
+		exists, err = repo.ExistsInCategory(ctx, "aabbccddee112233aabb3003")
+		if err != nil || exists {
+			t.Fatalf("expected no products in category, got %v (err %v)", exists, err)
+		}
+	})
 
-		products, err := repo.GetAll(ctx)
+	t.Run("update clears category and tags", func(t *testing.T) {
+		var none domain.ID
+		tags := []string{}
+		updated, err := repo.Update(ctx, polo.ID, port.ProductUpdate{CategoryID: &none, Tags: &tags})
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
-		if len(products) != 2 {
-			t.Fatalf("expected 2 products, got %d", len(products))
+		if updated.CategoryID != "" || len(updated.Tags) != 0 {
+			t.Fatalf("expected category and tags to be cleared, got %s and %v", updated.CategoryID, updated.Tags)
+		}
+
+		exists, err := repo.ExistsInCategory(ctx, polos)
+		if err != nil || exists {
+			t.Fatalf("expected no products left in category, got %v (err %v)", exists, err)
+		}
+	})
+}
+
+func TestProductRepository_UpsertBySKU(t *testing.T) {
+	freshDB := testClient.Database("test_product_upsert")
+	repo := repository.NewProductRepository(freshDB, repository.NewOutboxRepository(freshDB))
+	ctx := context.Background()
+
+	threshold := 4
+	product := domain.NewProduct("Tee", "Cotton", domain.NewAmountFromCents(1500), 10)
+	product.SKU = "TEE-1"
+	product.LowStockThreshold = &threshold
+	product.Tags = []string{"sale"}
+
+	t.Run("creates a product for a new sku", func(t *testing.T) {
+		created, err := repo.UpsertBySKU(ctx, product)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !created {
+			t.Fatal("expected the product to be created")
+		}
+	})
+
+	var existing *domain.Product
+	if err := repo.Stream(ctx, func(p *domain.Product) error {
+		existing = p
+		return nil
+	}); err != nil || existing == nil {
+		t.Fatalf("setup: stream products failed: %v", err)
+	}
+
+	t.Run("updates the catalogue fields and keeps the stock", func(t *testing.T) {
+		update := domain.NewProduct("Tee v2", "Organic cotton", domain.NewAmountFromCents(1800), 99)
+		update.SKU = "TEE-1"
+
+		created, err := repo.UpsertBySKU(ctx, update)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if created {
+			t.Fatal("expected the existing product to be updated")
+		}
+
+		found, err := repo.GetByID(ctx, existing.ID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if found.Name != "Tee v2" || found.Price != domain.NewAmountFromCents(1800) || found.Stock != 10 {
+			t.Fatalf("unexpected product %+v", found)
+		}
+		if found.LowStockThreshold != nil || len(found.Tags) != 0 {
+			t.Fatalf("expected threshold and tags to be cleared, got %v and %v", found.LowStockThreshold, found.Tags)
+		}
+	})
+
+	t.Run("updates the variant that holds the sku", func(t *testing.T) {
+		hoodie := domain.NewProduct("Hoodie", "", domain.NewAmountFromCents(5000), 0)
+		hoodie.Variants = []domain.ProductVariant{
+			domain.NewProductVariant("HOODIE-S", "Small", nil, 2),
+			domain.NewProductVariant("HOODIE-M", "Medium", nil, 3),
+		}
+		if err := repo.Create(ctx, hoodie); err != nil {
+			t.Fatalf("setup: create product failed: %v", err)
+		}
+
+		update := domain.NewProduct("Medium (slim)", "Fleece", domain.NewAmountFromCents(5500), 99)
+		update.SKU = "HOODIE-M"
+		created, err := repo.UpsertBySKU(ctx, update)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if created {
+			t.Fatal("expected the variant to be updated, not a product created")
+		}
+
+		found, err := repo.GetByID(ctx, hoodie.ID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		variant := found.Variant("HOODIE-M")
+		if found.Name != "Hoodie" || found.Description != "Fleece" {
+			t.Fatalf("unexpected product %+v", found)
+		}
+		if variant.Name != "Medium (slim)" || variant.Price == nil || *variant.Price != domain.NewAmountFromCents(5500) || variant.Stock != 3 {
+			t.Fatalf("unexpected variant %+v", variant)
+		}
+		if small := found.Variant("HOODIE-S"); small.Name != "Small" || small.Price != nil {
+			t.Fatalf("expected the other variant untouched, got %+v", small)
+		}
+
+		inherit := domain.NewProduct("Medium (slim)", "Fleece", 0, 0)
+		inherit.SKU = "HOODIE-M"
+		if _, err := repo.UpsertBySKU(ctx, inherit); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		found, err = repo.GetByID(ctx, hoodie.ID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if variant := found.Variant("HOODIE-M"); variant.Price != nil || found.PriceOf("HOODIE-M") != domain.NewAmountFromCents(5000) {
+			t.Fatalf("expected the variant to sell at the product's price again, got %+v", variant)
+		}
+		if err := repo.Archive(ctx, hoodie.ID); err != nil {
+			t.Fatalf("cleanup: archive failed: %v", err)
+		}
+	})
+
+	t.Run("requires a price for a new sku", func(t *testing.T) {
+		unpriced := domain.NewProduct("Cap", "", 0, 1)
+		unpriced.SKU = "CAP-1"
+
+		_, err := repo.UpsertBySKU(ctx, unpriced)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindUnprocessableEntity) {
+			t.Fatalf("expected KindUnprocessableEntity, got %v", err)
+		}
+	})
+
+	t.Run("does not revive an archived product", func(t *testing.T) {
+		if err := repo.Archive(ctx, existing.ID); err != nil {
+			t.Fatalf("setup: archive failed: %v", err)
+		}
+
+		_, err := repo.UpsertBySKU(ctx, product)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			t.Fatalf("expected KindConflict, got %v", err)
+		}
+	})
+
+	t.Run("stream skips archived products", func(t *testing.T) {
+		other := domain.NewProduct("Mug", "", domain.NewAmountFromCents(800), 3)
+		if err := repo.Create(ctx, other); err != nil {
+			t.Fatalf("setup: create product failed: %v", err)
+		}
+
+		var streamed []string
+		if err := repo.Stream(ctx, func(p *domain.Product) error {
+			streamed = append(streamed, p.Name)
+			return nil
+		}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !slices.Equal(streamed, []string{"Mug"}) {
+			t.Fatalf("unexpected products %v", streamed)
+		}
+	})
+}
+
+func TestProductRepository_Search(t *testing.T) {
+	freshDB := testClient.Database("test_product_search")
+	repo := repository.NewProductRepository(freshDB, repository.NewOutboxRepository(freshDB))
+	ctx := context.Background()
+
+	inName := domain.NewProduct("Blue Widget", "A fine gadget", domain.NewAmountFromCents(1000), 10)
+	inDescription := domain.NewProduct("Gadget", "Works with any widget", domain.NewAmountFromCents(2000), 10)
+	unrelated := domain.NewProduct("Sprocket", "Metal part", domain.NewAmountFromCents(300), 10)
+	archived := domain.NewProduct("Old Widget", "Discontinued", domain.NewAmountFromCents(500), 10)
+	for _, p := range []*domain.Product{inName, inDescription, unrelated, archived} {
+		if err := repo.Create(ctx, p); err != nil {
+			t.Fatalf("setup: create product failed: %v", err)
+		}
+	}
+	_ = repo.Archive(ctx, archived.ID)
+
+	t.Run("ranks name matches above description matches", func(t *testing.T) {
+		page, err := repo.Search(ctx, "widget", port.PageRequest{Limit: 10, IncludeTotal: true})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(page.Items) != 2 || page.Items[0].ID != inName.ID || page.Items[1].ID != inDescription.ID {
+			t.Fatalf("unexpected results %+v", page.Items)
+		}
+		if page.Total == nil || *page.Total != 2 {
+			t.Fatalf("expected total 2, got %v", page.Total)
+		}
+	})
+
+	t.Run("pages with a cursor", func(t *testing.T) {
+		first, err := repo.Search(ctx, "widget", port.PageRequest{Limit: 1})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(first.Items) != 1 || first.NextCursor == "" {
+			t.Fatalf("expected one result and a next cursor, got %d and %q", len(first.Items), first.NextCursor)
+		}
+
+		second, err := repo.Search(ctx, "widget", port.PageRequest{Limit: 1, Cursor: first.NextCursor})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(second.Items) != 1 || second.Items[0].ID != inDescription.ID || second.NextCursor != "" {
+			t.Fatalf("unexpected second page %+v (next cursor %q)", second.Items, second.NextCursor)
+		}
+	})
+
+	t.Run("rejects a listing cursor", func(t *testing.T) {
+		listing, _ := repo.List(ctx, port.ProductFilter{}, port.PageRequest{Limit: 1, SortBy: port.ProductSortName})
+
+		_, err := repo.Search(ctx, "widget", port.PageRequest{Limit: 1, Cursor: listing.NextCursor})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+}
+
+func TestProductRepository_Update(t *testing.T) {
+	repo := repository.NewProductRepository(testDB, repository.NewOutboxRepository(testDB))
+	ctx := context.Background()
+
+	t.Run("updates only provided fields and bumps updated_at", func(t *testing.T) {
+		created := createTestProduct(t, repo)
+		name := "Renamed"
+		price := domain.NewAmountFromCents(1234)
+		// Mongo stores milliseconds; make sure the update lands on a later one.
+		time.Sleep(5 * time.Millisecond)
+
+		updated, err := repo.Update(ctx, created.ID, port.ProductUpdate{Name: &name, Price: &price})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if updated.Name != name || updated.Price != price {
+			t.Fatalf("expected name %q and price %d, got %q and %d", name, price, updated.Name, updated.Price)
+		}
+		if updated.Description != created.Description {
+			t.Fatalf("expected description %q to be kept, got %q", created.Description, updated.Description)
+		}
+		if !updated.UpdatedAt.After(created.UpdatedAt) {
+			t.Fatal("expected updated_at to be bumped")
+		}
+	})
+
+	t.Run("rejects archived product", func(t *testing.T) {
+		created := createTestProduct(t, repo)
+		_ = repo.Archive(ctx, created.ID)
+		name := "Renamed"
+
+		_, err := repo.Update(ctx, created.ID, port.ProductUpdate{Name: &name})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindUnprocessableEntity) {
+			t.Fatalf("expected KindUnprocessableEntity, got %v", err)
+		}
+	})
+
+	t.Run("returns not found for non-existing product", func(t *testing.T) {
+		name := "Renamed"
+
+		_, err := repo.Update(ctx, "aabbccddee112233aabb0000", port.ProductUpdate{Name: &name})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+}
+
+func TestProductRepository_Archive(t *testing.T) {
+	repo := repository.NewProductRepository(testDB, repository.NewOutboxRepository(testDB))
+	ctx := context.Background()
+
+	t.Run("archives product and is idempotent", func(t *testing.T) {
+		created := createTestProduct(t, repo)
+
+		if err := repo.Archive(ctx, created.ID); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		archived, _ := repo.GetByID(ctx, created.ID)
+		if !archived.IsArchived() {
+			t.Fatal("expected product to be archived")
+		}
+
+		if err := repo.Archive(ctx, created.ID); err != nil {
+			t.Fatalf("expected archiving twice to succeed, got %v", err)
+		}
+		again, _ := repo.GetByID(ctx, created.ID)
+		if !again.ArchivedAt.Equal(*archived.ArchivedAt) {
+			t.Fatal("expected archived_at to be kept")
+		}
+	})
+
+	t.Run("returns not found for non-existing product", func(t *testing.T) {
+		err := repo.Archive(ctx, "aabbccddee112233aabb0000")
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
 		}
 	})
 }
 
 func TestProductRepository_DeductStock(t *testing.T) {
-	repo := repository.NewProductRepository(testDB)
+	repo := repository.NewProductRepository(testDB, repository.NewOutboxRepository(testDB))
 	ctx := context.Background()
 
 	t.Run("deducts stock successfully", func(t *testing.T) {
 		product := domain.NewProduct("Deduct Test", "", domain.NewAmountFromCents(500), 10)
 		_ = repo.Create(ctx, product)
 
-		err := repo.DeductStock(ctx, product.ID, 3)
+		err := repo.DeductStock(ctx, product.ID, "", 3, noStockAlert)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
@@ -141,7 +622,7 @@ func TestProductRepository_DeductStock(t *testing.T) {
 		product := domain.NewProduct("Low Stock", "", domain.NewAmountFromCents(500), 2)
 		_ = repo.Create(ctx, product)
 
-		err := repo.DeductStock(ctx, product.ID, 5)
+		err := repo.DeductStock(ctx, product.ID, "", 5, noStockAlert)
 		if err == nil {
 			t.Fatal("expected error, got nil")
 		}
@@ -160,7 +641,7 @@ func TestProductRepository_DeductStock(t *testing.T) {
 		product := domain.NewProduct("Exact Zero", "", domain.NewAmountFromCents(500), 5)
 		_ = repo.Create(ctx, product)
 
-		err := repo.DeductStock(ctx, product.ID, 5)
+		err := repo.DeductStock(ctx, product.ID, "", 5, noStockAlert)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
@@ -172,7 +653,7 @@ func TestProductRepository_DeductStock(t *testing.T) {
 	})
 
 	t.Run("fails for non-existing product", func(t *testing.T) {
-		err := repo.DeductStock(ctx, "aabbccddee112233aabbccdd", 1)
+		err := repo.DeductStock(ctx, "aabbccddee112233aabbccdd", "", 1, noStockAlert)
 		if err == nil {
 			t.Fatal("expected error, got nil")
 		}
@@ -182,7 +663,89 @@ func TestProductRepository_DeductStock(t *testing.T) {
 	})
 
 	t.Run("fails for invalid ID", func(t *testing.T) {
-		err := repo.DeductStock(ctx, "bad-id", 1)
+		err := repo.DeductStock(ctx, "bad-id", "", 1, noStockAlert)
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+}
+
+func TestProductRepository_DeductStock_WritesAlertToOutbox(t *testing.T) {
+	freshDB := testClient.Database("test_product_stock_alert")
+	outboxRepo := repository.NewOutboxRepository(freshDB)
+	repo := repository.NewProductRepository(freshDB, outboxRepo)
+	ctx := context.Background()
+
+	product := domain.NewProduct("Alert Test", "", domain.NewAmountFromCents(500), 5)
+	_ = repo.Create(ctx, product)
+
+	var seen *domain.Product
+	alert := func(p *domain.Product, sku string) domain.Event {
+		seen = p
+		return domain.NewStockAlertEvent(p, sku, p.AvailableStockOf(sku)+5, 2)
+	}
+	if err := repo.DeductStock(ctx, product.ID, "", 5, alert); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if seen == nil || seen.Stock != 0 {
+		t.Fatalf("expected alert to see the product after the deduction, got %+v", seen)
+	}
+
+	entries, err := outboxRepo.FetchPending(ctx, 10)
+	if err != nil {
+		t.Fatalf("fetch outbox: %v", err)
+	}
+	if len(entries) != 1 || entries[0].EventName != "product.out_of_stock" || entries[0].EntityName != "product" {
+		t.Fatalf("expected one product.out_of_stock entry, got %+v", entries)
+	}
+
+	t.Run("failed deduction writes nothing", func(t *testing.T) {
+		_ = outboxRepo.Delete(ctx, entries[0].ID)
+		err := repo.DeductStock(ctx, product.ID, "", 1, alert)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindUnprocessableEntity) {
+			t.Fatalf("expected KindUnprocessableEntity, got %v", err)
+		}
+		entries, _ := outboxRepo.FetchPending(ctx, 10)
+		if len(entries) != 0 {
+			t.Fatalf("expected no outbox entries, got %d", len(entries))
+		}
+	})
+}
+
+func TestProductRepository_RestoreStock(t *testing.T) {
+	repo := repository.NewProductRepository(testDB, repository.NewOutboxRepository(testDB))
+	ctx := context.Background()
+
+	t.Run("restores stock successfully", func(t *testing.T) {
+		product := domain.NewProduct("Restore Test", "", domain.NewAmountFromCents(500), 4)
+		_ = repo.Create(ctx, product)
+
+		err := repo.RestoreStock(ctx, product.ID, "", 3)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		updated, _ := repo.GetByID(ctx, product.ID)
+		if updated.Stock != 7 {
+			t.Fatalf("expected stock 7, got %d", updated.Stock)
+		}
+	})
+
+	t.Run("fails for non-existing product", func(t *testing.T) {
+		err := repo.RestoreStock(ctx, "aabbccddee112233aabb0000", "", 1)
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+
+	t.Run("fails for invalid ID", func(t *testing.T) {
+		err := repo.RestoreStock(ctx, "bad-id", "", 1)
 		if err == nil {
 			t.Fatal("expected error, got nil")
 		}
@@ -191,3 +754,390 @@ func TestProductRepository_DeductStock(t *testing.T) {
 		}
 	})
 }
+
+func TestProductRepository_ReserveStock(t *testing.T) {
+	repo := repository.NewProductRepository(testDB, repository.NewOutboxRepository(testDB))
+	ctx := context.Background()
+
+	t.Run("reserves stock without deducting it", func(t *testing.T) {
+		product := domain.NewProduct("Reserve Test", "", domain.NewAmountFromCents(500), 10)
+		_ = repo.Create(ctx, product)
+
+		if err := repo.ReserveStock(ctx, product.ID, "", 4); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		updated, _ := repo.GetByID(ctx, product.ID)
+		if updated.Stock != 10 || updated.Reserved != 4 {
+			t.Fatalf("expected stock 10 with 4 reserved, got %d with %d reserved", updated.Stock, updated.Reserved)
+		}
+	})
+
+	t.Run("fails when reservations leave too little available", func(t *testing.T) {
+		product := domain.NewProduct("Reserve Limit", "", domain.NewAmountFromCents(500), 5)
+		_ = repo.Create(ctx, product)
+		_ = repo.ReserveStock(ctx, product.ID, "", 4)
+
+		err := repo.ReserveStock(ctx, product.ID, "", 2)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindUnprocessableEntity) {
+			t.Fatalf("expected KindUnprocessableEntity, got %v", err)
+		}
+
+		err = repo.DeductStock(ctx, product.ID, "", 2, noStockAlert)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindUnprocessableEntity) {
+			t.Fatalf("expected deduction to respect reserved stock, got %v", err)
+		}
+	})
+}
+
+func TestProductRepository_ReserveStockBatch(t *testing.T) {
+	repo := repository.NewProductRepository(testDB, repository.NewOutboxRepository(testDB))
+	ctx := context.Background()
+
+	t.Run("reserves every line and merges repeated products", func(t *testing.T) {
+		first := domain.NewProduct("Batch Reserve A", "", domain.NewAmountFromCents(500), 10)
+		second := domain.NewProduct("Batch Reserve B", "", domain.NewAmountFromCents(500), 3)
+		_ = repo.Create(ctx, first)
+		_ = repo.Create(ctx, second)
+
+		err := repo.ReserveStockBatch(ctx, []port.StockLine{
+			{ProductID: first.ID, Quantity: 2},
+			{ProductID: second.ID, Quantity: 3},
+			{ProductID: first.ID, Quantity: 4},
+		}, noStockAlert)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		updated, _ := repo.GetByID(ctx, first.ID)
+		if updated.Reserved != 6 {
+			t.Fatalf("expected 6 reserved, got %d", updated.Reserved)
+		}
+		updated, _ = repo.GetByID(ctx, second.ID)
+		if updated.Reserved != 3 {
+			t.Fatalf("expected 3 reserved, got %d", updated.Reserved)
+		}
+	})
+
+	t.Run("reports every short product and reserves nothing", func(t *testing.T) {
+		enough := domain.NewProduct("Batch Enough", "", domain.NewAmountFromCents(500), 10)
+		short := domain.NewProduct("Batch Short", "", domain.NewAmountFromCents(500), 5)
+		empty := domain.NewProduct("Batch Empty", "", domain.NewAmountFromCents(500), 0)
+		_ = repo.Create(ctx, enough)
+		_ = repo.Create(ctx, short)
+		_ = repo.Create(ctx, empty)
+		_ = repo.ReserveStock(ctx, short.ID, "", 4)
+
+		err := repo.ReserveStockBatch(ctx, []port.StockLine{
+			{ProductID: enough.ID, Quantity: 1},
+			{ProductID: short.ID, Quantity: 2},
+			{ProductID: empty.ID, Quantity: 1},
+		}, noStockAlert)
+		var serviceErr *serviceerrors.ServiceError
+		if !errors.As(err, &serviceErr) || serviceErr.Kind != serviceerrors.KindUnprocessableEntity {
+			t.Fatalf("expected KindUnprocessableEntity, got %v", err)
+		}
+		details, _ := serviceErr.Details.(map[string]any)
+		shortages, _ := details["insufficient_stock"].([]domain.StockShortage)
+		expected := []domain.StockShortage{
+			{ProductID: short.ID, Requested: 2, Available: 1},
+			{ProductID: empty.ID, Requested: 1, Available: 0},
+		}
+		if len(shortages) != len(expected) || shortages[0] != expected[0] || shortages[1] != expected[1] {
+			t.Fatalf("expected shortages %+v, got %+v", expected, serviceErr.Details)
+		}
+
+		updated, _ := repo.GetByID(ctx, enough.ID)
+		if updated.Reserved != 0 {
+			t.Fatalf("expected nothing reserved, got %d", updated.Reserved)
+		}
+	})
+
+	t.Run("fails for non-existing product", func(t *testing.T) {
+		err := repo.ReserveStockBatch(ctx, []port.StockLine{{ProductID: "aabbccddee112233aabb0000", Quantity: 1}}, noStockAlert)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+
+	t.Run("fails for invalid ID", func(t *testing.T) {
+		err := repo.ReserveStockBatch(ctx, []port.StockLine{{ProductID: "bad-id", Quantity: 1}}, noStockAlert)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+}
+
+func TestProductRepository_ReserveStockBatch_WritesAlertToOutbox(t *testing.T) {
+	freshDB := testClient.Database("test_product_reserve_alert")
+	outboxRepo := repository.NewOutboxRepository(freshDB)
+	repo := repository.NewProductRepository(freshDB, outboxRepo)
+	ctx := context.Background()
+
+	product := domain.NewProduct("Reserve Alert", "", domain.NewAmountFromCents(500), 5)
+	_ = repo.Create(ctx, product)
+
+	var seen *domain.Product
+	alert := func(p *domain.Product, sku string) domain.Event {
+		seen = p
+		return domain.NewStockAlertEvent(p, sku, p.AvailableStockOf(sku)+4, 2)
+	}
+	if err := repo.ReserveStockBatch(ctx, []port.StockLine{{ProductID: product.ID, Quantity: 4}}, alert); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if seen == nil || seen.Stock != 5 || seen.Reserved != 4 {
+		t.Fatalf("expected alert to see the product after the reservation, got %+v", seen)
+	}
+
+	entries, err := outboxRepo.FetchPending(ctx, 10)
+	if err != nil {
+		t.Fatalf("fetch outbox: %v", err)
+	}
+	if len(entries) != 1 || entries[0].EventName != "product.low_stock" {
+		t.Fatalf("expected one product.low_stock entry, got %+v", entries)
+	}
+}
+
+func TestProductRepository_DeductStockBatch(t *testing.T) {
+	freshDB := testClient.Database("test_product_deduct_batch")
+	outboxRepo := repository.NewOutboxRepository(freshDB)
+	repo := repository.NewProductRepository(freshDB, outboxRepo)
+	ctx := context.Background()
+
+	first := domain.NewProduct("Batch Deduct A", "", domain.NewAmountFromCents(500), 5)
+	second := domain.NewProduct("Batch Deduct B", "", domain.NewAmountFromCents(500), 10)
+	_ = repo.Create(ctx, first)
+	_ = repo.Create(ctx, second)
+	quantities := map[domain.ID]int{first.ID: 5, second.ID: 1}
+	alert := func(p *domain.Product, sku string) domain.Event {
+		return domain.NewStockAlertEvent(p, sku, p.AvailableStockOf(sku)+quantities[p.ID], 2)
+	}
+
+	err := repo.DeductStockBatch(ctx, []port.StockLine{
+		{ProductID: first.ID, Quantity: 5},
+		{ProductID: second.ID, Quantity: 1},
+	}, alert)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	updated, _ := repo.GetByID(ctx, first.ID)
+	if updated.Stock != 0 {
+		t.Fatalf("expected stock 0, got %d", updated.Stock)
+	}
+	updated, _ = repo.GetByID(ctx, second.ID)
+	if updated.Stock != 9 {
+		t.Fatalf("expected stock 9, got %d", updated.Stock)
+	}
+
+	entries, err := outboxRepo.FetchPending(ctx, 10)
+	if err != nil {
+		t.Fatalf("fetch outbox: %v", err)
+	}
+	if len(entries) != 1 || entries[0].EventName != "product.out_of_stock" {
+		t.Fatalf("expected one product.out_of_stock entry, got %+v", entries)
+	}
+
+	t.Run("short batch deducts and writes nothing", func(t *testing.T) {
+		_ = outboxRepo.Delete(ctx, entries[0].ID)
+		err := repo.DeductStockBatch(ctx, []port.StockLine{
+			{ProductID: second.ID, Quantity: 1},
+			{ProductID: first.ID, Quantity: 1},
+		}, alert)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindUnprocessableEntity) {
+			t.Fatalf("expected KindUnprocessableEntity, got %v", err)
+		}
+
+		updated, _ := repo.GetByID(ctx, second.ID)
+		if updated.Stock != 9 {
+			t.Fatalf("expected stock to stay 9, got %d", updated.Stock)
+		}
+		entries, _ := outboxRepo.FetchPending(ctx, 10)
+		if len(entries) != 0 {
+			t.Fatalf("expected no outbox entries, got %d", len(entries))
+		}
+	})
+}
+
+func TestProductRepository_Variants(t *testing.T) {
+	repo := repository.NewProductRepository(testDB, repository.NewOutboxRepository(testDB))
+	ctx := context.Background()
+
+	newTee := func(t *testing.T, skuPrefix string) *domain.Product {
+		t.Helper()
+		largePrice := domain.NewAmountFromCents(3499)
+		product := domain.NewProduct("Tee", "", domain.NewAmountFromCents(2999), 10)
+		product.Variants = []domain.ProductVariant{
+			domain.NewProductVariant(skuPrefix+"-M", "Medium", nil, 4),
+			domain.NewProductVariant(skuPrefix+"-L", "Large", &largePrice, 6),
+		}
+		if err := repo.Create(ctx, product); err != nil {
+			t.Fatalf("setup: create product failed: %v", err)
+		}
+		return product
+	}
+
+	t.Run("stores variants with their price overrides", func(t *testing.T) {
+		product := newTee(t, "STORE")
+
+		found, err := repo.GetByID(ctx, product.ID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(found.Variants) != 2 || found.PriceOf("STORE-M") != 2999 || found.PriceOf("STORE-L") != 3499 {
+			t.Fatalf("unexpected variants %+v", found.Variants)
+		}
+	})
+
+	t.Run("rejects a sku used by another product", func(t *testing.T) {
+		newTee(t, "DUP")
+
+		other := domain.NewProduct("Other", "", domain.NewAmountFromCents(100), 1)
+		other.Variants = []domain.ProductVariant{domain.NewProductVariant("DUP-M", "Medium", nil, 1)}
+		err := repo.Create(ctx, other)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			t.Fatalf("expected KindConflict, got %v", err)
+		}
+	})
+
+	t.Run("products without variants do not clash on sku", func(t *testing.T) {
+		createTestProduct(t, repo)
+		createTestProduct(t, repo)
+	})
+
+	t.Run("changes variant stock and keeps the product totals", func(t *testing.T) {
+		product := newTee(t, "STOCK")
+
+		if err := repo.ReserveStock(ctx, product.ID, "STOCK-L", 5); err != nil {
+			t.Fatalf("reserve: %v", err)
+		}
+		if err := repo.CommitReservedStock(ctx, product.ID, "STOCK-L", 2); err != nil {
+			t.Fatalf("commit: %v", err)
+		}
+		if err := repo.DeductStock(ctx, product.ID, "STOCK-M", 1, noStockAlert); err != nil {
+			t.Fatalf("deduct: %v", err)
+		}
+
+		found, _ := repo.GetByID(ctx, product.ID)
+		large := found.Variant("STOCK-L")
+		if large.Stock != 4 || large.Reserved != 3 {
+			t.Fatalf("expected STOCK-L stock 4 with 3 reserved, got %d with %d", large.Stock, large.Reserved)
+		}
+		if found.Variant("STOCK-M").Stock != 3 {
+			t.Fatalf("expected STOCK-M stock 3, got %d", found.Variant("STOCK-M").Stock)
+		}
+		if found.Stock != 7 || found.Reserved != 3 {
+			t.Fatalf("expected product stock 7 with 3 reserved, got %d with %d", found.Stock, found.Reserved)
+		}
+	})
+
+	t.Run("alerts on the variant that runs out", func(t *testing.T) {
+		product := newTee(t, "ALERT")
+		var events []domain.Event
+		alert := func(p *domain.Product, sku string) domain.Event {
+			event := domain.NewStockAlertEvent(p, sku, p.AvailableStockOf(sku)+4, 2)
+			events = append(events, event)
+			return event
+		}
+
+		err := repo.DeductStockBatch(ctx, []port.StockLine{{ProductID: product.ID, SKU: "ALERT-M", Quantity: 4}}, alert)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(events) != 1 {
+			t.Fatalf("expected one alert, got %+v", events)
+		}
+		outOfStock, ok := events[0].(*domain.ProductOutOfStockEvent)
+		if !ok || outOfStock.SKU != "ALERT-M" || outOfStock.Stock != 0 {
+			t.Fatalf("expected ALERT-M to be out of stock while ALERT-L still has some, got %+v", events[0])
+		}
+	})
+
+	t.Run("checks availability per variant", func(t *testing.T) {
+		product := newTee(t, "AVAIL")
+
+		err := repo.ReserveStock(ctx, product.ID, "AVAIL-M", 5)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindUnprocessableEntity) {
+			t.Fatalf("expected KindUnprocessableEntity, got %v", err)
+		}
+
+		err = repo.ReserveStockBatch(ctx, []port.StockLine{
+			{ProductID: product.ID, SKU: "AVAIL-L", Quantity: 6},
+			{ProductID: product.ID, SKU: "AVAIL-M", Quantity: 5},
+		}, noStockAlert)
+		var serviceErr *serviceerrors.ServiceError
+		if !errors.As(err, &serviceErr) || serviceErr.Kind != serviceerrors.KindUnprocessableEntity {
+			t.Fatalf("expected KindUnprocessableEntity, got %v", err)
+		}
+		details, _ := serviceErr.Details.(map[string]any)
+		shortages, _ := details["insufficient_stock"].([]domain.StockShortage)
+		if len(shortages) != 1 || shortages[0].SKU != "AVAIL-M" || shortages[0].Available != 4 {
+			t.Fatalf("expected a single AVAIL-M shortage, got %+v", serviceErr.Details)
+		}
+
+		err = repo.ReserveStockBatch(ctx, []port.StockLine{{ProductID: product.ID, SKU: "AVAIL-XL", Quantity: 1}}, noStockAlert)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound for an unknown sku, got %v", err)
+		}
+	})
+}
+
+func TestProductRepository_ReleaseReservedStock(t *testing.T) {
+	repo := repository.NewProductRepository(testDB, repository.NewOutboxRepository(testDB))
+	ctx := context.Background()
+
+	t.Run("releases reserved stock", func(t *testing.T) {
+		product := domain.NewProduct("Release Test", "", domain.NewAmountFromCents(500), 10)
+		_ = repo.Create(ctx, product)
+		_ = repo.ReserveStock(ctx, product.ID, "", 4)
+
+		if err := repo.ReleaseReservedStock(ctx, product.ID, "", 3); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		updated, _ := repo.GetByID(ctx, product.ID)
+		if updated.Stock != 10 || updated.Reserved != 1 {
+			t.Fatalf("expected stock 10 with 1 reserved, got %d with %d reserved", updated.Stock, updated.Reserved)
+		}
+	})
+
+	t.Run("fails when less is reserved", func(t *testing.T) {
+		product := domain.NewProduct("Release Conflict", "", domain.NewAmountFromCents(500), 10)
+		_ = repo.Create(ctx, product)
+
+		err := repo.ReleaseReservedStock(ctx, product.ID, "", 1)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			t.Fatalf("expected KindConflict, got %v", err)
+		}
+	})
+}
+
+func TestProductRepository_CommitReservedStock(t *testing.T) {
+	repo := repository.NewProductRepository(testDB, repository.NewOutboxRepository(testDB))
+	ctx := context.Background()
+
+	t.Run("deducts committed reservation from stock", func(t *testing.T) {
+		product := domain.NewProduct("Commit Test", "", domain.NewAmountFromCents(500), 10)
+		_ = repo.Create(ctx, product)
+		_ = repo.ReserveStock(ctx, product.ID, "", 4)
+
+		if err := repo.CommitReservedStock(ctx, product.ID, "", 4); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		updated, _ := repo.GetByID(ctx, product.ID)
+		if updated.Stock != 6 || updated.Reserved != 0 {
+			t.Fatalf("expected stock 6 with nothing reserved, got %d with %d reserved", updated.Stock, updated.Reserved)
+		}
+	})
+
+	t.Run("fails when nothing is reserved", func(t *testing.T) {
+		product := domain.NewProduct("Commit Conflict", "", domain.NewAmountFromCents(500), 10)
+		_ = repo.Create(ctx, product)
+
+		err := repo.CommitReservedStock(ctx, product.ID, "", 1)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			t.Fatalf("expected KindConflict, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/rafaelleal24/challenge/internal/adapters/mongo/document"
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/logger"
+	"github.com/rafaelleal24/challenge/internal/core/port"
+	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ReservationRepository struct {
+	*BaseRepository[document.ReservationDocument]
+	collection *mongo.Collection
+}
+
+func NewReservationRepository(db *mongo.Database) port.ReservationPort {
+	repo := &ReservationRepository{
+		BaseRepository: NewBaseRepository[document.ReservationDocument](db, "reservations"),
+		collection:     db.Collection("reservations"),
+	}
+
+	if err := repo.createIndexes(context.Background()); err != nil {
+		logger.Error(context.Background(), "failed to create indexes", err, map[string]any{
+			"collection": "reservations",
+		})
+	}
+
+	return repo
+}
+
+func (r *ReservationRepository) createIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "order_id", Value: 1}},
+			Options: options.Index().SetUnique(false),
+		},
+		{
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "expires_at", Value: 1},
+			},
+			Options: options.Index().SetUnique(false),
+		},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+func (r *ReservationRepository) Create(ctx context.Context, reservations []*domain.Reservation) error {
+	if len(reservations) == 0 {
+		return nil
+	}
+
+	docs := make([]any, len(reservations))
+	for i, reservation := range reservations {
+		doc, err := document.ToReservationDocument(reservation)
+		if err != nil {
+			return parseError(err)
+		}
+		docs[i] = doc
+	}
+
+	result, err := r.collection.InsertMany(ctx, docs)
+	if err != nil {
+		return parseError(err)
+	}
+
+	for i, id := range result.InsertedIDs {
+		reservations[i].ID = domain.ID(id.(primitive.ObjectID).Hex())
+	}
+
+	return nil
+}
+
+func (r *ReservationRepository) GetByOrderID(ctx context.Context, orderID domain.ID) ([]*domain.Reservation, error) {
+	objectID, err := primitive.ObjectIDFromHex(string(orderID))
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	docs, err := r.Find(ctx, bson.M{"order_id": objectID})
+	if err != nil {
+		return nil, err
+	}
+
+	return toReservations(docs), nil
+}
+
+func (r *ReservationRepository) GetExpired(ctx context.Context, now time.Time, limit int64) ([]*domain.Reservation, error) {
+	opts := options.Find().
+		SetLimit(limit).
+		SetSort(bson.D{{Key: "expires_at", Value: 1}})
+
+	docs, err := r.Find(ctx, bson.M{
+		"status":     string(domain.ReservationStatusActive),
+		"expires_at": bson.M{"$lte": now},
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return toReservations(docs), nil
+}
+
+// UpdateStatus moves a reservation out of status from, returning a conflict when another
+// writer already did, so the held stock is committed or released exactly once.
+func (r *ReservationRepository) UpdateStatus(ctx context.Context, id domain.ID, from, to domain.ReservationStatus) error {
+	objectID, err := primitive.ObjectIDFromHex(string(id))
+	if err != nil {
+		return parseError(err)
+	}
+
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": objectID, "status": string(from)},
+		bson.M{"$set": bson.M{"status": string(to), "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return parseError(err)
+	}
+	if result.MatchedCount == 0 {
+		return serviceerrors.NewConflictError("reservation is no longer " + string(from))
+	}
+
+	return nil
+}
+
+func toReservations(docs []document.ReservationDocument) []*domain.Reservation {
+	reservations := make([]*domain.Reservation, len(docs))
+	for i, doc := range docs {
+		reservations[i] = doc.ToDomain()
+	}
+	return reservations
+}
@@ -0,0 +1,88 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rafaelleal24/challenge/internal/adapters/mongo/repository"
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
+)
+
+func TestReservationRepository_CreateAndGetByOrderID(t *testing.T) {
+	repo := repository.NewReservationRepository(testDB)
+	ctx := context.Background()
+
+	orderID := domain.ID("aabbccddee112233aabb0001")
+	reservations := []*domain.Reservation{
+		domain.NewReservation(orderID, "aabbccddee112233aabbcc01", "", 2, time.Now().Add(time.Minute)),
+		domain.NewReservation(orderID, "aabbccddee112233aabbcc02", "", 1, time.Now().Add(time.Minute)),
+	}
+
+	if err := repo.Create(ctx, reservations); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for _, r := range reservations {
+		if r.ID == "" {
+			t.Fatal("expected reservation ID to be assigned")
+		}
+	}
+
+	found, err := repo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(found) != 2 {
+		t.Fatalf("expected 2 reservations, got %d", len(found))
+	}
+	if found[0].Status != domain.ReservationStatusActive {
+		t.Fatalf("expected status active, got %q", found[0].Status)
+	}
+}
+
+func TestReservationRepository_GetExpired(t *testing.T) {
+	repo := repository.NewReservationRepository(testClient.Database("test_reservations_expired"))
+	ctx := context.Background()
+	now := time.Now()
+
+	expired := domain.NewReservation("aabbccddee112233aabb0002", "aabbccddee112233aabbcc01", "", 1, now.Add(-time.Minute))
+	fresh := domain.NewReservation("aabbccddee112233aabb0002", "aabbccddee112233aabbcc02", "", 1, now.Add(time.Minute))
+	released := domain.NewReservation("aabbccddee112233aabb0003", "aabbccddee112233aabbcc03", "", 1, now.Add(-time.Minute))
+	released.Status = domain.ReservationStatusReleased
+	if err := repo.Create(ctx, []*domain.Reservation{expired, fresh, released}); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	found, err := repo.GetExpired(ctx, now, 10)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(found) != 1 || found[0].ID != expired.ID {
+		t.Fatalf("expected only the expired active reservation, got %+v", found)
+	}
+}
+
+func TestReservationRepository_UpdateStatus(t *testing.T) {
+	repo := repository.NewReservationRepository(testDB)
+	ctx := context.Background()
+
+	reservation := domain.NewReservation("aabbccddee112233aabb0004", "aabbccddee112233aabbcc01", "", 1, time.Now().Add(time.Minute))
+	if err := repo.Create(ctx, []*domain.Reservation{reservation}); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	t.Run("moves reservation out of its current status", func(t *testing.T) {
+		err := repo.UpdateStatus(ctx, reservation.ID, domain.ReservationStatusActive, domain.ReservationStatusCommitted)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("conflicts when the status already changed", func(t *testing.T) {
+		err := repo.UpdateStatus(ctx, reservation.ID, domain.ReservationStatusActive, domain.ReservationStatusReleased)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			t.Fatalf("expected KindConflict, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rafaelleal24/challenge/internal/adapters/mongo/document"
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/logger"
+	"github.com/rafaelleal24/challenge/internal/core/port"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type StockMovementRepository struct {
+	*BaseRepository[document.StockMovementDocument]
+	collection *mongo.Collection
+}
+
+func NewStockMovementRepository(db *mongo.Database) port.StockMovementPort {
+	repo := &StockMovementRepository{
+		BaseRepository: NewBaseRepository[document.StockMovementDocument](db, "stock_movements"),
+		collection:     db.Collection("stock_movements"),
+	}
+
+	if err := repo.createIndexes(context.Background()); err != nil {
+		logger.Error(context.Background(), "failed to create indexes", err, map[string]any{
+			"collection": "stock_movements",
+		})
+	}
+
+	return repo
+}
+
+func (r *StockMovementRepository) createIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "product_id", Value: 1},
+				{Key: "created_at", Value: -1},
+				{Key: "_id", Value: -1},
+			},
+			Options: options.Index().SetUnique(false),
+		},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+func (r *StockMovementRepository) Create(ctx context.Context, movement *domain.StockMovement) error {
+	doc, err := document.ToStockMovementDocument(movement)
+	if err != nil {
+		return parseError(err)
+	}
+
+	result, err := r.collection.InsertOne(ctx, doc)
+	if err != nil {
+		return parseError(err)
+	}
+
+	movement.ID = domain.ID(result.InsertedID.(primitive.ObjectID).Hex())
+	return nil
+}
+
+func (r *StockMovementRepository) GetByProductID(ctx context.Context, productID domain.ID, limit int64, cursor string) (*port.Page[*domain.StockMovement], error) {
+	objectID, err := primitive.ObjectIDFromHex(string(productID))
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	docs, err := r.FindPage(ctx, bson.M{"product_id": objectID}, "created_at", port.PageRequest{
+		Limit:      limit,
+		Cursor:     cursor,
+		Descending: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	movements := make([]*domain.StockMovement, len(docs.Items))
+	for i, doc := range docs.Items {
+		movements[i] = doc.ToDomain()
+	}
+
+	return &port.Page[*domain.StockMovement]{
+		Items:      movements,
+		NextCursor: docs.NextCursor,
+	}, nil
+}
@@ -0,0 +1,71 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rafaelleal24/challenge/internal/adapters/mongo/repository"
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
+)
+
+func TestStockMovementRepository_CreateAndGetByProductID(t *testing.T) {
+	repo := repository.NewStockMovementRepository(testDB)
+	ctx := context.Background()
+	productID := domain.ID("aabbccddee112233aabb1001")
+
+	restock := domain.NewStockMovement(productID, "", 10, domain.StockMovementReasonRestock)
+	restock.Actor = "clerk-1"
+	restock.Note = "delivery"
+	deduction := domain.NewStockMovement(productID, "", -2, domain.StockMovementReasonOrderDeduction)
+	deduction.OrderID = "aabbccddee112233aabb2001"
+	deduction.CreatedAt = restock.CreatedAt.Add(time.Second)
+	other := domain.NewStockMovement("aabbccddee112233aabb1002", "", 5, domain.StockMovementReasonRestock)
+
+	for _, m := range []*domain.StockMovement{restock, deduction, other} {
+		if err := repo.Create(ctx, m); err != nil {
+			t.Fatalf("setup: create movement failed: %v", err)
+		}
+		if m.ID == "" {
+			t.Fatal("expected movement ID to be assigned")
+		}
+	}
+
+	t.Run("returns the product's movements newest first", func(t *testing.T) {
+		page, err := repo.GetByProductID(ctx, productID, 10, "")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(page.Items) != 2 {
+			t.Fatalf("expected 2 movements, got %d", len(page.Items))
+		}
+		if page.Items[0].ID != deduction.ID || page.Items[0].OrderID != deduction.OrderID {
+			t.Fatalf("expected the order deduction first, got %+v", page.Items[0])
+		}
+		if page.Items[1].Actor != "clerk-1" || page.Items[1].Note != "delivery" || page.Items[1].OrderID != "" {
+			t.Fatalf("unexpected restock movement %+v", page.Items[1])
+		}
+	})
+
+	t.Run("pages with a cursor", func(t *testing.T) {
+		first, err := repo.GetByProductID(ctx, productID, 1, "")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		second, err := repo.GetByProductID(ctx, productID, 1, first.NextCursor)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(second.Items) != 1 || second.Items[0].ID != restock.ID || second.NextCursor != "" {
+			t.Fatalf("unexpected second page %+v (next cursor %q)", second.Items, second.NextCursor)
+		}
+	})
+
+	t.Run("returns error for invalid product ID", func(t *testing.T) {
+		_, err := repo.GetByProductID(ctx, "bad-id", 10, "")
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+}
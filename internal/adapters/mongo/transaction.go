@@ -2,11 +2,16 @@ package mongo
 
 import (
 	"context"
+	"errors"
 
 	"github.com/rafaelleal24/challenge/internal/core/port"
+	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// writeConflictCode is the server error code of a transaction aborted by a concurrent write.
+const writeConflictCode = 112
+
 type TransactionManager struct {
 	client *mongo.Client
 }
@@ -16,6 +21,11 @@ func NewTransactionManager(client *mongo.Client) port.TransactionManager {
 }
 
 func (tm *TransactionManager) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	// join the caller's transaction instead of starting a nested one
+	if mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
 	session, err := tm.client.StartSession()
 	if err != nil {
 		return err
@@ -25,6 +35,20 @@ func (tm *TransactionManager) WithTransaction(ctx context.Context, fn func(ctx c
 	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
 		return nil, fn(sessCtx)
 	})
+	if isTransientError(err) {
+		return serviceerrors.NewUnavailableError("transaction aborted by a concurrent write, retry: " + err.Error())
+	}
 
 	return err
 }
+
+// isTransientError reports whether err aborted the transaction for a reason a later retry may
+// clear. The driver already retries these for a while before giving up.
+func isTransientError(err error) bool {
+	var labeled mongo.LabeledError
+	if errors.As(err, &labeled) && labeled.HasErrorLabel("TransientTransactionError") {
+		return true
+	}
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorCode(writeConflictCode)
+}
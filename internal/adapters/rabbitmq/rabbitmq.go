@@ -19,10 +19,15 @@ type RabbitMQAdapter struct {
 	conn    *amqp.Connection
 	channel *amqp.Channel
 	config  config.RabbitMQConfig
+	// exchanges maps entity names to the configured exchange their events are published to.
+	exchanges map[string]string
 }
 
 func NewRabbitMQAdapter(cfg config.RabbitMQConfig) (*RabbitMQAdapter, error) {
-	adapter := &RabbitMQAdapter{config: cfg, mu: sync.Mutex{}}
+	adapter := &RabbitMQAdapter{config: cfg, mu: sync.Mutex{}, exchanges: make(map[string]string, len(cfg.ExchangeConfigs))}
+	for _, ec := range cfg.ExchangeConfigs {
+		adapter.exchanges[ec.Entity] = ec.Name
+	}
 
 	if err := adapter.connect(); err != nil {
 		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
@@ -89,6 +94,13 @@ func (r *RabbitMQAdapter) publish(ctx context.Context, eventName, entityName str
 		return ctx.Err()
 	}
 
+	// Publishing to an exchange that was never declared is not reported back: the broker
+	// drops the message and closes the channel, so refuse instead.
+	exchange, ok := r.exchanges[entityName]
+	if !ok {
+		return fmt.Errorf("no exchange configured for %s events", entityName)
+	}
+
 	msg := amqp.Publishing{
 		ContentType:  "application/json",
 		Body:         body,
@@ -96,7 +108,6 @@ func (r *RabbitMQAdapter) publish(ctx context.Context, eventName, entityName str
 		Timestamp:    time.Now(),
 	}
 
-	exchange := fmt.Sprintf("exchange.%s", entityName)
 	routingKey := eventName
 
 	var lastErr error
@@ -38,6 +38,7 @@ func TestMain(m *testing.M) {
 		RetryDelay: 100 * time.Millisecond,
 		ExchangeConfigs: []config.ExchangeConfig{
 			{
+				Entity:     "order",
 				Name:       "exchange.order",
 				Type:       "direct",
 				Durable:    true,
@@ -147,6 +148,16 @@ func TestRabbitMQAdapter_Publish(t *testing.T) {
 	})
 }
 
+func TestRabbitMQAdapter_PublishWithoutExchange(t *testing.T) {
+	err := testAdapter.PublishRaw(context.Background(), "invoice.created", "invoice", []byte(`{}`))
+	if err == nil {
+		t.Fatal("expected an error for an entity without an exchange")
+	}
+	if err := testAdapter.HealthCheck(); err != nil {
+		t.Fatalf("expected the channel to stay usable, got %v", err)
+	}
+}
+
 func TestRabbitMQAdapter_CloseAndReconnect(t *testing.T) {
 	ctx := context.Background()
 
@@ -158,6 +169,7 @@ func TestRabbitMQAdapter_CloseAndReconnect(t *testing.T) {
 			RetryDelay: 100 * time.Millisecond,
 			ExchangeConfigs: []config.ExchangeConfig{
 				{
+					Entity:     "order",
 					Name:       "exchange.order",
 					Type:       "direct",
 					Durable:    true,
@@ -184,6 +196,7 @@ func TestRabbitMQAdapter_CloseAndReconnect(t *testing.T) {
 			RetryDelay: 0,
 			ExchangeConfigs: []config.ExchangeConfig{
 				{
+					Entity:     "order",
 					Name:       "exchange.order",
 					Type:       "direct",
 					Durable:    true,
@@ -0,0 +1,74 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/rafaelleal24/challenge/internal/adapters/config"
+	"github.com/rafaelleal24/challenge/internal/core/logger"
+	"github.com/rafaelleal24/challenge/internal/core/port"
+	"github.com/rafaelleal24/challenge/internal/core/service"
+)
+
+const orderExpiryLockKey = "order-expiry"
+
+// OrderExpirer periodically cancels orders that stayed created for longer than the
+// configured age. Replicas take a lock per tick so only one of them scans; a replica
+// that runs anyway is harmless, as each cancellation is conditioned on the order version.
+type OrderExpirer struct {
+	orders   *service.OrderService
+	lock     port.CachePort[string]
+	maxAge   time.Duration
+	interval time.Duration
+	batch    int
+}
+
+func NewOrderExpirer(orders *service.OrderService, lock port.CachePort[string], config config.OrderExpiryConfig) *OrderExpirer {
+	return &OrderExpirer{
+		orders:   orders,
+		lock:     lock,
+		maxAge:   config.MaxAge,
+		interval: config.Interval,
+		batch:    config.BatchSize,
+	}
+}
+
+func (e *OrderExpirer) Start(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.expire(ctx)
+		}
+	}
+}
+
+func (e *OrderExpirer) expire(ctx context.Context) {
+	// The lock is left to expire rather than released, so it covers the whole tick.
+	owner := "locked"
+	acquired, err := e.lock.SetNX(ctx, orderExpiryLockKey, &owner, e.interval)
+	if err != nil {
+		logger.Error(ctx, "orders: failed to acquire expiry lock", err, nil)
+		return
+	}
+	if !acquired {
+		return
+	}
+
+	expired, err := e.orders.ExpireStaleOrders(ctx, time.Now().Add(-e.maxAge), e.batch)
+	if err != nil {
+		logger.Error(ctx, "orders: failed to expire stale orders", err, map[string]any{
+			"batch": e.batch,
+		})
+		return
+	}
+	if expired > 0 {
+		logger.Info(ctx, "orders: expired stale orders", map[string]any{
+			"expired": expired,
+		})
+	}
+}
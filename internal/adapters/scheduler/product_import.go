@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/rafaelleal24/challenge/internal/adapters/config"
+	"github.com/rafaelleal24/challenge/internal/core/logger"
+	"github.com/rafaelleal24/challenge/internal/core/service"
+)
+
+// ProductImporter periodically applies queued product imports, one after another until the
+// queue is empty. Each import is claimed atomically, so replicas share the queue safely.
+type ProductImporter struct {
+	imports  *service.ProductImportService
+	interval time.Duration
+}
+
+func NewProductImporter(imports *service.ProductImportService, config config.ProductImportConfig) *ProductImporter {
+	return &ProductImporter{
+		imports:  imports,
+		interval: config.Interval,
+	}
+}
+
+func (i *ProductImporter) Start(ctx context.Context) {
+	ticker := time.NewTicker(i.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			i.drain(ctx)
+		}
+	}
+}
+
+func (i *ProductImporter) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		found, err := i.imports.ProcessNext(ctx)
+		if err != nil {
+			logger.Error(ctx, "product imports: failed to process import", err, nil)
+			return
+		}
+		if !found {
+			return
+		}
+	}
+}
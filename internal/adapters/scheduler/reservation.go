@@ -0,0 +1,55 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/rafaelleal24/challenge/internal/adapters/config"
+	"github.com/rafaelleal24/challenge/internal/core/logger"
+	"github.com/rafaelleal24/challenge/internal/core/service"
+)
+
+// ReservationSweeper periodically releases expired stock reservations. Running it on
+// several replicas is safe: each reservation can only be released once.
+type ReservationSweeper struct {
+	reservations *service.ReservationService
+	interval     time.Duration
+	batch        int
+}
+
+func NewReservationSweeper(reservations *service.ReservationService, config config.ReservationConfig) *ReservationSweeper {
+	return &ReservationSweeper{
+		reservations: reservations,
+		interval:     config.SweepInterval,
+		batch:        config.SweepBatch,
+	}
+}
+
+func (s *ReservationSweeper) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+func (s *ReservationSweeper) sweep(ctx context.Context) {
+	released, err := s.reservations.ReleaseExpired(ctx, int64(s.batch))
+	if err != nil {
+		logger.Error(ctx, "reservations: failed to release expired reservations", err, map[string]any{
+			"batch": s.batch,
+		})
+		return
+	}
+	if released > 0 {
+		logger.Info(ctx, "reservations: released expired reservations", map[string]any{
+			"released": released,
+		})
+	}
+}
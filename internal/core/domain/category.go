@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Category groups products for navigation. Categories form a tree: ParentID is empty for
+// top-level categories.
+type Category struct {
+	ID        ID
+	Name      string
+	ParentID  ID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func NewCategory(name string, parentID ID) *Category {
+	return &Category{
+		Name:      name,
+		ParentID:  parentID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// CategoryTree indexes a set of categories by parent so the hierarchy can be walked without
+// going back to storage.
+type CategoryTree struct {
+	categories map[ID]*Category
+	children   map[ID][]ID
+}
+
+func NewCategoryTree(categories []*Category) *CategoryTree {
+	tree := &CategoryTree{
+		categories: make(map[ID]*Category, len(categories)),
+		children:   make(map[ID][]ID, len(categories)),
+	}
+	for _, category := range categories {
+		tree.categories[category.ID] = category
+		tree.children[category.ParentID] = append(tree.children[category.ParentID], category.ID)
+	}
+	return tree
+}
+
+// Get returns the category with the given ID, or nil when the tree does not hold it.
+func (t *CategoryTree) Get(id ID) *Category {
+	return t.categories[id]
+}
+
+// HasChildren reports whether any category sits directly under id.
+func (t *CategoryTree) HasChildren(id ID) bool {
+	return len(t.children[id]) > 0
+}
+
+// Subtree returns id followed by the IDs of all categories below it. Each category is listed
+// once, so a cycle left in storage cannot make the walk go on forever.
+func (t *CategoryTree) Subtree(id ID) []ID {
+	ids := []ID{id}
+	seen := map[ID]bool{id: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range t.children[ids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids
+}
+
+// Ancestry returns id followed by the IDs of the categories above it, up to the top level.
+func (t *CategoryTree) Ancestry(id ID) []ID {
+	var ids []ID
+	seen := make(map[ID]bool)
+	for current := id; current != "" && !seen[current]; {
+		seen[current] = true
+		ids = append(ids, current)
+		category := t.categories[current]
+		if category == nil {
+			break
+		}
+		current = category.ParentID
+	}
+	return ids
+}
+
+// IsWithin reports whether id is ancestor or one of its descendants. Moving ancestor under
+// such a category would create a cycle.
+func (t *CategoryTree) IsWithin(id, ancestor ID) bool {
+	seen := make(map[ID]bool)
+	for current := id; current != "" && !seen[current]; {
+		if current == ancestor {
+			return true
+		}
+		seen[current] = true
+		category := t.categories[current]
+		if category == nil {
+			return false
+		}
+		current = category.ParentID
+	}
+	return false
+}
+
+// NormalizeTags trims and lower-cases tags and drops empty and repeated ones, so filtering
+// by tag is case-insensitive. The result is sorted, and nil when no tag is left.
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	var normalized []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+// newTestCategoryTree builds:
+//
+//	clothing
+//	├── shirts
+//	│   └── polos
+//	└── shoes
+//	books
+func newTestCategoryTree() *CategoryTree {
+	return NewCategoryTree([]*Category{
+		{ID: "clothing", Name: "Clothing"},
+		{ID: "shirts", Name: "Shirts", ParentID: "clothing"},
+		{ID: "polos", Name: "Polos", ParentID: "shirts"},
+		{ID: "shoes", Name: "Shoes", ParentID: "clothing"},
+		{ID: "books", Name: "Books"},
+	})
+}
+
+func TestCategoryTree_Subtree(t *testing.T) {
+	tree := newTestCategoryTree()
+
+	if got, want := tree.Subtree("clothing"), []ID{"clothing", "shirts", "shoes", "polos"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if got, want := tree.Subtree("books"), []ID{"books"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestCategoryTree_Subtree_StopsOnCycle(t *testing.T) {
+	tree := NewCategoryTree([]*Category{
+		{ID: "a", Name: "A", ParentID: "b"},
+		{ID: "b", Name: "B", ParentID: "a"},
+	})
+
+	if got, want := tree.Subtree("a"), []ID{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestCategoryTree_Ancestry(t *testing.T) {
+	tree := newTestCategoryTree()
+
+	if got, want := tree.Ancestry("polos"), []ID{"polos", "shirts", "clothing"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if got, want := tree.Ancestry("books"), []ID{"books"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestCategoryTree_HasChildren(t *testing.T) {
+	tree := newTestCategoryTree()
+
+	if !tree.HasChildren("shirts") {
+		t.Fatal("expected shirts to have children")
+	}
+	if tree.HasChildren("polos") {
+		t.Fatal("expected polos to have no children")
+	}
+}
+
+func TestCategoryTree_IsWithin(t *testing.T) {
+	tree := newTestCategoryTree()
+
+	tests := []struct {
+		id, ancestor ID
+		want         bool
+	}{
+		{"clothing", "clothing", true},
+		{"polos", "clothing", true},
+		{"shoes", "shirts", false},
+		{"books", "clothing", false},
+		{"clothing", "polos", false},
+		{"unknown", "clothing", false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.id)+"/"+string(tt.ancestor), func(t *testing.T) {
+			if got := tree.IsWithin(tt.id, tt.ancestor); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	got := NormalizeTags([]string{" Summer ", "sale", "SALE", "", "  "})
+
+	if want := []string{"sale", "summer"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// MaxCustomerAddresses bounds the address book of a customer.
+const MaxCustomerAddresses = 20
+
+// Customer is someone who places orders. Email is unique across customers; Phone is optional.
+// DefaultAddressID points at one of Addresses and is empty only when there are none.
+// ErasedAt is set once the customer's personal data has been erased; only the ID and
+// timestamps are kept, so their orders still add up.
+type Customer struct {
+	ID               ID
+	Name             string
+	Email            string
+	Phone            string
+	Addresses        []CustomerAddress
+	DefaultAddressID ID
+	ErasedAt         *time.Time
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// Address is a postal address an order can be shipped to. Country is an ISO 3166-1 alpha-2 code.
+type Address struct {
+	Recipient  string
+	Line1      string
+	Line2      string
+	City       string
+	State      string
+	PostalCode string
+	Country    string
+}
+
+// CustomerAddress is an address saved in a customer's address book. Label is a free-form
+// name such as "home" or "work".
+type CustomerAddress struct {
+	ID    ID
+	Label string
+	Address
+}
+
+func NewCustomer(name, email, phone string) *Customer {
+	return &Customer{
+		Name:      strings.TrimSpace(name),
+		Email:     NormalizeEmail(email),
+		Phone:     phone,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// NormalizeEmail trims and lower-cases an email address, so the same address written in
+// different case belongs to a single customer.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// FindAddress returns the saved address with the given ID, or nil when there is none.
+func (c *Customer) FindAddress(id ID) *CustomerAddress {
+	for i := range c.Addresses {
+		if c.Addresses[i].ID == id {
+			return &c.Addresses[i]
+		}
+	}
+	return nil
+}
+
+// DefaultAddress returns the customer's default address, or nil when the address book is empty.
+func (c *Customer) DefaultAddress() *CustomerAddress {
+	return c.FindAddress(c.DefaultAddressID)
+}
+
+// CustomerExport bundles everything stored about a customer, for them to take away.
+type CustomerExport struct {
+	Customer   *Customer
+	Orders     []*Order
+	ExportedAt time.Time
+}
+
+// CustomerErasedEvent tells other systems to forget a customer. It carries no personal data.
+type CustomerErasedEvent struct {
+	CustomerID ID        `json:"customer_id"`
+	ErasedAt   time.Time `json:"erased_at"`
+}
+
+func (e *CustomerErasedEvent) GetName() string {
+	return "customer.erased"
+}
+
+func (e *CustomerErasedEvent) GetEntityName() string {
+	return "customer"
+}
+
+func NewCustomerErasedEvent(customerID ID, erasedAt time.Time) *CustomerErasedEvent {
+	return &CustomerErasedEvent{
+		CustomerID: customerID,
+		ErasedAt:   erasedAt,
+	}
+}
@@ -0,0 +1,43 @@
+package domain
+
+import "testing"
+
+func TestNewCustomer(t *testing.T) {
+	customer := NewCustomer("  Ada Lovelace ", " Ada@Example.COM ", "+5511999999999")
+
+	if customer.Name != "Ada Lovelace" {
+		t.Fatalf("expected trimmed name, got %q", customer.Name)
+	}
+	if customer.Email != "ada@example.com" {
+		t.Fatalf("expected normalized email, got %q", customer.Email)
+	}
+	if customer.Phone != "+5511999999999" {
+		t.Fatalf("expected phone to be kept, got %q", customer.Phone)
+	}
+	if customer.CreatedAt.IsZero() || customer.UpdatedAt.IsZero() {
+		t.Fatal("expected timestamps to be set")
+	}
+}
+
+func TestCustomer_Addresses(t *testing.T) {
+	customer := &Customer{
+		Addresses: []CustomerAddress{
+			{ID: "home", Label: "Home", Address: Address{Line1: "Rua A, 1"}},
+			{ID: "work", Label: "Work", Address: Address{Line1: "Av. B, 2"}},
+		},
+		DefaultAddressID: "work",
+	}
+
+	if address := customer.FindAddress("home"); address == nil || address.Line1 != "Rua A, 1" {
+		t.Fatalf("expected home address, got %+v", address)
+	}
+	if address := customer.FindAddress("missing"); address != nil {
+		t.Fatalf("expected nil for unknown address, got %+v", address)
+	}
+	if address := customer.DefaultAddress(); address == nil || address.ID != "work" {
+		t.Fatalf("expected work as default address, got %+v", address)
+	}
+	if address := (&Customer{}).DefaultAddress(); address != nil {
+		t.Fatalf("expected no default address for an empty address book, got %+v", address)
+	}
+}
@@ -16,19 +16,81 @@ func (s OrderStatus) IsValid() bool {
 	return s == OrderStatusCreated || s == OrderStatusProcessing || s == OrderStatusShipped || s == OrderStatusDelivered || s == OrderStatusCancelled
 }
 
+// orderStatusTransitions lists, for each status, the statuses an order may move to next.
+// Delivered and cancelled are terminal.
+var orderStatusTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusCreated:    {OrderStatusProcessing, OrderStatusCancelled},
+	OrderStatusProcessing: {OrderStatusShipped, OrderStatusCancelled},
+	OrderStatusShipped:    {OrderStatusDelivered},
+	OrderStatusDelivered:  {},
+	OrderStatusCancelled:  {},
+}
+
+func (s OrderStatus) AllowedTransitions() []OrderStatus {
+	allowed := orderStatusTransitions[s]
+	result := make([]OrderStatus, len(allowed))
+	copy(result, allowed)
+	return result
+}
+
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
 type Order struct {
-	ID          ID
-	CustomerID  ID
-	Items       []OrderItem
-	Status      OrderStatus
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	TotalAmount Amount
+	ID            ID
+	CustomerID    ID
+	Items         []OrderItem
+	Status        OrderStatus
+	StatusHistory []OrderStatusChange
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	TotalAmount   Amount
+	// ShippingAddress is a copy of the destination taken when the order was placed, so later
+	// changes to the customer's address book leave the order as it was. It is nil for orders
+	// placed without one.
+	ShippingAddress *Address
+	// IdempotencyKey is the key the order was placed with, if any, so the response replayed
+	// for it can be found again, e.g. to redact it when the customer is erased.
+	IdempotencyKey string
+	// Version is incremented on every change so concurrent writers can detect stale reads.
+	Version int64
+}
+
+type OrderStatusChange struct {
+	From      OrderStatus
+	To        OrderStatus
+	ChangedAt time.Time
+	Actor     string
+	Reason    string
+}
+
+func NewOrderStatusChange(from, to OrderStatus, actor, reason string) OrderStatusChange {
+	return OrderStatusChange{
+		From:      from,
+		To:        to,
+		ChangedAt: time.Now(),
+		Actor:     actor,
+		Reason:    reason,
+	}
+}
+
+func (o *Order) ApplyStatusChange(change OrderStatusChange) {
+	o.Status = change.To
+	o.UpdatedAt = change.ChangedAt
+	o.StatusHistory = append(o.StatusHistory, change)
+	o.Version++
 }
 
 type OrderItem struct {
 	ID          ID
 	ProductID   ID
+	SKU         string
 	ProductName string
 	Quantity    int
 	UnitPrice   Amount
@@ -63,6 +125,7 @@ func NewOrder(customerID ID, status OrderStatus, items []OrderItem) *Order {
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 		TotalAmount: CalculateTotalAmount(items),
+		Version:     1,
 	}
 }
 
@@ -91,3 +154,83 @@ func NewOrderUpdateStatusEvent(orderID ID, status OrderStatus, oldStatus OrderSt
 		CustomerID: customerID,
 	}
 }
+
+type OrderEventItem struct {
+	ProductID   ID     `json:"product_id"`
+	SKU         string `json:"sku,omitempty"`
+	ProductName string `json:"product_name"`
+	Quantity    int    `json:"quantity"`
+	UnitPrice   Amount `json:"unit_price"`
+	TotalAmount Amount `json:"total_amount"`
+}
+
+func newOrderEventItems(items []OrderItem) []OrderEventItem {
+	eventItems := make([]OrderEventItem, len(items))
+	for i, item := range items {
+		eventItems[i] = OrderEventItem{
+			ProductID:   item.ProductID,
+			SKU:         item.SKU,
+			ProductName: item.ProductName,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			TotalAmount: item.CalculateTotalAmount(),
+		}
+	}
+	return eventItems
+}
+
+type OrderCreatedEvent struct {
+	OrderID     ID               `json:"order_id"`
+	CustomerID  ID               `json:"customer_id"`
+	Status      OrderStatus      `json:"status"`
+	Items       []OrderEventItem `json:"items"`
+	TotalAmount Amount           `json:"total_amount"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+func (e *OrderCreatedEvent) GetName() string {
+	return "order.created"
+}
+
+func (e *OrderCreatedEvent) GetEntityName() string {
+	return "order"
+}
+
+func NewOrderCreatedEvent(order *Order) *OrderCreatedEvent {
+	return &OrderCreatedEvent{
+		OrderID:     order.ID,
+		CustomerID:  order.CustomerID,
+		Status:      order.Status,
+		Items:       newOrderEventItems(order.Items),
+		TotalAmount: order.TotalAmount,
+		CreatedAt:   order.CreatedAt,
+	}
+}
+
+type OrderCancelledEvent struct {
+	OrderID     ID               `json:"order_id"`
+	CustomerID  ID               `json:"customer_id"`
+	OldStatus   OrderStatus      `json:"old_status"`
+	Reason      string           `json:"reason"`
+	Items       []OrderEventItem `json:"items"`
+	CancelledAt time.Time        `json:"cancelled_at"`
+}
+
+func (e *OrderCancelledEvent) GetName() string {
+	return "order.cancelled"
+}
+
+func (e *OrderCancelledEvent) GetEntityName() string {
+	return "order"
+}
+
+func NewOrderCancelledEvent(order *Order, reason string, cancelledAt time.Time) *OrderCancelledEvent {
+	return &OrderCancelledEvent{
+		OrderID:     order.ID,
+		CustomerID:  order.CustomerID,
+		OldStatus:   order.Status,
+		Reason:      reason,
+		Items:       newOrderEventItems(order.Items),
+		CancelledAt: cancelledAt,
+	}
+}
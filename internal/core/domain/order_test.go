@@ -28,6 +28,75 @@ func TestOrderStatus_IsValid(t *testing.T) {
 	}
 }
 
+func TestOrderStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to OrderStatus
+		allowed  bool
+	}{
+		{OrderStatusCreated, OrderStatusProcessing, true},
+		{OrderStatusCreated, OrderStatusCancelled, true},
+		{OrderStatusCreated, OrderStatusShipped, false},
+		{OrderStatusCreated, OrderStatusDelivered, false},
+		{OrderStatusProcessing, OrderStatusShipped, true},
+		{OrderStatusProcessing, OrderStatusCancelled, true},
+		{OrderStatusProcessing, OrderStatusCreated, false},
+		{OrderStatusShipped, OrderStatusDelivered, true},
+		{OrderStatusShipped, OrderStatusCancelled, false},
+		{OrderStatusDelivered, OrderStatusCreated, false},
+		{OrderStatusDelivered, OrderStatusCancelled, false},
+		{OrderStatusCancelled, OrderStatusShipped, false},
+		{OrderStatusCancelled, OrderStatusCreated, false},
+		{"invalid", OrderStatusProcessing, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			if got := tt.from.CanTransitionTo(tt.to); got != tt.allowed {
+				t.Errorf("OrderStatus(%q).CanTransitionTo(%q) = %v, want %v", tt.from, tt.to, got, tt.allowed)
+			}
+		})
+	}
+}
+
+func TestOrderStatus_AllowedTransitions(t *testing.T) {
+	allowed := OrderStatusProcessing.AllowedTransitions()
+	if len(allowed) != 2 || allowed[0] != OrderStatusShipped || allowed[1] != OrderStatusCancelled {
+		t.Fatalf("expected [shipped cancelled], got %v", allowed)
+	}
+
+	allowed[0] = OrderStatusCreated
+	if !OrderStatusProcessing.CanTransitionTo(OrderStatusShipped) {
+		t.Fatal("mutating the returned slice must not change the transition table")
+	}
+
+	if got := OrderStatusDelivered.AllowedTransitions(); len(got) != 0 {
+		t.Fatalf("expected no transitions from delivered, got %v", got)
+	}
+}
+
+func TestOrder_ApplyStatusChange(t *testing.T) {
+	order := NewOrder("cust1", OrderStatusCreated, nil)
+	change := NewOrderStatusChange(OrderStatusCreated, OrderStatusProcessing, "ops", "payment confirmed")
+
+	order.ApplyStatusChange(change)
+
+	if order.Status != OrderStatusProcessing {
+		t.Fatalf("expected status 'processing', got %q", order.Status)
+	}
+	if !order.UpdatedAt.Equal(change.ChangedAt) {
+		t.Fatalf("expected UpdatedAt %v, got %v", change.ChangedAt, order.UpdatedAt)
+	}
+	if len(order.StatusHistory) != 1 {
+		t.Fatalf("expected 1 history entry, got %d", len(order.StatusHistory))
+	}
+	got := order.StatusHistory[0]
+	if got.From != OrderStatusCreated || got.To != OrderStatusProcessing || got.Actor != "ops" || got.Reason != "payment confirmed" {
+		t.Fatalf("unexpected history entry: %+v", got)
+	}
+	if order.Version != 2 {
+		t.Fatalf("expected version 2 after one change, got %d", order.Version)
+	}
+}
+
 func TestNewOrderItem(t *testing.T) {
 	item := NewOrderItem("prod123", "Widget", 3, NewAmountFromCents(1500))
 
@@ -190,3 +259,87 @@ func TestOrderUpdateStatusEvent_GetEntityName(t *testing.T) {
 		t.Fatalf("expected 'order', got %q", got)
 	}
 }
+
+func TestNewOrderCreatedEvent(t *testing.T) {
+	now := time.Now()
+	order := &Order{
+		ID:         "order1",
+		CustomerID: "cust1",
+		Status:     OrderStatusCreated,
+		Items: []OrderItem{
+			{ID: "item1", ProductID: "p1", ProductName: "A", Quantity: 2, UnitPrice: 1000},
+			{ID: "item2", ProductID: "p2", ProductName: "B", Quantity: 1, UnitPrice: 500},
+		},
+		TotalAmount: 2500,
+		CreatedAt:   now,
+	}
+
+	event := NewOrderCreatedEvent(order)
+
+	if event.OrderID != "order1" {
+		t.Fatalf("expected OrderID 'order1', got %q", event.OrderID)
+	}
+	if event.CustomerID != "cust1" {
+		t.Fatalf("expected CustomerID 'cust1', got %q", event.CustomerID)
+	}
+	if event.Status != OrderStatusCreated {
+		t.Fatalf("expected Status 'created', got %q", event.Status)
+	}
+	if event.TotalAmount != 2500 {
+		t.Fatalf("expected TotalAmount 2500, got %d", event.TotalAmount)
+	}
+	if !event.CreatedAt.Equal(now) {
+		t.Fatalf("expected CreatedAt %v, got %v", now, event.CreatedAt)
+	}
+	if len(event.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(event.Items))
+	}
+	if event.Items[0].TotalAmount != 2000 {
+		t.Fatalf("expected first item total 2000, got %d", event.Items[0].TotalAmount)
+	}
+	if event.GetName() != "order.created" {
+		t.Fatalf("expected 'order.created', got %q", event.GetName())
+	}
+	if event.GetEntityName() != "order" {
+		t.Fatalf("expected 'order', got %q", event.GetEntityName())
+	}
+}
+
+func TestNewOrderCancelledEvent(t *testing.T) {
+	now := time.Now()
+	order := &Order{
+		ID:         "order1",
+		CustomerID: "cust1",
+		Status:     OrderStatusProcessing,
+		Items: []OrderItem{
+			{ID: "item1", ProductID: "p1", ProductName: "A", Quantity: 2, UnitPrice: 1000},
+		},
+	}
+
+	event := NewOrderCancelledEvent(order, "customer request", now)
+
+	if event.OrderID != "order1" {
+		t.Fatalf("expected OrderID 'order1', got %q", event.OrderID)
+	}
+	if event.CustomerID != "cust1" {
+		t.Fatalf("expected CustomerID 'cust1', got %q", event.CustomerID)
+	}
+	if event.OldStatus != OrderStatusProcessing {
+		t.Fatalf("expected OldStatus 'processing', got %q", event.OldStatus)
+	}
+	if event.Reason != "customer request" {
+		t.Fatalf("expected Reason 'customer request', got %q", event.Reason)
+	}
+	if !event.CancelledAt.Equal(now) {
+		t.Fatalf("expected CancelledAt %v, got %v", now, event.CancelledAt)
+	}
+	if len(event.Items) != 1 || event.Items[0].ProductID != "p1" || event.Items[0].Quantity != 2 {
+		t.Fatalf("expected items to be copied from order, got %+v", event.Items)
+	}
+	if event.GetName() != "order.cancelled" {
+		t.Fatalf("expected 'order.cancelled', got %q", event.GetName())
+	}
+	if event.GetEntityName() != "order" {
+		t.Fatalf("expected 'order', got %q", event.GetEntityName())
+	}
+}
@@ -2,14 +2,47 @@ package domain
 
 import "time"
 
+// Product is an item of the catalogue. SKU is optional; when set it identifies the product
+// to catalogue imports and is unique across products.
 type Product struct {
-	ID          ID
-	Name        string
-	Description string
-	Price       Amount
-	Stock       int
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID                ID
+	SKU               string
+	Name              string
+	Description       string
+	Price             Amount
+	Stock             int
+	Reserved          int
+	LowStockThreshold *int
+	Variants          []ProductVariant
+	CategoryID        ID
+	Tags              []string
+	ArchivedAt        *time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// ProductVariant is one size, colour or other version of a product, sold under its own SKU.
+// Stock and Reserved count the variant's units only; the product's own counters hold the
+// totals across its variants. A nil Price means the variant sells at the product price.
+type ProductVariant struct {
+	SKU      string
+	Name     string
+	Price    *Amount
+	Stock    int
+	Reserved int
+}
+
+func NewProductVariant(sku, name string, price *Amount, stock int) ProductVariant {
+	return ProductVariant{
+		SKU:   sku,
+		Name:  name,
+		Price: price,
+		Stock: stock,
+	}
+}
+
+func (v *ProductVariant) AvailableStock() int {
+	return v.Stock - v.Reserved
 }
 
 func NewProduct(name string, description string, price Amount, stock int) *Product {
@@ -22,3 +55,145 @@ func NewProduct(name string, description string, price Amount, stock int) *Produ
 		UpdatedAt:   time.Now(),
 	}
 }
+
+// AvailableStock is the stock that is not held by active reservations.
+func (p *Product) AvailableStock() int {
+	return p.Stock - p.Reserved
+}
+
+// HasVariants reports whether the product is sold through variants, in which case every
+// order item and stock change must name one by SKU.
+func (p *Product) HasVariants() bool {
+	return len(p.Variants) > 0
+}
+
+// Variant returns the variant with the given SKU, or nil when the product has none.
+func (p *Product) Variant(sku string) *ProductVariant {
+	for i := range p.Variants {
+		if p.Variants[i].SKU == sku {
+			return &p.Variants[i]
+		}
+	}
+	return nil
+}
+
+// PriceOf returns the price of the variant with the given SKU, falling back to the product
+// price when sku is empty or the variant does not override it.
+func (p *Product) PriceOf(sku string) Amount {
+	if variant := p.Variant(sku); variant != nil && variant.Price != nil {
+		return *variant.Price
+	}
+	return p.Price
+}
+
+// AvailableStockOf returns the available stock of the variant with the given SKU, or of the
+// product itself when sku is empty.
+func (p *Product) AvailableStockOf(sku string) int {
+	if sku == "" {
+		return p.AvailableStock()
+	}
+	if variant := p.Variant(sku); variant != nil {
+		return variant.AvailableStock()
+	}
+	return 0
+}
+
+// IsArchived reports whether the product was withdrawn from sale.
+func (p *Product) IsArchived() bool {
+	return p.ArchivedAt != nil
+}
+
+// StockShortage reports a product that does not have the available stock a change asked for.
+type StockShortage struct {
+	ProductID ID     `json:"product_id"`
+	SKU       string `json:"sku,omitempty"`
+	Requested int    `json:"requested"`
+	Available int    `json:"available"`
+}
+
+// LowStockThresholdOr returns the product's own low-stock threshold, or defaultThreshold
+// when the product does not override it.
+func (p *Product) LowStockThresholdOr(defaultThreshold int) int {
+	if p.LowStockThreshold != nil {
+		return *p.LowStockThreshold
+	}
+	return defaultThreshold
+}
+
+// NewStockAlertEvent returns the event for a change that took the available stock of the
+// variant with the given SKU, or of the product itself when sku is empty, from
+// previousAvailable to what it is now, or nil when no threshold was crossed. Each variant is
+// held to the product's threshold on its own, so one running out is reported even while its
+// siblings have stock. Thresholds apply to available stock, so reserving units raises the
+// alert rather than committing them later. Running out raises out-of-stock instead of
+// low-stock.
+func NewStockAlertEvent(product *Product, sku string, previousAvailable int, defaultThreshold int) Event {
+	now := time.Now()
+	stock := product.Stock
+	if variant := product.Variant(sku); variant != nil {
+		stock = variant.Stock
+	}
+	available := product.AvailableStockOf(sku)
+	if available <= 0 {
+		if previousAvailable <= 0 {
+			return nil
+		}
+		return &ProductOutOfStockEvent{
+			ProductID:      product.ID,
+			SKU:            sku,
+			Name:           product.Name,
+			Stock:          stock,
+			AvailableStock: available,
+			OccurredAt:     now,
+		}
+	}
+
+	threshold := product.LowStockThresholdOr(defaultThreshold)
+	if available >= threshold || previousAvailable < threshold {
+		return nil
+	}
+	return &ProductLowStockEvent{
+		ProductID:      product.ID,
+		SKU:            sku,
+		Name:           product.Name,
+		Stock:          stock,
+		AvailableStock: available,
+		Threshold:      threshold,
+		OccurredAt:     now,
+	}
+}
+
+type ProductLowStockEvent struct {
+	ProductID      ID        `json:"product_id"`
+	SKU            string    `json:"sku,omitempty"`
+	Name           string    `json:"name"`
+	Stock          int       `json:"stock"`
+	AvailableStock int       `json:"available_stock"`
+	Threshold      int       `json:"threshold"`
+	OccurredAt     time.Time `json:"occurred_at"`
+}
+
+func (e *ProductLowStockEvent) GetName() string {
+	return "product.low_stock"
+}
+
+func (e *ProductLowStockEvent) GetEntityName() string {
+	return "product"
+}
+
+type ProductOutOfStockEvent struct {
+	ProductID      ID        `json:"product_id"`
+	SKU            string    `json:"sku,omitempty"`
+	Name           string    `json:"name"`
+	Stock          int       `json:"stock"`
+	AvailableStock int       `json:"available_stock"`
+	OccurredAt     time.Time `json:"occurred_at"`
+}
+
+func (e *ProductOutOfStockEvent) GetName() string {
+	return "product.out_of_stock"
+}
+
+func (e *ProductOutOfStockEvent) GetEntityName() string {
+	return "product"
+}
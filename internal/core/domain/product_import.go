@@ -0,0 +1,117 @@
+package domain
+
+import "time"
+
+type ProductImportFormat string
+
+const (
+	ProductImportFormatCSV    ProductImportFormat = "csv"
+	ProductImportFormatNDJSON ProductImportFormat = "ndjson"
+)
+
+func (f ProductImportFormat) IsValid() bool {
+	return f == ProductImportFormatCSV || f == ProductImportFormatNDJSON
+}
+
+type ProductImportStatus string
+
+const (
+	ProductImportStatusPending   ProductImportStatus = "pending"
+	ProductImportStatusRunning   ProductImportStatus = "running"
+	ProductImportStatusCompleted ProductImportStatus = "completed"
+)
+
+// MaxProductImportErrors bounds the row errors kept on an import; Failed still counts them all.
+const MaxProductImportErrors = 1000
+
+// ProductImport is a catalogue upload upserting products by SKU. Rows are validated when the
+// upload is received; the valid ones are kept in Rows and applied in the background, starting
+// from Processed, so an import interrupted by a restart resumes where it stopped. LeaseID
+// identifies the worker's claim on a running import; only the latest claim saves progress.
+type ProductImport struct {
+	ID         ID
+	Format     ProductImportFormat
+	Status     ProductImportStatus
+	LeaseID    string
+	Rows       []ProductImportRow
+	Total      int
+	Processed  int
+	Created    int
+	Updated    int
+	Failed     int
+	Errors     []ProductImportError
+	CreatedAt  time.Time
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+	UpdatedAt  time.Time
+}
+
+// ProductImportRow is one validated row of an import. Line is where it starts in the upload.
+// Price is nil when the row leaves it out, as exports do for variants priced like their product.
+type ProductImportRow struct {
+	Line              int
+	SKU               string
+	Name              string
+	Description       string
+	Price             *Amount
+	Stock             int
+	LowStockThreshold *int
+	CategoryID        ID
+	Tags              []string
+}
+
+// ProductImportError reports why the row starting at Line was not imported.
+type ProductImportError struct {
+	Line    int    `json:"line"`
+	SKU     string `json:"sku,omitempty"`
+	Message string `json:"message"`
+}
+
+// NewProductImport creates a pending import of the valid rows; total counts every row of the
+// upload and rowErrors holds those rejected during validation.
+func NewProductImport(format ProductImportFormat, rows []ProductImportRow, total int, rowErrors []ProductImportError) *ProductImport {
+	now := time.Now()
+	productImport := &ProductImport{
+		Format:    format,
+		Status:    ProductImportStatusPending,
+		Rows:      rows,
+		Total:     total,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	for _, rowError := range rowErrors {
+		productImport.Fail(rowError)
+	}
+	return productImport
+}
+
+// Fail counts a row as failed, keeping its error while fewer than MaxProductImportErrors are held.
+func (i *ProductImport) Fail(rowError ProductImportError) {
+	i.Failed++
+	if len(i.Errors) < MaxProductImportErrors {
+		i.Errors = append(i.Errors, rowError)
+	}
+}
+
+// Pending returns the rows not applied yet.
+func (i *ProductImport) Pending() []ProductImportRow {
+	if i.Processed >= len(i.Rows) {
+		return nil
+	}
+	return i.Rows[i.Processed:]
+}
+
+// NewProduct builds the product a row creates when no product has its SKU yet. A row without a
+// price gives it a zero price.
+func (r *ProductImportRow) NewProduct() *Product {
+	var price Amount
+	if r.Price != nil {
+		price = *r.Price
+	}
+	product := NewProduct(r.Name, r.Description, price, r.Stock)
+	product.SKU = r.SKU
+	product.LowStockThreshold = r.LowStockThreshold
+	product.CategoryID = r.CategoryID
+	product.Tags = r.Tags
+	return product
+}
@@ -0,0 +1,69 @@
+package domain
+
+import "testing"
+
+func TestNewProductImport(t *testing.T) {
+	rows := []ProductImportRow{{Line: 2, SKU: "A"}, {Line: 4, SKU: "C"}}
+	rowErrors := []ProductImportError{{Line: 3, SKU: "B", Message: "price is required"}}
+
+	productImport := NewProductImport(ProductImportFormatCSV, rows, 3, rowErrors)
+
+	if productImport.Status != ProductImportStatusPending {
+		t.Fatalf("expected status pending, got %s", productImport.Status)
+	}
+	if productImport.Total != 3 || productImport.Failed != 1 || len(productImport.Errors) != 1 {
+		t.Fatalf("unexpected counters %+v", productImport)
+	}
+}
+
+func TestProductImport_Fail(t *testing.T) {
+	productImport := NewProductImport(ProductImportFormatNDJSON, nil, MaxProductImportErrors+5, nil)
+
+	for line := 1; line <= MaxProductImportErrors+5; line++ {
+		productImport.Fail(ProductImportError{Line: line, Message: "invalid"})
+	}
+
+	if productImport.Failed != MaxProductImportErrors+5 {
+		t.Fatalf("expected %d failed rows, got %d", MaxProductImportErrors+5, productImport.Failed)
+	}
+	if len(productImport.Errors) != MaxProductImportErrors {
+		t.Fatalf("expected %d errors kept, got %d", MaxProductImportErrors, len(productImport.Errors))
+	}
+}
+
+func TestProductImport_Pending(t *testing.T) {
+	productImport := NewProductImport(ProductImportFormatCSV, []ProductImportRow{{SKU: "A"}, {SKU: "B"}, {SKU: "C"}}, 3, nil)
+
+	productImport.Processed = 1
+	if pending := productImport.Pending(); len(pending) != 2 || pending[0].SKU != "B" {
+		t.Fatalf("expected rows B and C, got %+v", pending)
+	}
+
+	productImport.Processed = 3
+	if pending := productImport.Pending(); len(pending) != 0 {
+		t.Fatalf("expected no pending rows, got %+v", pending)
+	}
+}
+
+func TestProductImportRow_NewProduct(t *testing.T) {
+	threshold := 3
+	price := NewAmountFromCents(1500)
+	row := ProductImportRow{
+		SKU:               "TEE-1",
+		Name:              "Tee",
+		Price:             &price,
+		Stock:             7,
+		LowStockThreshold: &threshold,
+		CategoryID:        "aabbccddee112233aabbcc01",
+		Tags:              []string{"sale"},
+	}
+
+	product := row.NewProduct()
+
+	if product.SKU != "TEE-1" || product.Name != "Tee" || product.Price != NewAmountFromCents(1500) || product.Stock != 7 {
+		t.Fatalf("unexpected product %+v", product)
+	}
+	if product.LowStockThreshold != &threshold || product.CategoryID != row.CategoryID || len(product.Tags) != 1 {
+		t.Fatalf("unexpected product %+v", product)
+	}
+}
@@ -32,3 +32,118 @@ func TestNewProduct(t *testing.T) {
 		t.Fatalf("UpdatedAt %v not in expected range [%v, %v]", p.UpdatedAt, before, after)
 	}
 }
+
+func TestProduct_AvailableStock(t *testing.T) {
+	product := NewProduct("Widget", "", NewAmountFromCents(100), 10)
+	product.Reserved = 4
+
+	if product.AvailableStock() != 6 {
+		t.Fatalf("expected available stock 6, got %d", product.AvailableStock())
+	}
+}
+
+func TestProduct_IsArchived(t *testing.T) {
+	product := NewProduct("Widget", "", NewAmountFromCents(100), 10)
+	if product.IsArchived() {
+		t.Fatal("expected new product not to be archived")
+	}
+
+	archivedAt := time.Now()
+	product.ArchivedAt = &archivedAt
+	if !product.IsArchived() {
+		t.Fatal("expected product to be archived")
+	}
+}
+
+func TestProduct_Variants(t *testing.T) {
+	largePrice := NewAmountFromCents(150)
+	product := NewProduct("Tee", "", NewAmountFromCents(100), 10)
+	product.Variants = []ProductVariant{
+		NewProductVariant("TEE-M", "Medium", nil, 4),
+		NewProductVariant("TEE-L", "Large", &largePrice, 6),
+	}
+	product.Variants[1].Reserved = 2
+
+	if !product.HasVariants() {
+		t.Fatal("expected product to have variants")
+	}
+	if product.Variant("TEE-XL") != nil {
+		t.Fatal("expected no variant for an unknown SKU")
+	}
+	if product.PriceOf("TEE-M") != 100 || product.PriceOf("TEE-L") != 150 {
+		t.Fatalf("expected prices 100 and 150, got %d and %d", product.PriceOf("TEE-M"), product.PriceOf("TEE-L"))
+	}
+	if product.AvailableStockOf("TEE-L") != 4 {
+		t.Fatalf("expected 4 available for TEE-L, got %d", product.AvailableStockOf("TEE-L"))
+	}
+	if product.AvailableStockOf("TEE-XL") != 0 {
+		t.Fatalf("expected nothing available for an unknown SKU, got %d", product.AvailableStockOf("TEE-XL"))
+	}
+	if product.AvailableStockOf("") != 10 {
+		t.Fatalf("expected the product stock without a SKU, got %d", product.AvailableStockOf(""))
+	}
+}
+
+func TestNewStockAlertEvent(t *testing.T) {
+	threshold := 3
+
+	tests := []struct {
+		name              string
+		product           *Product
+		previousAvailable int
+		want              string
+	}{
+		{"crosses default threshold", &Product{Stock: 9}, 12, "product.low_stock"},
+		{"already below threshold", &Product{Stock: 7}, 9, ""},
+		{"stays above threshold", &Product{Stock: 11}, 15, ""},
+		{"product threshold overrides default", &Product{Stock: 5, LowStockThreshold: &threshold}, 12, ""},
+		{"crosses product threshold", &Product{Stock: 2, LowStockThreshold: &threshold}, 4, "product.low_stock"},
+		{"reaches zero", &Product{Stock: 0}, 12, "product.out_of_stock"},
+		{"was already empty", &Product{Stock: 0}, 0, ""},
+		{"reservation crosses threshold", &Product{Stock: 12, Reserved: 4}, 12, "product.low_stock"},
+		{"reservation takes the rest", &Product{Stock: 12, Reserved: 12}, 9, "product.out_of_stock"},
+		{"committing reserved units changes nothing", &Product{Stock: 8, Reserved: 0}, 8, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := NewStockAlertEvent(tt.product, "", tt.previousAvailable, 10)
+			got := ""
+			if event != nil {
+				got = event.GetName()
+				if event.GetEntityName() != "product" {
+					t.Fatalf("expected entity 'product', got %q", event.GetEntityName())
+				}
+			}
+			if got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNewStockAlertEvent_Variant(t *testing.T) {
+	product := &Product{
+		ID:    "aabbccddee112233aabbccdd",
+		Stock: 6,
+		Variants: []ProductVariant{
+			NewProductVariant("TEE-M", "Medium", nil, 0),
+			NewProductVariant("TEE-L", "Large", nil, 6),
+		},
+	}
+
+	event := NewStockAlertEvent(product, "TEE-M", 2, 3)
+	outOfStock, ok := event.(*ProductOutOfStockEvent)
+	if !ok {
+		t.Fatalf("expected *ProductOutOfStockEvent while a sibling has stock, got %T", event)
+	}
+	if outOfStock.SKU != "TEE-M" || outOfStock.Stock != 0 || outOfStock.AvailableStock != 0 {
+		t.Fatalf("unexpected event %+v", outOfStock)
+	}
+
+	product.Variants[0].Stock = 2
+	event = NewStockAlertEvent(product, "TEE-M", 4, 3)
+	lowStock, ok := event.(*ProductLowStockEvent)
+	if !ok || lowStock.SKU != "TEE-M" || lowStock.Stock != 2 || lowStock.Threshold != 3 {
+		t.Fatalf("expected a low stock alert for TEE-M, got %+v", event)
+	}
+}
@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+type QuoteItem struct {
+	ProductID      ID
+	SKU            string
+	ProductName    string
+	Quantity       int
+	UnitPrice      Amount
+	LineTotal      Amount
+	AvailableStock int
+}
+
+func (i QuoteItem) IsAvailable() bool {
+	return i.AvailableStock >= i.Quantity
+}
+
+// Quote is a priced preview of an order. Token lets the customer place the order at the
+// quoted prices until ExpiresAt.
+type Quote struct {
+	Items       []QuoteItem
+	TotalAmount Amount
+	Token       string
+	ExpiresAt   time.Time
+}
+
+func NewQuote(items []OrderItem, availableStock []int) *Quote {
+	quoteItems := make([]QuoteItem, len(items))
+	for i, item := range items {
+		quoteItems[i] = QuoteItem{
+			ProductID:      item.ProductID,
+			SKU:            item.SKU,
+			ProductName:    item.ProductName,
+			Quantity:       item.Quantity,
+			UnitPrice:      item.UnitPrice,
+			LineTotal:      item.CalculateTotalAmount(),
+			AvailableStock: availableStock[i],
+		}
+	}
+	return &Quote{
+		Items:       quoteItems,
+		TotalAmount: CalculateTotalAmount(items),
+	}
+}
+
+func (q *Quote) IsAvailable() bool {
+	for _, item := range q.Items {
+		if !item.IsAvailable() {
+			return false
+		}
+	}
+	return true
+}
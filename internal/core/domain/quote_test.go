@@ -0,0 +1,28 @@
+package domain
+
+import "testing"
+
+func TestNewQuote(t *testing.T) {
+	items := []OrderItem{
+		*NewOrderItem("prod1", "Widget", 2, NewAmountFromCents(1500)),
+		*NewOrderItem("prod2", "Gadget", 4, NewAmountFromCents(250)),
+	}
+
+	quote := NewQuote(items, []int{5, 3})
+
+	if quote.TotalAmount != 4000 {
+		t.Fatalf("expected total 4000, got %d", quote.TotalAmount)
+	}
+	if quote.Items[0].LineTotal != 3000 || quote.Items[1].LineTotal != 1000 {
+		t.Fatalf("unexpected line totals: %d, %d", quote.Items[0].LineTotal, quote.Items[1].LineTotal)
+	}
+	if !quote.Items[0].IsAvailable() {
+		t.Fatal("expected first item to be available")
+	}
+	if quote.Items[1].IsAvailable() {
+		t.Fatal("expected second item to be unavailable")
+	}
+	if quote.IsAvailable() {
+		t.Fatal("expected quote to be unavailable when any item is short")
+	}
+}
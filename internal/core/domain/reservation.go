@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+type ReservationStatus string
+
+const (
+	ReservationStatusActive    ReservationStatus = "active"
+	ReservationStatusCommitted ReservationStatus = "committed"
+	ReservationStatusReleased  ReservationStatus = "released"
+)
+
+// Reservation holds Quantity units of a product, or of its variant SKU, for an order until
+// ExpiresAt. While active the units count as reserved on the product; committing turns them
+// into a stock deduction and releasing makes them available again.
+type Reservation struct {
+	ID        ID
+	OrderID   ID
+	ProductID ID
+	SKU       string
+	Quantity  int
+	Status    ReservationStatus
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func NewReservation(orderID, productID ID, sku string, quantity int, expiresAt time.Time) *Reservation {
+	now := time.Now()
+	return &Reservation{
+		OrderID:   orderID,
+		ProductID: productID,
+		SKU:       sku,
+		Quantity:  quantity,
+		Status:    ReservationStatusActive,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func (r *Reservation) IsExpired(now time.Time) bool {
+	return r.Status == ReservationStatusActive && !now.Before(r.ExpiresAt)
+}
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReservation_IsExpired(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		reservation *Reservation
+		want        bool
+	}{
+		{"active and past expiry", NewReservation("order", "product", "", 1, now.Add(-time.Second)), true},
+		{"active and before expiry", NewReservation("order", "product", "", 1, now.Add(time.Minute)), false},
+		{"committed and past expiry", &Reservation{Status: ReservationStatusCommitted, ExpiresAt: now.Add(-time.Second)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.reservation.IsExpired(now); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+type StockMovementReason string
+
+const (
+	StockMovementReasonOrderDeduction StockMovementReason = "order_deduction"
+	StockMovementReasonCancellation   StockMovementReason = "cancellation"
+	StockMovementReasonRestock        StockMovementReason = "restock"
+	StockMovementReasonCorrection     StockMovementReason = "correction"
+	StockMovementReasonReturn         StockMovementReason = "return"
+	StockMovementReasonImport         StockMovementReason = "import"
+)
+
+// IsManual reports whether the reason is one an operator may record by hand; the
+// others are written by the order flow and by product imports.
+func (r StockMovementReason) IsManual() bool {
+	switch r {
+	case StockMovementReasonRestock, StockMovementReasonCorrection, StockMovementReasonReturn:
+		return true
+	}
+	return false
+}
+
+// StockMovement records a change to a product's stock, or to one variant's when SKU is set.
+// Quantity is signed: positive movements add stock and negative ones remove it.
+type StockMovement struct {
+	ID        ID
+	ProductID ID
+	SKU       string
+	OrderID   ID
+	Quantity  int
+	Reason    StockMovementReason
+	Actor     string
+	Note      string
+	CreatedAt time.Time
+}
+
+func NewStockMovement(productID ID, sku string, quantity int, reason StockMovementReason) *StockMovement {
+	return &StockMovement{
+		ProductID: productID,
+		SKU:       sku,
+		Quantity:  quantity,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "testing"
+
+func TestStockMovementReason_IsManual(t *testing.T) {
+	tests := []struct {
+		reason StockMovementReason
+		want   bool
+	}{
+		{StockMovementReasonRestock, true},
+		{StockMovementReasonCorrection, true},
+		{StockMovementReasonReturn, true},
+		{StockMovementReasonOrderDeduction, false},
+		{StockMovementReasonCancellation, false},
+		{StockMovementReason("unknown"), false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.reason), func(t *testing.T) {
+			if got := tt.reason.IsManual(); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
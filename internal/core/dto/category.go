@@ -0,0 +1,15 @@
+package dto
+
+import "github.com/rafaelleal24/challenge/internal/core/domain"
+
+type CreateCategoryRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+	// ParentID places the category under an existing one; it is a top-level category when empty.
+	ParentID domain.ID `json:"parent_id"`
+}
+
+// UpdateCategoryRequest renames or moves a category; an empty ParentID moves it to the top level.
+type UpdateCategoryRequest struct {
+	Name     *string    `json:"name" binding:"omitempty,min=1,max=100"`
+	ParentID *domain.ID `json:"parent_id"`
+}
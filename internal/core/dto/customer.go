@@ -0,0 +1,35 @@
+package dto
+
+// CreateCustomerRequest registers a customer. Email is unique across customers, regardless of
+// case; Phone is optional and in E.164 format, such as +5511999999999.
+type CreateCustomerRequest struct {
+	Name  string `json:"name" binding:"required,max=100"`
+	Email string `json:"email" binding:"required,email,max=254"`
+	Phone string `json:"phone" binding:"omitempty,e164"`
+}
+
+// UpdateCustomerRequest changes the given fields; an empty Phone removes the customer's phone.
+type UpdateCustomerRequest struct {
+	Name  *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Email *string `json:"email" binding:"omitempty,email,max=254"`
+	Phone *string `json:"phone" binding:"omitempty,len=0|e164"`
+}
+
+// AddressRequest is a postal address. Country is an upper-case ISO 3166-1 alpha-2 code, such as BR.
+type AddressRequest struct {
+	Recipient  string `json:"recipient" binding:"required,max=100"`
+	Line1      string `json:"line1" binding:"required,max=200"`
+	Line2      string `json:"line2" binding:"omitempty,max=200"`
+	City       string `json:"city" binding:"required,max=100"`
+	State      string `json:"state" binding:"omitempty,max=100"`
+	PostalCode string `json:"postal_code" binding:"required,max=20"`
+	Country    string `json:"country" binding:"required,iso3166_1_alpha2"`
+}
+
+// SaveAddressRequest adds an address to a customer's address book or replaces a saved one.
+// Default makes it the customer's default address; the first address saved always is.
+type SaveAddressRequest struct {
+	Label string `json:"label" binding:"omitempty,max=50"`
+	AddressRequest
+	Default bool `json:"default"`
+}
@@ -1,13 +1,49 @@
 package dto
 
-import "github.com/rafaelleal24/challenge/internal/core/domain"
+import (
+	"time"
+
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+)
 
 type OrderItem struct {
 	ProductID domain.ID `json:"product_id"`
-	Quantity  int       `json:"quantity"`
+	// SKU names the variant to order; it is required for products with variants.
+	SKU      string `json:"sku,omitempty"`
+	Quantity int    `json:"quantity"`
 }
 
+// CreateOrderRequest places an order. The order ships either to ShippingAddressID, one of the
+// customer's saved addresses, or to the inline ShippingAddress; at most one of them may be set.
 type CreateOrderRequest struct {
-	CustomerID domain.ID   `json:"customer_id"`
-	Items      []OrderItem `json:"items"`
+	CustomerID        domain.ID       `json:"customer_id"`
+	Items             []OrderItem     `json:"items"`
+	QuoteToken        string          `json:"quote_token,omitempty"`
+	ShippingAddressID domain.ID       `json:"shipping_address_id,omitempty"`
+	ShippingAddress   *AddressRequest `json:"shipping_address,omitempty"`
+}
+
+type QuoteOrderRequest struct {
+	Items []OrderItem `json:"items"`
+}
+
+type ListOrdersRequest struct {
+	CustomerID   domain.ID          `form:"customer_id"`
+	Status       domain.OrderStatus `form:"status"`
+	CreatedFrom  time.Time          `form:"created_from"`
+	CreatedTo    time.Time          `form:"created_to"`
+	MinTotal     *int               `form:"min_total" binding:"omitempty,gte=0"`
+	MaxTotal     *int               `form:"max_total" binding:"omitempty,gte=0"`
+	Sort         string             `form:"sort" binding:"omitempty,oneof=created_at total_amount"`
+	Order        string             `form:"order" binding:"omitempty,oneof=asc desc"`
+	Limit        int64              `form:"limit" binding:"omitempty,gte=1,lte=100"`
+	Cursor       string             `form:"cursor"`
+	IncludeTotal bool               `form:"include_total"`
+}
+
+// ListCustomerOrdersRequest pages through one customer's orders, newest first.
+type ListCustomerOrdersRequest struct {
+	Status domain.OrderStatus `form:"status"`
+	Limit  int64              `form:"limit" binding:"omitempty,gte=1,lte=100"`
+	Cursor string             `form:"cursor"`
 }
@@ -1,8 +1,88 @@
 package dto
 
+import "github.com/rafaelleal24/challenge/internal/core/domain"
+
+// CreateProductRequest creates a product sold either as a single item or through Variants.
+// A product with variants takes its stock from theirs, so Stock is then ignored.
+// Tags are normalized to lower case, so their case and order do not matter. SKU is optional
+// and identifies the product to catalogue imports.
 type CreateProductRequest struct {
-	Name        string `json:"name" binding:"required"`
-	Description string `json:"description"`
-	Price       int    `json:"price" binding:"required,gt=0"`
-	Stock       int    `json:"stock" binding:"required,gte=0"`
+	SKU               string                 `json:"sku" binding:"omitempty,max=64"`
+	Name              string                 `json:"name" binding:"required"`
+	Description       string                 `json:"description"`
+	Price             int                    `json:"price" binding:"required,gt=0"`
+	Stock             int                    `json:"stock" binding:"required_without=Variants,gte=0"`
+	LowStockThreshold *int                   `json:"low_stock_threshold" binding:"omitempty,gte=0"`
+	Variants          []CreateVariantRequest `json:"variants" binding:"omitempty,max=100,dive"`
+	CategoryID        domain.ID              `json:"category_id"`
+	Tags              []string               `json:"tags" binding:"omitempty,max=20,dive,max=50"`
+}
+
+// CreateVariantRequest describes one variant; Price overrides the product price when set.
+type CreateVariantRequest struct {
+	SKU   string `json:"sku" binding:"required,max=64"`
+	Name  string `json:"name" binding:"required"`
+	Price *int   `json:"price" binding:"omitempty,gt=0"`
+	Stock int    `json:"stock" binding:"gte=0"`
+}
+
+// UpdateProductRequest changes the given fields. An empty CategoryID removes the product from
+// its category and an empty Tags list clears its tags.
+type UpdateProductRequest struct {
+	Name              *string    `json:"name" binding:"omitempty,min=1"`
+	Description       *string    `json:"description"`
+	Price             *int       `json:"price" binding:"omitempty,gt=0"`
+	LowStockThreshold *int       `json:"low_stock_threshold" binding:"omitempty,gte=0"`
+	CategoryID        *domain.ID `json:"category_id"`
+	Tags              *[]string  `json:"tags" binding:"omitempty,max=20,dive,max=50"`
+}
+
+type ListProductsRequest struct {
+	NamePrefix   string `form:"name_prefix"`
+	MinPrice     *int   `form:"min_price" binding:"omitempty,gte=0"`
+	MaxPrice     *int   `form:"max_price" binding:"omitempty,gte=0"`
+	InStock      bool   `form:"in_stock"`
+	Category     string `form:"category"`
+	Tag          string `form:"tag"`
+	Sort         string `form:"sort" binding:"omitempty,oneof=name price created_at"`
+	Order        string `form:"order" binding:"omitempty,oneof=asc desc"`
+	Limit        int64  `form:"limit" binding:"omitempty,gte=1,lte=100"`
+	Cursor       string `form:"cursor"`
+	IncludeTotal bool   `form:"include_total"`
+}
+
+// IsPaginated reports whether any pagination parameter was sent. Requests without one get
+// the legacy response: the first page of matching products in a bare array.
+func (r *ListProductsRequest) IsPaginated() bool {
+	return r.Limit > 0 || r.Cursor != "" || r.IncludeTotal
+}
+
+type SearchProductsRequest struct {
+	Query        string `form:"q" binding:"required"`
+	Limit        int64  `form:"limit" binding:"omitempty,gte=1,lte=100"`
+	Cursor       string `form:"cursor"`
+	IncludeTotal bool   `form:"include_total"`
+}
+
+type StockAdjustmentRequest struct {
+	// SKU selects the variant to adjust; it is required for products with variants.
+	SKU string `json:"sku"`
+	// Quantity is added to the stock; only corrections may be negative.
+	Quantity int    `json:"quantity" binding:"required,ne=0"`
+	Reason   string `json:"reason" binding:"required,oneof=restock correction return"`
+	Note     string `json:"note" binding:"omitempty,max=500"`
+}
+
+type ListStockMovementsRequest struct {
+	Limit  int64  `form:"limit" binding:"omitempty,gte=1,lte=100"`
+	Cursor string `form:"cursor"`
+}
+
+// ProductImportRequest picks the upload format; without it the Content-Type header decides.
+type ProductImportRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv ndjson"`
+}
+
+type ProductExportRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv ndjson"`
 }
@@ -0,0 +1,31 @@
+package port
+
+import (
+	"context"
+
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mock/$GOFILE -package=mock
+
+// CategoryUpdate holds the category fields to change; nil fields are left untouched. An empty
+// ParentID moves the category to the top level.
+type CategoryUpdate struct {
+	Name     *string
+	ParentID *domain.ID
+}
+
+type CategoryPort interface {
+	Create(ctx context.Context, category *domain.Category) error
+	GetByID(ctx context.Context, id domain.ID) (*domain.Category, error)
+	// List returns every category, by name. Catalogues hold few enough categories for the
+	// whole tree to be loaded at once.
+	List(ctx context.Context) ([]*domain.Category, error)
+	Update(ctx context.Context, id domain.ID, update CategoryUpdate) (*domain.Category, error)
+	Delete(ctx context.Context, id domain.ID) error
+	// Lock writes to the category within the caller's transaction without changing it, so a
+	// concurrent transaction that moves, deletes or locks the same category conflicts with it
+	// and is retried against the committed tree. It fails with not found when the category
+	// does not exist.
+	Lock(ctx context.Context, id domain.ID) error
+}
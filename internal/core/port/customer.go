@@ -2,13 +2,43 @@ package port
 
 import (
 	"context"
+	"time"
 
 	"github.com/rafaelleal24/challenge/internal/core/domain"
 )
 
 //go:generate mockgen -source=$GOFILE -destination=mock/$GOFILE -package=mock
 
+// CustomerUpdate holds the customer fields to change; nil fields are left as they are.
+type CustomerUpdate struct {
+	Name  *string
+	Email *string
+	Phone *string
+}
+
 type CustomerPort interface {
-	Create(ctx context.Context) (domain.ID, error)
+	Create(ctx context.Context, customer *domain.Customer) error
+	GetByID(ctx context.Context, id domain.ID) (*domain.Customer, error)
+	// Update changes the given fields. An erased customer is reported as not found.
+	Update(ctx context.Context, id domain.ID, update CustomerUpdate) (*domain.Customer, error)
+	// Exists reports whether a customer that has not been erased has the given ID.
 	Exists(ctx context.Context, id domain.ID) (bool, error)
+	// AddAddress saves address in the customer's address book and sets its ID. The address
+	// becomes the default when makeDefault is set or the address book was empty. It fails with
+	// an unprocessable entity error once the book holds domain.MaxCustomerAddresses addresses,
+	// and reports an erased customer as not found.
+	AddAddress(ctx context.Context, customerID domain.ID, address *domain.CustomerAddress, makeDefault bool) (*domain.Customer, error)
+	// ReplaceAddress overwrites the saved address with the same ID, making it the default when
+	// makeDefault is set.
+	ReplaceAddress(ctx context.Context, customerID domain.ID, address domain.CustomerAddress, makeDefault bool) (*domain.Customer, error)
+	// RemoveAddress deletes a saved address. When it was the default, the first remaining
+	// address becomes the default.
+	RemoveAddress(ctx context.Context, customerID, addressID domain.ID) (*domain.Customer, error)
+	// Erase removes the customer's personal data, marks them erased at erasedAt and writes
+	// event to the outbox, in one transaction.
+	Erase(ctx context.Context, id domain.ID, erasedAt time.Time, event domain.Event) error
+	// Lock writes to the customer within the caller's transaction without changing them, so a
+	// concurrent erasure conflicts with it and one of the two is retried. It fails with not
+	// found when the customer does not exist or has been erased.
+	Lock(ctx context.Context, id domain.ID) error
 }
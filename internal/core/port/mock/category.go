@@ -0,0 +1,130 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: category.go
+//
+// Generated by this command:
+//
+//	mockgen -source=category.go -destination=mock/category.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/rafaelleal24/challenge/internal/core/domain"
+	port "github.com/rafaelleal24/challenge/internal/core/port"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCategoryPort is a mock of CategoryPort interface.
+type MockCategoryPort struct {
+	ctrl     *gomock.Controller
+	recorder *MockCategoryPortMockRecorder
+	isgomock struct{}
+}
+
+// MockCategoryPortMockRecorder is the mock recorder for MockCategoryPort.
+type MockCategoryPortMockRecorder struct {
+	mock *MockCategoryPort
+}
+
+// NewMockCategoryPort creates a new mock instance.
+func NewMockCategoryPort(ctrl *gomock.Controller) *MockCategoryPort {
+	mock := &MockCategoryPort{ctrl: ctrl}
+	mock.recorder = &MockCategoryPortMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCategoryPort) EXPECT() *MockCategoryPortMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockCategoryPort) Create(ctx context.Context, category *domain.Category) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, category)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockCategoryPortMockRecorder) Create(ctx, category any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCategoryPort)(nil).Create), ctx, category)
+}
+
+// Delete mocks base method.
+func (m *MockCategoryPort) Delete(ctx context.Context, id domain.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCategoryPortMockRecorder) Delete(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCategoryPort)(nil).Delete), ctx, id)
+}
+
+// GetByID mocks base method.
+func (m *MockCategoryPort) GetByID(ctx context.Context, id domain.ID) (*domain.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*domain.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockCategoryPortMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockCategoryPort)(nil).GetByID), ctx, id)
+}
+
+// List mocks base method.
+func (m *MockCategoryPort) List(ctx context.Context) ([]*domain.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]*domain.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockCategoryPortMockRecorder) List(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockCategoryPort)(nil).List), ctx)
+}
+
+// Lock mocks base method.
+func (m *MockCategoryPort) Lock(ctx context.Context, id domain.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockCategoryPortMockRecorder) Lock(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockCategoryPort)(nil).Lock), ctx, id)
+}
+
+// Update mocks base method.
+func (m *MockCategoryPort) Update(ctx context.Context, id domain.ID, update port.CategoryUpdate) (*domain.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, id, update)
+	ret0, _ := ret[0].(*domain.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockCategoryPortMockRecorder) Update(ctx, id, update any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCategoryPort)(nil).Update), ctx, id, update)
+}
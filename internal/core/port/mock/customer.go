@@ -12,8 +12,10 @@ package mock
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	domain "github.com/rafaelleal24/challenge/internal/core/domain"
+	port "github.com/rafaelleal24/challenge/internal/core/port"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -41,19 +43,47 @@ func (m *MockCustomerPort) EXPECT() *MockCustomerPortMockRecorder {
 	return m.recorder
 }
 
-// Create mocks base method.
-func (m *MockCustomerPort) Create(ctx context.Context) (domain.ID, error) {
+// AddAddress mocks base method.
+func (m *MockCustomerPort) AddAddress(ctx context.Context, customerID domain.ID, address *domain.CustomerAddress, makeDefault bool) (*domain.Customer, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", ctx)
-	ret0, _ := ret[0].(domain.ID)
+	ret := m.ctrl.Call(m, "AddAddress", ctx, customerID, address, makeDefault)
+	ret0, _ := ret[0].(*domain.Customer)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
+// AddAddress indicates an expected call of AddAddress.
+func (mr *MockCustomerPortMockRecorder) AddAddress(ctx, customerID, address, makeDefault any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAddress", reflect.TypeOf((*MockCustomerPort)(nil).AddAddress), ctx, customerID, address, makeDefault)
+}
+
+// Create mocks base method.
+func (m *MockCustomerPort) Create(ctx context.Context, customer *domain.Customer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, customer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
 // Create indicates an expected call of Create.
-func (mr *MockCustomerPortMockRecorder) Create(ctx any) *gomock.Call {
+func (mr *MockCustomerPortMockRecorder) Create(ctx, customer any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCustomerPort)(nil).Create), ctx, customer)
+}
+
+// Erase mocks base method.
+func (m *MockCustomerPort) Erase(ctx context.Context, id domain.ID, erasedAt time.Time, event domain.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Erase", ctx, id, erasedAt, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Erase indicates an expected call of Erase.
+func (mr *MockCustomerPortMockRecorder) Erase(ctx, id, erasedAt, event any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCustomerPort)(nil).Create), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Erase", reflect.TypeOf((*MockCustomerPort)(nil).Erase), ctx, id, erasedAt, event)
 }
 
 // Exists mocks base method.
@@ -70,3 +100,77 @@ func (mr *MockCustomerPortMockRecorder) Exists(ctx, id any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockCustomerPort)(nil).Exists), ctx, id)
 }
+
+// GetByID mocks base method.
+func (m *MockCustomerPort) GetByID(ctx context.Context, id domain.ID) (*domain.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*domain.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockCustomerPortMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockCustomerPort)(nil).GetByID), ctx, id)
+}
+
+// Lock mocks base method.
+func (m *MockCustomerPort) Lock(ctx context.Context, id domain.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockCustomerPortMockRecorder) Lock(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockCustomerPort)(nil).Lock), ctx, id)
+}
+
+// RemoveAddress mocks base method.
+func (m *MockCustomerPort) RemoveAddress(ctx context.Context, customerID, addressID domain.ID) (*domain.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveAddress", ctx, customerID, addressID)
+	ret0, _ := ret[0].(*domain.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveAddress indicates an expected call of RemoveAddress.
+func (mr *MockCustomerPortMockRecorder) RemoveAddress(ctx, customerID, addressID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveAddress", reflect.TypeOf((*MockCustomerPort)(nil).RemoveAddress), ctx, customerID, addressID)
+}
+
+// ReplaceAddress mocks base method.
+func (m *MockCustomerPort) ReplaceAddress(ctx context.Context, customerID domain.ID, address domain.CustomerAddress, makeDefault bool) (*domain.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceAddress", ctx, customerID, address, makeDefault)
+	ret0, _ := ret[0].(*domain.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplaceAddress indicates an expected call of ReplaceAddress.
+func (mr *MockCustomerPortMockRecorder) ReplaceAddress(ctx, customerID, address, makeDefault any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceAddress", reflect.TypeOf((*MockCustomerPort)(nil).ReplaceAddress), ctx, customerID, address, makeDefault)
+}
+
+// Update mocks base method.
+func (m *MockCustomerPort) Update(ctx context.Context, id domain.ID, update port.CustomerUpdate) (*domain.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, id, update)
+	ret0, _ := ret[0].(*domain.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockCustomerPortMockRecorder) Update(ctx, id, update any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCustomerPort)(nil).Update), ctx, id, update)
+}
@@ -14,6 +14,7 @@ import (
 	reflect "reflect"
 
 	domain "github.com/rafaelleal24/challenge/internal/core/domain"
+	port "github.com/rafaelleal24/challenge/internal/core/port"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -55,6 +56,20 @@ func (mr *MockOrderPortMockRecorder) Create(ctx, order any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOrderPort)(nil).Create), ctx, order)
 }
 
+// CreateWithOutbox mocks base method.
+func (m *MockOrderPort) CreateWithOutbox(ctx context.Context, order *domain.Order, buildEvent func(*domain.Order) domain.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWithOutbox", ctx, order, buildEvent)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWithOutbox indicates an expected call of CreateWithOutbox.
+func (mr *MockOrderPortMockRecorder) CreateWithOutbox(ctx, order, buildEvent any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithOutbox", reflect.TypeOf((*MockOrderPort)(nil).CreateWithOutbox), ctx, order, buildEvent)
+}
+
 // Delete mocks base method.
 func (m *MockOrderPort) Delete(ctx context.Context, id domain.ID) error {
 	m.ctrl.T.Helper()
@@ -69,19 +84,33 @@ func (mr *MockOrderPortMockRecorder) Delete(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockOrderPort)(nil).Delete), ctx, id)
 }
 
+// EraseShippingAddresses mocks base method.
+func (m *MockOrderPort) EraseShippingAddresses(ctx context.Context, customerID domain.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EraseShippingAddresses", ctx, customerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EraseShippingAddresses indicates an expected call of EraseShippingAddresses.
+func (mr *MockOrderPortMockRecorder) EraseShippingAddresses(ctx, customerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EraseShippingAddresses", reflect.TypeOf((*MockOrderPort)(nil).EraseShippingAddresses), ctx, customerID)
+}
+
 // GetByCustomerID mocks base method.
-func (m *MockOrderPort) GetByCustomerID(ctx context.Context, customerID domain.ID, limit, offset int64) ([]*domain.Order, error) {
+func (m *MockOrderPort) GetByCustomerID(ctx context.Context, customerID domain.ID, status domain.OrderStatus, limit int64, cursor string) (*port.Page[*domain.Order], error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByCustomerID", ctx, customerID, limit, offset)
-	ret0, _ := ret[0].([]*domain.Order)
+	ret := m.ctrl.Call(m, "GetByCustomerID", ctx, customerID, status, limit, cursor)
+	ret0, _ := ret[0].(*port.Page[*domain.Order])
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetByCustomerID indicates an expected call of GetByCustomerID.
-func (mr *MockOrderPortMockRecorder) GetByCustomerID(ctx, customerID, limit, offset any) *gomock.Call {
+func (mr *MockOrderPortMockRecorder) GetByCustomerID(ctx, customerID, status, limit, cursor any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByCustomerID", reflect.TypeOf((*MockOrderPort)(nil).GetByCustomerID), ctx, customerID, limit, offset)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByCustomerID", reflect.TypeOf((*MockOrderPort)(nil).GetByCustomerID), ctx, customerID, status, limit, cursor)
 }
 
 // GetByID mocks base method.
@@ -100,30 +129,45 @@ func (mr *MockOrderPortMockRecorder) GetByID(ctx, id any) *gomock.Call {
 }
 
 // GetByStatus mocks base method.
-func (m *MockOrderPort) GetByStatus(ctx context.Context, status domain.OrderStatus, limit, offset int64) ([]*domain.Order, error) {
+func (m *MockOrderPort) GetByStatus(ctx context.Context, status domain.OrderStatus, limit int64, cursor string) (*port.Page[*domain.Order], error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByStatus", ctx, status, limit, offset)
-	ret0, _ := ret[0].([]*domain.Order)
+	ret := m.ctrl.Call(m, "GetByStatus", ctx, status, limit, cursor)
+	ret0, _ := ret[0].(*port.Page[*domain.Order])
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetByStatus indicates an expected call of GetByStatus.
-func (mr *MockOrderPortMockRecorder) GetByStatus(ctx, status, limit, offset any) *gomock.Call {
+func (mr *MockOrderPortMockRecorder) GetByStatus(ctx, status, limit, cursor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByStatus", reflect.TypeOf((*MockOrderPort)(nil).GetByStatus), ctx, status, limit, cursor)
+}
+
+// List mocks base method.
+func (m *MockOrderPort) List(ctx context.Context, filter port.OrderFilter, page port.PageRequest) (*port.Page[*domain.Order], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter, page)
+	ret0, _ := ret[0].(*port.Page[*domain.Order])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockOrderPortMockRecorder) List(ctx, filter, page any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByStatus", reflect.TypeOf((*MockOrderPort)(nil).GetByStatus), ctx, status, limit, offset)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockOrderPort)(nil).List), ctx, filter, page)
 }
 
 // UpdateStatusWithOutbox mocks base method.
-func (m *MockOrderPort) UpdateStatusWithOutbox(ctx context.Context, id domain.ID, status domain.OrderStatus, event domain.Event) error {
+func (m *MockOrderPort) UpdateStatusWithOutbox(ctx context.Context, id domain.ID, expectedVersion int64, change domain.OrderStatusChange, event domain.Event) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateStatusWithOutbox", ctx, id, status, event)
+	ret := m.ctrl.Call(m, "UpdateStatusWithOutbox", ctx, id, expectedVersion, change, event)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateStatusWithOutbox indicates an expected call of UpdateStatusWithOutbox.
-func (mr *MockOrderPortMockRecorder) UpdateStatusWithOutbox(ctx, id, status, event any) *gomock.Call {
+func (mr *MockOrderPortMockRecorder) UpdateStatusWithOutbox(ctx, id, expectedVersion, change, event any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatusWithOutbox", reflect.TypeOf((*MockOrderPort)(nil).UpdateStatusWithOutbox), ctx, id, status, event)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatusWithOutbox", reflect.TypeOf((*MockOrderPort)(nil).UpdateStatusWithOutbox), ctx, id, expectedVersion, change, event)
 }
@@ -14,6 +14,7 @@ import (
 	reflect "reflect"
 
 	domain "github.com/rafaelleal24/challenge/internal/core/domain"
+	port "github.com/rafaelleal24/challenge/internal/core/port"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -41,6 +42,34 @@ func (m *MockProductPort) EXPECT() *MockProductPortMockRecorder {
 	return m.recorder
 }
 
+// Archive mocks base method.
+func (m *MockProductPort) Archive(ctx context.Context, id domain.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Archive", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Archive indicates an expected call of Archive.
+func (mr *MockProductPortMockRecorder) Archive(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Archive", reflect.TypeOf((*MockProductPort)(nil).Archive), ctx, id)
+}
+
+// CommitReservedStock mocks base method.
+func (m *MockProductPort) CommitReservedStock(ctx context.Context, id domain.ID, sku string, quantity int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitReservedStock", ctx, id, sku, quantity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CommitReservedStock indicates an expected call of CommitReservedStock.
+func (mr *MockProductPortMockRecorder) CommitReservedStock(ctx, id, sku, quantity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitReservedStock", reflect.TypeOf((*MockProductPort)(nil).CommitReservedStock), ctx, id, sku, quantity)
+}
+
 // Create mocks base method.
 func (m *MockProductPort) Create(ctx context.Context, product *domain.Product) error {
 	m.ctrl.T.Helper()
@@ -56,32 +85,46 @@ func (mr *MockProductPortMockRecorder) Create(ctx, product any) *gomock.Call {
 }
 
 // DeductStock mocks base method.
-func (m *MockProductPort) DeductStock(ctx context.Context, id domain.ID, quantity int) error {
+func (m *MockProductPort) DeductStock(ctx context.Context, id domain.ID, sku string, quantity int, alert port.StockAlertBuilder) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeductStock", ctx, id, quantity)
+	ret := m.ctrl.Call(m, "DeductStock", ctx, id, sku, quantity, alert)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeductStock indicates an expected call of DeductStock.
-func (mr *MockProductPortMockRecorder) DeductStock(ctx, id, quantity any) *gomock.Call {
+func (mr *MockProductPortMockRecorder) DeductStock(ctx, id, sku, quantity, alert any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeductStock", reflect.TypeOf((*MockProductPort)(nil).DeductStock), ctx, id, quantity)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeductStock", reflect.TypeOf((*MockProductPort)(nil).DeductStock), ctx, id, sku, quantity, alert)
 }
 
-// GetAll mocks base method.
-func (m *MockProductPort) GetAll(ctx context.Context) ([]*domain.Product, error) {
+// DeductStockBatch mocks base method.
+func (m *MockProductPort) DeductStockBatch(ctx context.Context, lines []port.StockLine, alert port.StockAlertBuilder) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", ctx)
-	ret0, _ := ret[0].([]*domain.Product)
+	ret := m.ctrl.Call(m, "DeductStockBatch", ctx, lines, alert)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeductStockBatch indicates an expected call of DeductStockBatch.
+func (mr *MockProductPortMockRecorder) DeductStockBatch(ctx, lines, alert any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeductStockBatch", reflect.TypeOf((*MockProductPort)(nil).DeductStockBatch), ctx, lines, alert)
+}
+
+// ExistsInCategory mocks base method.
+func (m *MockProductPort) ExistsInCategory(ctx context.Context, categoryID domain.ID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExistsInCategory", ctx, categoryID)
+	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAll indicates an expected call of GetAll.
-func (mr *MockProductPortMockRecorder) GetAll(ctx any) *gomock.Call {
+// ExistsInCategory indicates an expected call of ExistsInCategory.
+func (mr *MockProductPortMockRecorder) ExistsInCategory(ctx, categoryID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockProductPort)(nil).GetAll), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExistsInCategory", reflect.TypeOf((*MockProductPort)(nil).ExistsInCategory), ctx, categoryID)
 }
 
 // GetByID mocks base method.
@@ -98,3 +141,148 @@ func (mr *MockProductPortMockRecorder) GetByID(ctx, id any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockProductPort)(nil).GetByID), ctx, id)
 }
+
+// GetByIDs mocks base method.
+func (m *MockProductPort) GetByIDs(ctx context.Context, ids []domain.ID) ([]*domain.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIDs", ctx, ids)
+	ret0, _ := ret[0].([]*domain.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIDs indicates an expected call of GetByIDs.
+func (mr *MockProductPortMockRecorder) GetByIDs(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIDs", reflect.TypeOf((*MockProductPort)(nil).GetByIDs), ctx, ids)
+}
+
+// List mocks base method.
+func (m *MockProductPort) List(ctx context.Context, filter port.ProductFilter, page port.PageRequest) (*port.Page[*domain.Product], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter, page)
+	ret0, _ := ret[0].(*port.Page[*domain.Product])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockProductPortMockRecorder) List(ctx, filter, page any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockProductPort)(nil).List), ctx, filter, page)
+}
+
+// ReleaseReservedStock mocks base method.
+func (m *MockProductPort) ReleaseReservedStock(ctx context.Context, id domain.ID, sku string, quantity int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseReservedStock", ctx, id, sku, quantity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseReservedStock indicates an expected call of ReleaseReservedStock.
+func (mr *MockProductPortMockRecorder) ReleaseReservedStock(ctx, id, sku, quantity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseReservedStock", reflect.TypeOf((*MockProductPort)(nil).ReleaseReservedStock), ctx, id, sku, quantity)
+}
+
+// ReserveStock mocks base method.
+func (m *MockProductPort) ReserveStock(ctx context.Context, id domain.ID, sku string, quantity int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveStock", ctx, id, sku, quantity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReserveStock indicates an expected call of ReserveStock.
+func (mr *MockProductPortMockRecorder) ReserveStock(ctx, id, sku, quantity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveStock", reflect.TypeOf((*MockProductPort)(nil).ReserveStock), ctx, id, sku, quantity)
+}
+
+// ReserveStockBatch mocks base method.
+func (m *MockProductPort) ReserveStockBatch(ctx context.Context, lines []port.StockLine, alert port.StockAlertBuilder) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveStockBatch", ctx, lines, alert)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReserveStockBatch indicates an expected call of ReserveStockBatch.
+func (mr *MockProductPortMockRecorder) ReserveStockBatch(ctx, lines, alert any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveStockBatch", reflect.TypeOf((*MockProductPort)(nil).ReserveStockBatch), ctx, lines, alert)
+}
+
+// RestoreStock mocks base method.
+func (m *MockProductPort) RestoreStock(ctx context.Context, id domain.ID, sku string, quantity int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreStock", ctx, id, sku, quantity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreStock indicates an expected call of RestoreStock.
+func (mr *MockProductPortMockRecorder) RestoreStock(ctx, id, sku, quantity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreStock", reflect.TypeOf((*MockProductPort)(nil).RestoreStock), ctx, id, sku, quantity)
+}
+
+// Search mocks base method.
+func (m *MockProductPort) Search(ctx context.Context, query string, page port.PageRequest) (*port.Page[*domain.Product], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, query, page)
+	ret0, _ := ret[0].(*port.Page[*domain.Product])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockProductPortMockRecorder) Search(ctx, query, page any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockProductPort)(nil).Search), ctx, query, page)
+}
+
+// Stream mocks base method.
+func (m *MockProductPort) Stream(ctx context.Context, fn func(*domain.Product) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stream", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Stream indicates an expected call of Stream.
+func (mr *MockProductPortMockRecorder) Stream(ctx, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stream", reflect.TypeOf((*MockProductPort)(nil).Stream), ctx, fn)
+}
+
+// Update mocks base method.
+func (m *MockProductPort) Update(ctx context.Context, id domain.ID, update port.ProductUpdate) (*domain.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, id, update)
+	ret0, _ := ret[0].(*domain.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockProductPortMockRecorder) Update(ctx, id, update any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockProductPort)(nil).Update), ctx, id, update)
+}
+
+// UpsertBySKU mocks base method.
+func (m *MockProductPort) UpsertBySKU(ctx context.Context, product *domain.Product) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertBySKU", ctx, product)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertBySKU indicates an expected call of UpsertBySKU.
+func (mr *MockProductPortMockRecorder) UpsertBySKU(ctx, product any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertBySKU", reflect.TypeOf((*MockProductPort)(nil).UpsertBySKU), ctx, product)
+}
@@ -0,0 +1,101 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: product_import.go
+//
+// Generated by this command:
+//
+//	mockgen -source=product_import.go -destination=mock/product_import.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/rafaelleal24/challenge/internal/core/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockProductImportPort is a mock of ProductImportPort interface.
+type MockProductImportPort struct {
+	ctrl     *gomock.Controller
+	recorder *MockProductImportPortMockRecorder
+	isgomock struct{}
+}
+
+// MockProductImportPortMockRecorder is the mock recorder for MockProductImportPort.
+type MockProductImportPortMockRecorder struct {
+	mock *MockProductImportPort
+}
+
+// NewMockProductImportPort creates a new mock instance.
+func NewMockProductImportPort(ctrl *gomock.Controller) *MockProductImportPort {
+	mock := &MockProductImportPort{ctrl: ctrl}
+	mock.recorder = &MockProductImportPortMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProductImportPort) EXPECT() *MockProductImportPortMockRecorder {
+	return m.recorder
+}
+
+// ClaimNext mocks base method.
+func (m *MockProductImportPort) ClaimNext(ctx context.Context, staleBefore time.Time) (*domain.ProductImport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimNext", ctx, staleBefore)
+	ret0, _ := ret[0].(*domain.ProductImport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimNext indicates an expected call of ClaimNext.
+func (mr *MockProductImportPortMockRecorder) ClaimNext(ctx, staleBefore any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimNext", reflect.TypeOf((*MockProductImportPort)(nil).ClaimNext), ctx, staleBefore)
+}
+
+// Create mocks base method.
+func (m *MockProductImportPort) Create(ctx context.Context, productImport *domain.ProductImport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, productImport)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockProductImportPortMockRecorder) Create(ctx, productImport any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockProductImportPort)(nil).Create), ctx, productImport)
+}
+
+// GetByID mocks base method.
+func (m *MockProductImportPort) GetByID(ctx context.Context, id domain.ID) (*domain.ProductImport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*domain.ProductImport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockProductImportPortMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockProductImportPort)(nil).GetByID), ctx, id)
+}
+
+// SaveProgress mocks base method.
+func (m *MockProductImportPort) SaveProgress(ctx context.Context, productImport *domain.ProductImport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveProgress", ctx, productImport)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveProgress indicates an expected call of SaveProgress.
+func (mr *MockProductImportPortMockRecorder) SaveProgress(ctx, productImport any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveProgress", reflect.TypeOf((*MockProductImportPort)(nil).SaveProgress), ctx, productImport)
+}
@@ -0,0 +1,101 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: reservation.go
+//
+// Generated by this command:
+//
+//	mockgen -source=reservation.go -destination=mock/reservation.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/rafaelleal24/challenge/internal/core/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReservationPort is a mock of ReservationPort interface.
+type MockReservationPort struct {
+	ctrl     *gomock.Controller
+	recorder *MockReservationPortMockRecorder
+	isgomock struct{}
+}
+
+// MockReservationPortMockRecorder is the mock recorder for MockReservationPort.
+type MockReservationPortMockRecorder struct {
+	mock *MockReservationPort
+}
+
+// NewMockReservationPort creates a new mock instance.
+func NewMockReservationPort(ctrl *gomock.Controller) *MockReservationPort {
+	mock := &MockReservationPort{ctrl: ctrl}
+	mock.recorder = &MockReservationPortMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReservationPort) EXPECT() *MockReservationPortMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockReservationPort) Create(ctx context.Context, reservations []*domain.Reservation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, reservations)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockReservationPortMockRecorder) Create(ctx, reservations any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockReservationPort)(nil).Create), ctx, reservations)
+}
+
+// GetByOrderID mocks base method.
+func (m *MockReservationPort) GetByOrderID(ctx context.Context, orderID domain.ID) ([]*domain.Reservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByOrderID", ctx, orderID)
+	ret0, _ := ret[0].([]*domain.Reservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByOrderID indicates an expected call of GetByOrderID.
+func (mr *MockReservationPortMockRecorder) GetByOrderID(ctx, orderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByOrderID", reflect.TypeOf((*MockReservationPort)(nil).GetByOrderID), ctx, orderID)
+}
+
+// GetExpired mocks base method.
+func (m *MockReservationPort) GetExpired(ctx context.Context, now time.Time, limit int64) ([]*domain.Reservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpired", ctx, now, limit)
+	ret0, _ := ret[0].([]*domain.Reservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpired indicates an expected call of GetExpired.
+func (mr *MockReservationPortMockRecorder) GetExpired(ctx, now, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpired", reflect.TypeOf((*MockReservationPort)(nil).GetExpired), ctx, now, limit)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockReservationPort) UpdateStatus(ctx context.Context, id domain.ID, from, to domain.ReservationStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, id, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockReservationPortMockRecorder) UpdateStatus(ctx, id, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockReservationPort)(nil).UpdateStatus), ctx, id, from, to)
+}
@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: stock_movement.go
+//
+// Generated by this command:
+//
+//	mockgen -source=stock_movement.go -destination=mock/stock_movement.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/rafaelleal24/challenge/internal/core/domain"
+	port "github.com/rafaelleal24/challenge/internal/core/port"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockStockMovementPort is a mock of StockMovementPort interface.
+type MockStockMovementPort struct {
+	ctrl     *gomock.Controller
+	recorder *MockStockMovementPortMockRecorder
+	isgomock struct{}
+}
+
+// MockStockMovementPortMockRecorder is the mock recorder for MockStockMovementPort.
+type MockStockMovementPortMockRecorder struct {
+	mock *MockStockMovementPort
+}
+
+// NewMockStockMovementPort creates a new mock instance.
+func NewMockStockMovementPort(ctrl *gomock.Controller) *MockStockMovementPort {
+	mock := &MockStockMovementPort{ctrl: ctrl}
+	mock.recorder = &MockStockMovementPortMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStockMovementPort) EXPECT() *MockStockMovementPortMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockStockMovementPort) Create(ctx context.Context, movement *domain.StockMovement) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, movement)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockStockMovementPortMockRecorder) Create(ctx, movement any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockStockMovementPort)(nil).Create), ctx, movement)
+}
+
+// GetByProductID mocks base method.
+func (m *MockStockMovementPort) GetByProductID(ctx context.Context, productID domain.ID, limit int64, cursor string) (*port.Page[*domain.StockMovement], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByProductID", ctx, productID, limit, cursor)
+	ret0, _ := ret[0].(*port.Page[*domain.StockMovement])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByProductID indicates an expected call of GetByProductID.
+func (mr *MockStockMovementPortMockRecorder) GetByProductID(ctx, productID, limit, cursor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByProductID", reflect.TypeOf((*MockStockMovementPort)(nil).GetByProductID), ctx, productID, limit, cursor)
+}
@@ -2,17 +2,40 @@ package port
 
 import (
 	"context"
+	"time"
 
 	"github.com/rafaelleal24/challenge/internal/core/domain"
 )
 
+const (
+	OrderSortCreatedAt   = "created_at"
+	OrderSortTotalAmount = "total_amount"
+)
+
+type OrderFilter struct {
+	CustomerID    domain.ID
+	Status        domain.OrderStatus
+	CreatedFrom   time.Time
+	CreatedTo     time.Time
+	CreatedBefore time.Time // exclusive, unlike CreatedTo
+	MinTotal      *domain.Amount
+	MaxTotal      *domain.Amount
+}
+
 //go:generate mockgen -source=$GOFILE -destination=mock/$GOFILE -package=mock
 
 type OrderPort interface {
 	Create(ctx context.Context, order *domain.Order) error
+	CreateWithOutbox(ctx context.Context, order *domain.Order, buildEvent func(order *domain.Order) domain.Event) error
 	GetByID(ctx context.Context, id domain.ID) (*domain.Order, error)
-	GetByCustomerID(ctx context.Context, customerID domain.ID, limit, offset int64) ([]*domain.Order, error)
-	GetByStatus(ctx context.Context, status domain.OrderStatus, limit, offset int64) ([]*domain.Order, error)
-	UpdateStatusWithOutbox(ctx context.Context, id domain.ID, status domain.OrderStatus, event domain.Event) error
+	// GetByCustomerID pages through a customer's orders newest first, only those in status
+	// when it is set.
+	GetByCustomerID(ctx context.Context, customerID domain.ID, status domain.OrderStatus, limit int64, cursor string) (*Page[*domain.Order], error)
+	GetByStatus(ctx context.Context, status domain.OrderStatus, limit int64, cursor string) (*Page[*domain.Order], error)
+	List(ctx context.Context, filter OrderFilter, page PageRequest) (*Page[*domain.Order], error)
+	UpdateStatusWithOutbox(ctx context.Context, id domain.ID, expectedVersion int64, change domain.OrderStatusChange, event domain.Event) error
 	Delete(ctx context.Context, id domain.ID) error
+	// EraseShippingAddresses strips the shipping addresses of the customer's orders down to
+	// the country, which is kept for accounting.
+	EraseShippingAddresses(ctx context.Context, customerID domain.ID) error
 }
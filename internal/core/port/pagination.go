@@ -0,0 +1,15 @@
+package port
+
+type PageRequest struct {
+	Limit        int64
+	Cursor       string
+	SortBy       string
+	Descending   bool
+	IncludeTotal bool
+}
+
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+	Total      *int64
+}
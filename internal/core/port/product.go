@@ -8,9 +8,87 @@ import (
 
 //go:generate mockgen -source=$GOFILE -destination=mock/$GOFILE -package=mock
 
+const (
+	ProductSortName      = "name"
+	ProductSortPrice     = "price"
+	ProductSortCreatedAt = "created_at"
+)
+
+// ProductFilter narrows a product listing; zero-valued fields are ignored.
+// Archived products are never listed.
+type ProductFilter struct {
+	// NamePrefix matches the start of the name, case-sensitively, so the name index can be used.
+	NamePrefix string
+	MinPrice   *domain.Amount
+	MaxPrice   *domain.Amount
+	// InStockOnly keeps products with stock not held by reservations.
+	InStockOnly bool
+	// CategoryIDs keeps products in any of the categories; callers expand a category to its
+	// subtree so products of subcategories are listed too.
+	CategoryIDs []domain.ID
+	// Tag keeps products carrying the tag, which is matched as normalized by domain.NormalizeTags.
+	Tag string
+}
+
+// ProductUpdate holds the product fields to change; nil fields are left untouched.
+type ProductUpdate struct {
+	Name              *string
+	Description       *string
+	Price             *domain.Amount
+	LowStockThreshold *int
+	// CategoryID set to an empty ID removes the product from its category.
+	CategoryID *domain.ID
+	Tags       *[]string
+}
+
+// StockAlertBuilder builds the event for a change that lowered the available stock of the
+// variant with the given SKU, or of the product when sku is empty, from the product as it is
+// after the change, or returns nil when there is nothing to publish.
+type StockAlertBuilder func(product *domain.Product, sku string) domain.Event
+
+// StockLine is the quantity of one product, or of its variant SKU, changed by a batch stock
+// operation.
+type StockLine struct {
+	ProductID domain.ID
+	SKU       string
+	Quantity  int
+}
+
 type ProductPort interface {
 	Create(ctx context.Context, product *domain.Product) error
 	GetByID(ctx context.Context, id domain.ID) (*domain.Product, error)
-	GetAll(ctx context.Context) ([]*domain.Product, error)
-	DeductStock(ctx context.Context, id domain.ID, quantity int) error
+	// GetByIDs returns the products found among ids, in no particular order; missing IDs are skipped.
+	GetByIDs(ctx context.Context, ids []domain.ID) ([]*domain.Product, error)
+	List(ctx context.Context, filter ProductFilter, page PageRequest) (*Page[*domain.Product], error)
+	// Search matches name and description, most relevant first; page sort fields are ignored.
+	Search(ctx context.Context, query string, page PageRequest) (*Page[*domain.Product], error)
+	Update(ctx context.Context, id domain.ID, update ProductUpdate) (*domain.Product, error)
+	Archive(ctx context.Context, id domain.ID) error
+	// UpsertBySKU creates the product, or updates the catalogue fields of the product or
+	// variant that has its SKU: a variant takes the name and price, its product the other
+	// fields. A zero price leaves a product's price as it is and makes a variant sell at its
+	// product's price; a product cannot be created without one. SKUs of archived products
+	// are rejected with a conflict. Stock is only set when the product is created; existing
+	// stock changes through adjustments so it stays accounted for.
+	UpsertBySKU(ctx context.Context, product *domain.Product) (created bool, err error)
+	// Stream calls fn for every product that is not archived, oldest first, stopping at the
+	// first error.
+	Stream(ctx context.Context, fn func(product *domain.Product) error) error
+	// ExistsInCategory reports whether any product, archived or not, is in the category.
+	ExistsInCategory(ctx context.Context, categoryID domain.ID) (bool, error)
+	// The stock changes apply to the variant sku, keeping the product totals in step, or to
+	// the product itself when sku is empty. DeductStock writes the event built by alert to the
+	// outbox in the same transaction as the deduction. Committing reserved units leaves the
+	// available stock as it is, so it raises no alert.
+	DeductStock(ctx context.Context, id domain.ID, sku string, quantity int, alert StockAlertBuilder) error
+	RestoreStock(ctx context.Context, id domain.ID, sku string, quantity int) error
+	ReserveStock(ctx context.Context, id domain.ID, sku string, quantity int) error
+	ReleaseReservedStock(ctx context.Context, id domain.ID, sku string, quantity int) error
+	CommitReservedStock(ctx context.Context, id domain.ID, sku string, quantity int) error
+	// ReserveStockBatch and DeductStockBatch change the stock of every line or of none, writing
+	// the events built by alert, one per product and SKU, to the outbox. When products lack available
+	// stock they fail with an unprocessable entity error whose details list a
+	// domain.StockShortage for each of them.
+	ReserveStockBatch(ctx context.Context, lines []StockLine, alert StockAlertBuilder) error
+	DeductStockBatch(ctx context.Context, lines []StockLine, alert StockAlertBuilder) error
 }
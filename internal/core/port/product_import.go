@@ -0,0 +1,25 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mock/$GOFILE -package=mock
+
+type ProductImportPort interface {
+	Create(ctx context.Context, productImport *domain.ProductImport) error
+	// GetByID returns the import without its rows, which only the worker applying it needs.
+	GetByID(ctx context.Context, id domain.ID) (*domain.ProductImport, error)
+	// ClaimNext marks the oldest pending import as running under a new lease and returns it
+	// with its rows. A running import whose progress was last saved before staleBefore is
+	// claimed again, as its worker is presumed gone. It returns nil when there is nothing to do.
+	ClaimNext(ctx context.Context, staleBefore time.Time) (*domain.ProductImport, error)
+	// SaveProgress stores the status, counters and errors of a running import. Rows are
+	// dropped once it completes. It fails with a conflict when the import is no longer running
+	// under the lease it was claimed with, so a worker presumed gone cannot overwrite the
+	// progress of the one that took over.
+	SaveProgress(ctx context.Context, productImport *domain.ProductImport) error
+}
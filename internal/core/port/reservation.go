@@ -0,0 +1,17 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mock/$GOFILE -package=mock
+
+type ReservationPort interface {
+	Create(ctx context.Context, reservations []*domain.Reservation) error
+	GetByOrderID(ctx context.Context, orderID domain.ID) ([]*domain.Reservation, error)
+	GetExpired(ctx context.Context, now time.Time, limit int64) ([]*domain.Reservation, error)
+	UpdateStatus(ctx context.Context, id domain.ID, from, to domain.ReservationStatus) error
+}
@@ -0,0 +1,15 @@
+package port
+
+import (
+	"context"
+
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mock/$GOFILE -package=mock
+
+type StockMovementPort interface {
+	Create(ctx context.Context, movement *domain.StockMovement) error
+	// GetByProductID returns the movements of a product, newest first.
+	GetByProductID(ctx context.Context, productID domain.ID, limit int64, cursor string) (*Page[*domain.StockMovement], error)
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/dto"
+	"github.com/rafaelleal24/challenge/internal/core/logger"
+	"github.com/rafaelleal24/challenge/internal/core/port"
+	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
+)
+
+// CategoryService keeps the category tree acyclic and stops categories from being deleted
+// while anything points at them. Checks run in the same transaction as the change they guard,
+// and every change locks the categories it depends on (see port.CategoryPort.Lock), so two
+// concurrent changes that are each valid alone cannot both commit.
+type CategoryService struct {
+	categoryRepository port.CategoryPort
+	productRepository  port.ProductPort
+	txManager          port.TransactionManager
+}
+
+func NewCategoryService(categoryRepository port.CategoryPort, productRepository port.ProductPort, txManager port.TransactionManager) *CategoryService {
+	return &CategoryService{
+		categoryRepository: categoryRepository,
+		productRepository:  productRepository,
+		txManager:          txManager,
+	}
+}
+
+func (s *CategoryService) CreateCategory(ctx context.Context, request *dto.CreateCategoryRequest) (*domain.Category, error) {
+	if request.ParentID != "" && !domain.ValidateID(string(request.ParentID)) {
+		return nil, serviceerrors.NewInvalidRequestError("invalid parent category ID")
+	}
+
+	category := domain.NewCategory(request.Name, request.ParentID)
+	err := s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		if request.ParentID != "" {
+			if err := s.categoryRepository.Lock(txCtx, request.ParentID); err != nil {
+				return parentNotFound(err, request.ParentID)
+			}
+		}
+		return s.categoryRepository.Create(txCtx, category)
+	})
+	if err != nil {
+		if serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			return nil, serviceerrors.NewConflictError(fmt.Sprintf("a category named %q already exists at this level", request.Name))
+		}
+		return nil, err
+	}
+
+	logger.Info(ctx, "Category created", map[string]any{"category_id": category.ID})
+	return category, nil
+}
+
+func (s *CategoryService) GetByID(ctx context.Context, id domain.ID) (*domain.Category, error) {
+	category, err := s.categoryRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, categoryNotFound(err, id)
+	}
+	return category, nil
+}
+
+func (s *CategoryService) ListCategories(ctx context.Context) ([]*domain.Category, error) {
+	return s.categoryRepository.List(ctx)
+}
+
+// UpdateCategory renames a category or moves it, with its subcategories, under another parent.
+// A category cannot be moved under itself or one of its own subcategories. The move locks
+// the new parent and every category above it, so a concurrent move that would close a cycle
+// through them conflicts instead of committing alongside.
+func (s *CategoryService) UpdateCategory(ctx context.Context, id domain.ID, request *dto.UpdateCategoryRequest) (*domain.Category, error) {
+	if request.Name == nil && request.ParentID == nil {
+		return nil, serviceerrors.NewInvalidRequestError("at least one field must be provided")
+	}
+	if request.ParentID != nil && *request.ParentID != "" && !domain.ValidateID(string(*request.ParentID)) {
+		return nil, serviceerrors.NewInvalidRequestError("invalid parent category ID")
+	}
+
+	var category *domain.Category
+	err := s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		if request.ParentID != nil && *request.ParentID != "" {
+			if err := s.checkMove(txCtx, id, *request.ParentID); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		category, err = s.categoryRepository.Update(txCtx, id, port.CategoryUpdate{
+			Name:     request.Name,
+			ParentID: request.ParentID,
+		})
+		if serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			return serviceerrors.NewConflictError("a category with this name already exists at this level")
+		}
+		return categoryNotFound(err, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info(ctx, "Category updated", map[string]any{"category_id": id})
+	return category, nil
+}
+
+// DeleteCategory removes an empty category. Categories that still hold subcategories or
+// products, archived ones included, must be emptied first so nothing is left pointing at it.
+// Subcategories and products are only placed in a category after locking it, so they
+// cannot be added between the checks and the delete.
+func (s *CategoryService) DeleteCategory(ctx context.Context, id domain.ID) error {
+	err := s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		categories, err := s.categoryRepository.List(txCtx)
+		if err != nil {
+			return err
+		}
+		tree := domain.NewCategoryTree(categories)
+		if tree.Get(id) == nil {
+			return serviceerrors.NewNotFoundError(fmt.Sprintf("category %s not found", id))
+		}
+		if tree.HasChildren(id) {
+			return serviceerrors.NewConflictError(fmt.Sprintf("category %s has subcategories", id))
+		}
+
+		hasProducts, err := s.productRepository.ExistsInCategory(txCtx, id)
+		if err != nil {
+			return err
+		}
+		if hasProducts {
+			return serviceerrors.NewConflictError(fmt.Sprintf("category %s has products", id))
+		}
+
+		return categoryNotFound(s.categoryRepository.Delete(txCtx, id), id)
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info(ctx, "Category deleted", map[string]any{"category_id": id})
+	return nil
+}
+
+// checkMove checks that id can be moved under parentID and locks parentID and its ancestors.
+func (s *CategoryService) checkMove(ctx context.Context, id, parentID domain.ID) error {
+	categories, err := s.categoryRepository.List(ctx)
+	if err != nil {
+		return err
+	}
+	tree := domain.NewCategoryTree(categories)
+	if tree.Get(id) == nil {
+		return serviceerrors.NewNotFoundError(fmt.Sprintf("category %s not found", id))
+	}
+	if tree.Get(parentID) == nil {
+		return serviceerrors.NewNotFoundError(fmt.Sprintf("parent category %s not found", parentID))
+	}
+	if tree.IsWithin(parentID, id) {
+		return serviceerrors.NewUnprocessableEntityError("a category cannot be moved under itself or its subcategories")
+	}
+
+	for _, ancestorID := range tree.Ancestry(parentID) {
+		if err := s.categoryRepository.Lock(ctx, ancestorID); err != nil {
+			return parentNotFound(err, parentID)
+		}
+	}
+	return nil
+}
+
+func parentNotFound(err error, parentID domain.ID) error {
+	if serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+		return serviceerrors.NewNotFoundError(fmt.Sprintf("parent category %s not found", parentID))
+	}
+	return err
+}
+
+// categoryNotFound names the category in not-found errors from the repository, which only
+// reports a missing entity.
+func categoryNotFound(err error, id domain.ID) error {
+	if serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+		return serviceerrors.NewNotFoundError(fmt.Sprintf("category %s not found", id))
+	}
+	return err
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/dto"
+	"github.com/rafaelleal24/challenge/internal/core/port"
+	"github.com/rafaelleal24/challenge/internal/core/port/mock"
+	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
+	"go.uber.org/mock/gomock"
+)
+
+type categoryMocks struct {
+	categoryRepo *mock.MockCategoryPort
+	productRepo  *mock.MockProductPort
+}
+
+func setupCategoryService(t *testing.T) (*CategoryService, *categoryMocks) {
+	ctrl := gomock.NewController(t)
+	categoryRepo := mock.NewMockCategoryPort(ctrl)
+	productRepo := mock.NewMockProductPort(ctrl)
+	txManager := mock.NewMockTransactionManager(ctrl)
+	txManager.EXPECT().
+		WithTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		}).
+		AnyTimes()
+	svc := NewCategoryService(categoryRepo, productRepo, txManager)
+	return svc, &categoryMocks{
+		categoryRepo: categoryRepo,
+		productRepo:  productRepo,
+	}
+}
+
+var (
+	clothingID = domain.ID("aabbccddee112233aabbcc01")
+	shirtsID   = domain.ID("aabbccddee112233aabbcc02")
+	booksID    = domain.ID("aabbccddee112233aabbcc03")
+)
+
+// testCategories is a small tree: Clothing > Shirts, and Books at the top level.
+func testCategories() []*domain.Category {
+	return []*domain.Category{
+		{ID: booksID, Name: "Books"},
+		{ID: clothingID, Name: "Clothing"},
+		{ID: shirtsID, Name: "Shirts", ParentID: clothingID},
+	}
+}
+
+func TestCategoryService_CreateCategory(t *testing.T) {
+	t.Run("under a parent", func(t *testing.T) {
+		svc, m := setupCategoryService(t)
+
+		m.categoryRepo.EXPECT().Lock(gomock.Any(), clothingID).Return(nil)
+		m.categoryRepo.EXPECT().
+			Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, category *domain.Category) error {
+				if category.Name != "Shoes" || category.ParentID != clothingID {
+					t.Fatalf("unexpected category %+v", category)
+				}
+				category.ID = shirtsID
+				return nil
+			})
+
+		category, err := svc.CreateCategory(context.Background(), &dto.CreateCategoryRequest{Name: "Shoes", ParentID: clothingID})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if category.ID != shirtsID {
+			t.Fatalf("expected id %s, got %s", shirtsID, category.ID)
+		}
+	})
+
+	t.Run("unknown parent", func(t *testing.T) {
+		svc, m := setupCategoryService(t)
+
+		m.categoryRepo.EXPECT().Lock(gomock.Any(), clothingID).Return(serviceerrors.NewNotFoundError("entity not found"))
+
+		_, err := svc.CreateCategory(context.Background(), &dto.CreateCategoryRequest{Name: "Shoes", ParentID: clothingID})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+
+	t.Run("duplicate sibling name", func(t *testing.T) {
+		svc, m := setupCategoryService(t)
+
+		m.categoryRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(serviceerrors.NewConflictError("duplicate key error"))
+
+		_, err := svc.CreateCategory(context.Background(), &dto.CreateCategoryRequest{Name: "Books"})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			t.Fatalf("expected KindConflict, got %v", err)
+		}
+	})
+}
+
+func TestCategoryService_UpdateCategory(t *testing.T) {
+	t.Run("moves the category", func(t *testing.T) {
+		svc, m := setupCategoryService(t)
+
+		m.categoryRepo.EXPECT().List(gomock.Any()).Return(testCategories(), nil)
+		m.categoryRepo.EXPECT().Lock(gomock.Any(), booksID).Return(nil)
+		m.categoryRepo.EXPECT().
+			Update(gomock.Any(), shirtsID, port.CategoryUpdate{ParentID: &booksID}).
+			Return(&domain.Category{ID: shirtsID, ParentID: booksID}, nil)
+
+		category, err := svc.UpdateCategory(context.Background(), shirtsID, &dto.UpdateCategoryRequest{ParentID: &booksID})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if category.ParentID != booksID {
+			t.Fatalf("expected parent %s, got %s", booksID, category.ParentID)
+		}
+	})
+
+	t.Run("locks the new parent and every category above it", func(t *testing.T) {
+		svc, m := setupCategoryService(t)
+
+		m.categoryRepo.EXPECT().List(gomock.Any()).Return(testCategories(), nil)
+		gomock.InOrder(
+			m.categoryRepo.EXPECT().Lock(gomock.Any(), shirtsID).Return(nil),
+			m.categoryRepo.EXPECT().Lock(gomock.Any(), clothingID).Return(nil),
+			m.categoryRepo.EXPECT().
+				Update(gomock.Any(), booksID, port.CategoryUpdate{ParentID: &shirtsID}).
+				Return(&domain.Category{ID: booksID, ParentID: shirtsID}, nil),
+		)
+
+		if _, err := svc.UpdateCategory(context.Background(), booksID, &dto.UpdateCategoryRequest{ParentID: &shirtsID}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("parent deleted while moving", func(t *testing.T) {
+		svc, m := setupCategoryService(t)
+
+		m.categoryRepo.EXPECT().List(gomock.Any()).Return(testCategories(), nil)
+		m.categoryRepo.EXPECT().Lock(gomock.Any(), booksID).Return(serviceerrors.NewNotFoundError("entity not found"))
+
+		_, err := svc.UpdateCategory(context.Background(), shirtsID, &dto.UpdateCategoryRequest{ParentID: &booksID})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+
+	t.Run("rejects moving under a subcategory", func(t *testing.T) {
+		svc, m := setupCategoryService(t)
+
+		m.categoryRepo.EXPECT().List(gomock.Any()).Return(testCategories(), nil)
+
+		_, err := svc.UpdateCategory(context.Background(), clothingID, &dto.UpdateCategoryRequest{ParentID: &shirtsID})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindUnprocessableEntity) {
+			t.Fatalf("expected KindUnprocessableEntity, got %v", err)
+		}
+	})
+
+	t.Run("rejects moving under itself", func(t *testing.T) {
+		svc, m := setupCategoryService(t)
+
+		m.categoryRepo.EXPECT().List(gomock.Any()).Return(testCategories(), nil)
+
+		_, err := svc.UpdateCategory(context.Background(), booksID, &dto.UpdateCategoryRequest{ParentID: &booksID})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindUnprocessableEntity) {
+			t.Fatalf("expected KindUnprocessableEntity, got %v", err)
+		}
+	})
+
+	t.Run("moves to the top level without loading the tree", func(t *testing.T) {
+		svc, m := setupCategoryService(t)
+		var top domain.ID
+
+		m.categoryRepo.EXPECT().
+			Update(gomock.Any(), shirtsID, port.CategoryUpdate{ParentID: &top}).
+			Return(&domain.Category{ID: shirtsID}, nil)
+
+		if _, err := svc.UpdateCategory(context.Background(), shirtsID, &dto.UpdateCategoryRequest{ParentID: &top}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		svc, m := setupCategoryService(t)
+		name := "Renamed"
+
+		m.categoryRepo.EXPECT().Update(gomock.Any(), booksID, gomock.Any()).Return(nil, serviceerrors.NewNotFoundError("entity not found"))
+
+		_, err := svc.UpdateCategory(context.Background(), booksID, &dto.UpdateCategoryRequest{Name: &name})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+
+	t.Run("no fields", func(t *testing.T) {
+		svc, _ := setupCategoryService(t)
+
+		_, err := svc.UpdateCategory(context.Background(), booksID, &dto.UpdateCategoryRequest{})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+}
+
+func TestCategoryService_DeleteCategory(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		svc, m := setupCategoryService(t)
+
+		m.categoryRepo.EXPECT().List(gomock.Any()).Return(testCategories(), nil)
+		m.productRepo.EXPECT().ExistsInCategory(gomock.Any(), shirtsID).Return(false, nil)
+		m.categoryRepo.EXPECT().Delete(gomock.Any(), shirtsID).Return(nil)
+
+		if err := svc.DeleteCategory(context.Background(), shirtsID); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("has subcategories", func(t *testing.T) {
+		svc, m := setupCategoryService(t)
+
+		m.categoryRepo.EXPECT().List(gomock.Any()).Return(testCategories(), nil)
+
+		err := svc.DeleteCategory(context.Background(), clothingID)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			t.Fatalf("expected KindConflict, got %v", err)
+		}
+	})
+
+	t.Run("has products", func(t *testing.T) {
+		svc, m := setupCategoryService(t)
+
+		m.categoryRepo.EXPECT().List(gomock.Any()).Return(testCategories(), nil)
+		m.productRepo.EXPECT().ExistsInCategory(gomock.Any(), booksID).Return(true, nil)
+
+		err := svc.DeleteCategory(context.Background(), booksID)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			t.Fatalf("expected KindConflict, got %v", err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		svc, m := setupCategoryService(t)
+
+		m.categoryRepo.EXPECT().List(gomock.Any()).Return(nil, nil)
+
+		err := svc.DeleteCategory(context.Background(), booksID)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+
+	t.Run("product lookup error", func(t *testing.T) {
+		svc, m := setupCategoryService(t)
+
+		m.categoryRepo.EXPECT().List(gomock.Any()).Return(testCategories(), nil)
+		m.productRepo.EXPECT().ExistsInCategory(gomock.Any(), booksID).Return(false, errors.New("db error"))
+
+		if err := svc.DeleteCategory(context.Background(), booksID); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
@@ -2,36 +2,271 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/dto"
 	"github.com/rafaelleal24/challenge/internal/core/logger"
 	"github.com/rafaelleal24/challenge/internal/core/port"
 	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
 )
 
+const (
+	customerExistsCacheTTL  = 1 * time.Minute
+	customerMissingCacheTTL = 10 * time.Second
+	// customerErasedCacheTTL outlasts any existence check that read the customer before the
+	// erasure, so the answer such a check caches cannot replace the erased entry.
+	customerErasedCacheTTL = 10 * time.Minute
+)
+
 type CustomerService struct {
 	customerRepository port.CustomerPort
+	existenceCache     port.CachePort[bool]
+}
+
+func NewCustomerService(customerRepository port.CustomerPort, existenceCache port.CachePort[bool]) *CustomerService {
+	return &CustomerService{
+		customerRepository: customerRepository,
+		existenceCache:     existenceCache,
+	}
 }
 
-func NewCustomerService(customerRepository port.CustomerPort) *CustomerService {
-	return &CustomerService{customerRepository: customerRepository}
+func customerExistsCacheKey(customerID domain.ID) string {
+	return "customer-exists:" + string(customerID)
+}
+
+func (s *CustomerService) CreateCustomer(ctx context.Context, request *dto.CreateCustomerRequest) (*domain.Customer, error) {
+	customer := domain.NewCustomer(request.Name, request.Email, request.Phone)
+	if customer.Name == "" {
+		return nil, serviceerrors.NewInvalidRequestError("name must not be blank")
+	}
+
+	if err := s.customerRepository.Create(ctx, customer); err != nil {
+		if serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			return nil, serviceerrors.NewConflictError(fmt.Sprintf("email %s is already in use", customer.Email))
+		}
+		return nil, err
+	}
+
+	logger.Info(ctx, "Customer created", map[string]any{"customer_id": customer.ID})
+	return customer, nil
 }
 
-func (s *CustomerService) Create(ctx context.Context) (domain.ID, error) {
-	return s.customerRepository.Create(ctx)
+func (s *CustomerService) GetByID(ctx context.Context, id domain.ID) (*domain.Customer, error) {
+	customer, err := s.customerRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, customerNotFound(err, id)
+	}
+	return customer, nil
 }
 
+// UpdateCustomer changes the given profile fields. The email is normalized as on creation and
+// must still be unique; an empty phone removes it.
+func (s *CustomerService) UpdateCustomer(ctx context.Context, id domain.ID, request *dto.UpdateCustomerRequest) (*domain.Customer, error) {
+	if request.Name == nil && request.Email == nil && request.Phone == nil {
+		return nil, serviceerrors.NewInvalidRequestError("at least one field must be provided")
+	}
+
+	update := port.CustomerUpdate{Phone: request.Phone}
+	if request.Name != nil {
+		name := strings.TrimSpace(*request.Name)
+		if name == "" {
+			return nil, serviceerrors.NewInvalidRequestError("name must not be blank")
+		}
+		update.Name = &name
+	}
+	if request.Email != nil {
+		email := domain.NormalizeEmail(*request.Email)
+		update.Email = &email
+	}
+
+	customer, err := s.customerRepository.Update(ctx, id, update)
+	if err != nil {
+		if update.Email != nil && serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			return nil, serviceerrors.NewConflictError(fmt.Sprintf("email %s is already in use", *update.Email))
+		}
+		return nil, customerNotFound(err, id)
+	}
+
+	logger.Info(ctx, "Customer updated", map[string]any{"customer_id": id})
+	return customer, nil
+}
+
+// AddAddress saves an address in the customer's address book. The first address saved becomes
+// the default, as does any address saved with Default set.
+func (s *CustomerService) AddAddress(ctx context.Context, customerID domain.ID, request *dto.SaveAddressRequest) (*domain.Customer, *domain.CustomerAddress, error) {
+	address := &domain.CustomerAddress{
+		Label:   strings.TrimSpace(request.Label),
+		Address: newAddress(request.AddressRequest),
+	}
+	customer, err := s.customerRepository.AddAddress(ctx, customerID, address, request.Default)
+	if err != nil {
+		return nil, nil, customerNotFound(err, customerID)
+	}
+
+	logger.Info(ctx, "Customer address added", map[string]any{
+		"customer_id": customerID,
+		"address_id":  address.ID,
+	})
+	return customer, customer.FindAddress(address.ID), nil
+}
+
+// ReplaceAddress overwrites a saved address. Orders already placed keep the copy they were
+// given, so they are not affected. Default makes it the default address; the default can
+// only be moved to another address, not cleared.
+func (s *CustomerService) ReplaceAddress(ctx context.Context, customerID, addressID domain.ID, request *dto.SaveAddressRequest) (*domain.Customer, *domain.CustomerAddress, error) {
+	if err := s.checkAddress(ctx, customerID, addressID); err != nil {
+		return nil, nil, err
+	}
+
+	address := domain.CustomerAddress{
+		ID:      addressID,
+		Label:   strings.TrimSpace(request.Label),
+		Address: newAddress(request.AddressRequest),
+	}
+	customer, err := s.customerRepository.ReplaceAddress(ctx, customerID, address, request.Default)
+	if err != nil {
+		return nil, nil, addressNotFound(err, customerID, addressID)
+	}
+
+	logger.Info(ctx, "Customer address replaced", map[string]any{
+		"customer_id": customerID,
+		"address_id":  addressID,
+	})
+	return customer, customer.FindAddress(addressID), nil
+}
+
+// RemoveAddress deletes a saved address. When it was the default, the first address left
+// becomes the default.
+func (s *CustomerService) RemoveAddress(ctx context.Context, customerID, addressID domain.ID) error {
+	if err := s.checkAddress(ctx, customerID, addressID); err != nil {
+		return err
+	}
+
+	if _, err := s.customerRepository.RemoveAddress(ctx, customerID, addressID); err != nil {
+		return addressNotFound(err, customerID, addressID)
+	}
+
+	logger.Info(ctx, "Customer address removed", map[string]any{
+		"customer_id": customerID,
+		"address_id":  addressID,
+	})
+	return nil
+}
+
+// SavedAddress returns a copy of one of the customer's saved addresses, for an order to keep.
+func (s *CustomerService) SavedAddress(ctx context.Context, customerID, addressID domain.ID) (*domain.Address, error) {
+	customer, err := s.GetByID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	saved := customer.FindAddress(addressID)
+	if saved == nil {
+		return nil, newAddressNotFoundError(customerID, addressID)
+	}
+	address := saved.Address
+	return &address, nil
+}
+
+// checkAddress reports which of the customer and the address is missing, since the repository
+// only reports that nothing matched both.
+func (s *CustomerService) checkAddress(ctx context.Context, customerID, addressID domain.ID) error {
+	if !domain.ValidateID(string(addressID)) {
+		return serviceerrors.NewInvalidRequestError("invalid address ID")
+	}
+	customer, err := s.GetByID(ctx, customerID)
+	if err != nil {
+		return err
+	}
+	if customer.FindAddress(addressID) == nil {
+		return newAddressNotFoundError(customerID, addressID)
+	}
+	return nil
+}
+
+func newAddress(request dto.AddressRequest) domain.Address {
+	return domain.Address{
+		Recipient:  strings.TrimSpace(request.Recipient),
+		Line1:      strings.TrimSpace(request.Line1),
+		Line2:      strings.TrimSpace(request.Line2),
+		City:       strings.TrimSpace(request.City),
+		State:      strings.TrimSpace(request.State),
+		PostalCode: strings.TrimSpace(request.PostalCode),
+		Country:    request.Country,
+	}
+}
+
+// Exists returns a not-found error unless the customer exists and has not been erased. Answers
+// are cached briefly, misses for a shorter time than hits, so placing an order does not go to
+// the database every time. Repository errors are returned: an unverified customer never passes.
+// Answers are only cached when nothing is cached yet, so a check racing an erasure cannot
+// overwrite the entry the erasure leaves behind with a stale hit.
 func (s *CustomerService) Exists(ctx context.Context, id domain.ID) error {
-	_, err := s.customerRepository.Exists(ctx, id)
+	cached, err := s.existenceCache.Get(ctx, customerExistsCacheKey(id))
 	if err != nil {
-		if serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+		logger.Error(ctx, "cache: get customer existence failed", err, map[string]any{
+			"customer_id": id,
+		})
+	}
+	if cached != nil {
+		if !*cached {
 			return serviceerrors.NewNotFoundError("customer not found")
 		}
-		logger.Error(ctx, "customer: exists failed", err, map[string]any{
+		return nil
+	}
+
+	exists := true
+	ttl := customerExistsCacheTTL
+	if _, err := s.customerRepository.Exists(ctx, id); err != nil {
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			logger.Error(ctx, "customer: exists failed", err, map[string]any{
+				"customer_id": id,
+			})
+			return fmt.Errorf("customer existence check failed: %w", err)
+		}
+		exists = false
+		ttl = customerMissingCacheTTL
+	}
+
+	if _, err := s.existenceCache.SetNX(ctx, customerExistsCacheKey(id), &exists, ttl); err != nil {
+		logger.Error(ctx, "cache: set customer existence failed", err, map[string]any{
 			"customer_id": id,
 		})
-		return nil
 	}
 
+	if !exists {
+		return serviceerrors.NewNotFoundError("customer not found")
+	}
 	return nil
 }
+
+// customerNotFound names the customer in not-found errors from the repository, which only
+// reports a missing entity.
+// Lock makes the caller's transaction conflict with a concurrent erasure of the customer. It
+// fails with not found once the customer is erased, whatever the existence cache says.
+func (s *CustomerService) Lock(ctx context.Context, id domain.ID) error {
+	if err := s.customerRepository.Lock(ctx, id); err != nil {
+		return customerNotFound(err, id)
+	}
+	return nil
+}
+
+func customerNotFound(err error, id domain.ID) error {
+	if serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+		return serviceerrors.NewNotFoundError(fmt.Sprintf("customer %s not found", id))
+	}
+	return err
+}
+
+func addressNotFound(err error, customerID, addressID domain.ID) error {
+	if serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+		return newAddressNotFoundError(customerID, addressID)
+	}
+	return err
+}
+
+func newAddressNotFoundError(customerID, addressID domain.ID) error {
+	return serviceerrors.NewNotFoundError(fmt.Sprintf("address %s not found for customer %s", addressID, customerID))
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/logger"
+	"github.com/rafaelleal24/challenge/internal/core/port"
+	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
+)
+
+// CustomerDataService answers data subject requests: exporting everything stored about a
+// customer and erasing their personal data.
+type CustomerDataService struct {
+	customerRepository port.CustomerPort
+	orderRepository    port.OrderPort
+	orderCache         port.CachePort[domain.Order]
+	existenceCache     port.CachePort[bool]
+	idempotency        *IdempotencyService[domain.Order]
+	txManager          port.TransactionManager
+}
+
+func NewCustomerDataService(
+	customerRepository port.CustomerPort,
+	orderRepository port.OrderPort,
+	orderCache port.CachePort[domain.Order],
+	existenceCache port.CachePort[bool],
+	idempotency *IdempotencyService[domain.Order],
+	txManager port.TransactionManager,
+) *CustomerDataService {
+	return &CustomerDataService{
+		customerRepository: customerRepository,
+		orderRepository:    orderRepository,
+		orderCache:         orderCache,
+		existenceCache:     existenceCache,
+		idempotency:        idempotency,
+		txManager:          txManager,
+	}
+}
+
+// ExportCustomer returns the customer's profile together with all of their orders.
+func (s *CustomerDataService) ExportCustomer(ctx context.Context, id domain.ID) (*domain.CustomerExport, error) {
+	customer, err := s.customerRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, customerNotFound(err, id)
+	}
+
+	orders, err := s.customerOrders(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info(ctx, "Customer data exported", map[string]any{"customer_id": id})
+	return &domain.CustomerExport{
+		Customer:   customer,
+		Orders:     orders,
+		ExportedAt: time.Now(),
+	}, nil
+}
+
+// EraseCustomer removes the customer's personal data while keeping their orders for accounting:
+// the profile and address book are cleared, and the shipping addresses of their orders are
+// stripped down to the country. A customer.erased event tells other systems to do the same.
+// The orders are read back in the same transaction, so exactly the ones erased are then
+// evicted from the order cache and redacted in the responses kept for their idempotency keys.
+// The customer is cached as missing so they can no longer place orders; an order already
+// being placed locks the customer, so it either commits first and is erased with the others
+// or fails.
+// Erasing a customer twice is a no-op.
+func (s *CustomerDataService) EraseCustomer(ctx context.Context, id domain.ID) error {
+	customer, err := s.customerRepository.GetByID(ctx, id)
+	if err != nil {
+		return customerNotFound(err, id)
+	}
+	if customer.ErasedAt != nil {
+		return nil
+	}
+
+	erasedAt := time.Now()
+	var orders []*domain.Order
+	err = s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := s.orderRepository.EraseShippingAddresses(txCtx, id); err != nil {
+			return err
+		}
+		if err := s.customerRepository.Erase(txCtx, id, erasedAt, domain.NewCustomerErasedEvent(id, erasedAt)); err != nil {
+			return err
+		}
+		var err error
+		orders, err = s.customerOrders(txCtx, id)
+		return err
+	})
+	if err != nil {
+		if serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			// Erased concurrently by another request.
+			return nil
+		}
+		logger.Error(ctx, "transaction: erase customer failed", err, map[string]any{
+			"customer_id": id,
+		})
+		return err
+	}
+
+	// Overwriting rather than deleting the entry leaves a concurrent existence check that read
+	// the customer before the erasure nothing to fill in.
+	erased := false
+	if err := s.existenceCache.Set(ctx, customerExistsCacheKey(id), &erased, customerErasedCacheTTL); err != nil {
+		logger.Error(ctx, "cache: mark erased customer missing failed", err, map[string]any{
+			"customer_id": id,
+		})
+	}
+	for _, order := range orders {
+		if err := s.orderCache.Del(ctx, orderCacheKey(order.ID)); err != nil {
+			logger.Error(ctx, "cache: evict erased customer order failed", err, map[string]any{
+				"customer_id": id,
+				"order_id":    order.ID,
+			})
+		}
+		if order.IdempotencyKey == "" {
+			continue
+		}
+		if err := s.idempotency.ReplaceResult(ctx, order.IdempotencyKey, order); err != nil {
+			logger.Error(ctx, "idempotency: redact erased customer order failed", err, map[string]any{
+				"customer_id": id,
+				"order_id":    order.ID,
+			})
+		}
+	}
+
+	logger.Info(ctx, "Customer erased", map[string]any{"customer_id": id})
+	return nil
+}
+
+// customerOrders loads all of the customer's orders, newest first.
+func (s *CustomerDataService) customerOrders(ctx context.Context, id domain.ID) ([]*domain.Order, error) {
+	var orders []*domain.Order
+	cursor := ""
+	for {
+		page, err := s.orderRepository.GetByCustomerID(ctx, id, "", orderListMaxLimit, cursor)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, page.Items...)
+		if page.NextCursor == "" {
+			return orders, nil
+		}
+		cursor = page.NextCursor
+	}
+}
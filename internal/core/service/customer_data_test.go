@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/port"
+	"github.com/rafaelleal24/challenge/internal/core/port/mock"
+	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
+	"go.uber.org/mock/gomock"
+)
+
+type customerDataMocks struct {
+	customerRepo   *mock.MockCustomerPort
+	orderRepo      *mock.MockOrderPort
+	orderCache     *mock.MockCachePort[domain.Order]
+	existenceCache *mock.MockCachePort[bool]
+	idempotency    *mock.MockCachePort[IdempotencyEntry[domain.Order]]
+	txManager      *mock.MockTransactionManager
+}
+
+func setupCustomerDataService(t *testing.T) (*CustomerDataService, *customerDataMocks) {
+	ctrl := gomock.NewController(t)
+	mocks := &customerDataMocks{
+		customerRepo:   mock.NewMockCustomerPort(ctrl),
+		orderRepo:      mock.NewMockOrderPort(ctrl),
+		orderCache:     mock.NewMockCachePort[domain.Order](ctrl),
+		existenceCache: mock.NewMockCachePort[bool](ctrl),
+		idempotency:    mock.NewMockCachePort[IdempotencyEntry[domain.Order]](ctrl),
+		txManager:      mock.NewMockTransactionManager(ctrl),
+	}
+	idempotency := NewIdempotencyService(mocks.idempotency, time.Minute, time.Millisecond, time.Second)
+	svc := NewCustomerDataService(mocks.customerRepo, mocks.orderRepo, mocks.orderCache, mocks.existenceCache, idempotency, mocks.txManager)
+	return svc, mocks
+}
+
+func TestCustomerDataService_ExportCustomer(t *testing.T) {
+	customerID := domain.ID("ccddaabbee112233aabbcc01")
+
+	t.Run("success follows every page of orders", func(t *testing.T) {
+		svc, mocks := setupCustomerDataService(t)
+		customer := &domain.Customer{ID: customerID, Name: "Ada Lovelace", Email: "ada@example.com"}
+		first := &domain.Order{ID: "aabbccddee112233aabbcc01", CustomerID: customerID}
+		second := &domain.Order{ID: "aabbccddee112233aabbcc02", CustomerID: customerID}
+
+		mocks.customerRepo.EXPECT().GetByID(gomock.Any(), customerID).Return(customer, nil)
+		gomock.InOrder(
+			mocks.orderRepo.EXPECT().
+				GetByCustomerID(gomock.Any(), customerID, domain.OrderStatus(""), int64(orderListMaxLimit), "").
+				Return(&port.Page[*domain.Order]{Items: []*domain.Order{first}, NextCursor: "next"}, nil),
+			mocks.orderRepo.EXPECT().
+				GetByCustomerID(gomock.Any(), customerID, domain.OrderStatus(""), int64(orderListMaxLimit), "next").
+				Return(&port.Page[*domain.Order]{Items: []*domain.Order{second}}, nil),
+		)
+
+		export, err := svc.ExportCustomer(context.Background(), customerID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if export.Customer != customer {
+			t.Fatalf("expected the customer profile, got %+v", export.Customer)
+		}
+		if len(export.Orders) != 2 || export.Orders[0] != first || export.Orders[1] != second {
+			t.Fatalf("expected both orders, got %+v", export.Orders)
+		}
+		if export.ExportedAt.IsZero() {
+			t.Fatal("expected ExportedAt to be set")
+		}
+	})
+
+	t.Run("customer not found", func(t *testing.T) {
+		svc, mocks := setupCustomerDataService(t)
+
+		mocks.customerRepo.EXPECT().
+			GetByID(gomock.Any(), customerID).
+			Return(nil, serviceerrors.NewNotFoundError("entity not found"))
+
+		_, err := svc.ExportCustomer(context.Background(), customerID)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+}
+
+func TestCustomerDataService_EraseCustomer(t *testing.T) {
+	customerID := domain.ID("ccddaabbee112233aabbcc01")
+	orders := []*domain.Order{
+		{ID: "aabbccddee112233aabbcc01", CustomerID: customerID, IdempotencyKey: "checkout-1"},
+		{ID: "aabbccddee112233aabbcc02", CustomerID: customerID},
+	}
+
+	expectTransaction := func(mocks *customerDataMocks) {
+		mocks.txManager.EXPECT().
+			WithTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
+	}
+
+	t.Run("success erases and evicts the customer and their orders from the caches", func(t *testing.T) {
+		svc, mocks := setupCustomerDataService(t)
+
+		mocks.customerRepo.EXPECT().GetByID(gomock.Any(), customerID).Return(&domain.Customer{ID: customerID}, nil)
+		expectTransaction(mocks)
+		gomock.InOrder(
+			mocks.orderRepo.EXPECT().EraseShippingAddresses(gomock.Any(), customerID).Return(nil),
+			mocks.customerRepo.EXPECT().
+				Erase(gomock.Any(), customerID, gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ domain.ID, erasedAt time.Time, event domain.Event) error {
+					erased, ok := event.(*domain.CustomerErasedEvent)
+					if !ok || erased.CustomerID != customerID || !erased.ErasedAt.Equal(erasedAt) {
+						t.Fatalf("expected a customer.erased event for %s, got %+v", customerID, event)
+					}
+					return nil
+				}),
+			// Read back in the transaction, so exactly the orders erased are evicted.
+			mocks.orderRepo.EXPECT().
+				GetByCustomerID(gomock.Any(), customerID, domain.OrderStatus(""), int64(orderListMaxLimit), "").
+				Return(&port.Page[*domain.Order]{Items: orders}, nil),
+		)
+		mocks.idempotency.EXPECT().Get(gomock.Any(), "checkout-1").Return(&IdempotencyEntry[domain.Order]{
+			Status:      IdempotencyCompleted,
+			PayloadHash: "hash",
+			Result:      &domain.Order{ID: orders[0].ID, ShippingAddress: &domain.Address{Line1: "1 Main St", Country: "PT"}},
+		}, nil)
+		mocks.idempotency.EXPECT().
+			Set(gomock.Any(), "checkout-1", gomock.Any(), time.Minute).
+			DoAndReturn(func(_ context.Context, _ string, entry *IdempotencyEntry[domain.Order], _ time.Duration) error {
+				if entry.Result != orders[0] || entry.PayloadHash != "hash" {
+					t.Fatalf("expected the erased order to replace the replayed one, got %+v", entry)
+				}
+				return nil
+			})
+		erased := false
+		mocks.existenceCache.EXPECT().Set(gomock.Any(), "customer-exists:"+string(customerID), &erased, customerErasedCacheTTL).Return(nil)
+		mocks.orderCache.EXPECT().Del(gomock.Any(), "order:aabbccddee112233aabbcc01").Return(nil)
+		mocks.orderCache.EXPECT().Del(gomock.Any(), "order:aabbccddee112233aabbcc02").Return(errors.New("redis down"))
+
+		if err := svc.EraseCustomer(context.Background(), customerID); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("already erased is a no-op", func(t *testing.T) {
+		svc, mocks := setupCustomerDataService(t)
+		erasedAt := time.Now()
+
+		mocks.customerRepo.EXPECT().
+			GetByID(gomock.Any(), customerID).
+			Return(&domain.Customer{ID: customerID, ErasedAt: &erasedAt}, nil)
+
+		if err := svc.EraseCustomer(context.Background(), customerID); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("customer not found", func(t *testing.T) {
+		svc, mocks := setupCustomerDataService(t)
+
+		mocks.customerRepo.EXPECT().
+			GetByID(gomock.Any(), customerID).
+			Return(nil, serviceerrors.NewNotFoundError("entity not found"))
+
+		err := svc.EraseCustomer(context.Background(), customerID)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+
+	t.Run("transaction error is returned and nothing is evicted", func(t *testing.T) {
+		svc, mocks := setupCustomerDataService(t)
+
+		mocks.customerRepo.EXPECT().GetByID(gomock.Any(), customerID).Return(&domain.Customer{ID: customerID}, nil)
+		expectTransaction(mocks)
+		mocks.orderRepo.EXPECT().EraseShippingAddresses(gomock.Any(), customerID).Return(errors.New("write conflict"))
+
+		if err := svc.EraseCustomer(context.Background(), customerID); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
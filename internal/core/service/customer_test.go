@@ -6,33 +6,76 @@ import (
 	"testing"
 
 	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/dto"
+	"github.com/rafaelleal24/challenge/internal/core/port"
 	"github.com/rafaelleal24/challenge/internal/core/port/mock"
 	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
 	"go.uber.org/mock/gomock"
 )
 
 func setupCustomerService(t *testing.T) (*CustomerService, *mock.MockCustomerPort) {
+	svc, customerRepo, _ := setupCustomerServiceWithCache(t)
+	return svc, customerRepo
+}
+
+func setupCustomerServiceWithCache(t *testing.T) (*CustomerService, *mock.MockCustomerPort, *mock.MockCachePort[bool]) {
 	ctrl := gomock.NewController(t)
 	customerRepo := mock.NewMockCustomerPort(ctrl)
-	svc := NewCustomerService(customerRepo)
-	return svc, customerRepo
+	existenceCache := mock.NewMockCachePort[bool](ctrl)
+	svc := NewCustomerService(customerRepo, existenceCache)
+	return svc, customerRepo, existenceCache
 }
 
-func TestCustomerService_Create(t *testing.T) {
-	t.Run("success", func(t *testing.T) {
+func TestCustomerService_CreateCustomer(t *testing.T) {
+	t.Run("success normalizes the email", func(t *testing.T) {
 		svc, customerRepo := setupCustomerService(t)
 		expectedID := domain.ID("aabbccddee112233aabbccdd")
 
 		customerRepo.EXPECT().
-			Create(gomock.Any()).
-			Return(expectedID, nil)
+			Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, customer *domain.Customer) error {
+				if customer.Email != "ada@example.com" {
+					t.Fatalf("expected normalized email, got %q", customer.Email)
+				}
+				customer.ID = expectedID
+				return nil
+			})
 
-		id, err := svc.Create(context.Background())
+		customer, err := svc.CreateCustomer(context.Background(), &dto.CreateCustomerRequest{
+			Name:  "Ada Lovelace",
+			Email: "Ada@Example.com",
+			Phone: "+5511999999999",
+		})
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
-		if id != expectedID {
-			t.Fatalf("expected id %s, got %s", expectedID, id)
+		if customer.ID != expectedID {
+			t.Fatalf("expected id %s, got %s", expectedID, customer.ID)
+		}
+		if customer.Phone != "+5511999999999" {
+			t.Fatalf("expected phone to be kept, got %q", customer.Phone)
+		}
+	})
+
+	t.Run("blank name", func(t *testing.T) {
+		svc, _ := setupCustomerService(t)
+
+		_, err := svc.CreateCustomer(context.Background(), &dto.CreateCustomerRequest{Name: "   ", Email: "ada@example.com"})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+
+	t.Run("email in use", func(t *testing.T) {
+		svc, customerRepo := setupCustomerService(t)
+
+		customerRepo.EXPECT().
+			Create(gomock.Any(), gomock.Any()).
+			Return(serviceerrors.NewConflictError("duplicate key error"))
+
+		_, err := svc.CreateCustomer(context.Background(), &dto.CreateCustomerRequest{Name: "Ada", Email: "ada@example.com"})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			t.Fatalf("expected KindConflict, got %v", err)
 		}
 	})
 
@@ -41,27 +84,290 @@ func TestCustomerService_Create(t *testing.T) {
 		repoErr := errors.New("db connection failed")
 
 		customerRepo.EXPECT().
-			Create(gomock.Any()).
-			Return(domain.ID(""), repoErr)
+			Create(gomock.Any(), gomock.Any()).
+			Return(repoErr)
 
-		_, err := svc.Create(context.Background())
-		if err == nil {
-			t.Fatal("expected error, got nil")
-		}
+		_, err := svc.CreateCustomer(context.Background(), &dto.CreateCustomerRequest{Name: "Ada", Email: "ada@example.com"})
 		if !errors.Is(err, repoErr) {
 			t.Fatalf("expected %v, got %v", repoErr, err)
 		}
 	})
 }
 
-func TestCustomerService_Exists(t *testing.T) {
-	t.Run("customer exists", func(t *testing.T) {
+func TestCustomerService_GetByID(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
 		svc, customerRepo := setupCustomerService(t)
 		customerID := domain.ID("aabbccddee112233aabbccdd")
 
+		customerRepo.EXPECT().
+			GetByID(gomock.Any(), customerID).
+			Return(&domain.Customer{ID: customerID, Name: "Ada"}, nil)
+
+		customer, err := svc.GetByID(context.Background(), customerID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if customer.Name != "Ada" {
+			t.Fatalf("expected customer Ada, got %q", customer.Name)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		svc, customerRepo := setupCustomerService(t)
+		customerID := domain.ID("aabbccddee112233aabbccdd")
+
+		customerRepo.EXPECT().
+			GetByID(gomock.Any(), customerID).
+			Return(nil, serviceerrors.NewNotFoundError("entity not found"))
+
+		_, err := svc.GetByID(context.Background(), customerID)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+}
+
+func TestCustomerService_UpdateCustomer(t *testing.T) {
+	customerID := domain.ID("aabbccddee112233aabbccdd")
+	strPtr := func(s string) *string { return &s }
+
+	t.Run("normalizes name and email", func(t *testing.T) {
+		svc, customerRepo := setupCustomerService(t)
+
+		customerRepo.EXPECT().
+			Update(gomock.Any(), customerID, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ domain.ID, update port.CustomerUpdate) (*domain.Customer, error) {
+				if *update.Name != "Ada" || *update.Email != "ada@example.com" || update.Phone != nil {
+					t.Fatalf("unexpected update %+v", update)
+				}
+				return &domain.Customer{ID: customerID, Name: *update.Name, Email: *update.Email}, nil
+			})
+
+		customer, err := svc.UpdateCustomer(context.Background(), customerID, &dto.UpdateCustomerRequest{
+			Name:  strPtr(" Ada "),
+			Email: strPtr("ADA@example.com"),
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if customer.Email != "ada@example.com" {
+			t.Fatalf("expected updated email, got %q", customer.Email)
+		}
+	})
+
+	t.Run("no fields", func(t *testing.T) {
+		svc, _ := setupCustomerService(t)
+
+		_, err := svc.UpdateCustomer(context.Background(), customerID, &dto.UpdateCustomerRequest{})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+
+	t.Run("blank name", func(t *testing.T) {
+		svc, _ := setupCustomerService(t)
+
+		_, err := svc.UpdateCustomer(context.Background(), customerID, &dto.UpdateCustomerRequest{Name: strPtr(" ")})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+
+	t.Run("email in use", func(t *testing.T) {
+		svc, customerRepo := setupCustomerService(t)
+
+		customerRepo.EXPECT().
+			Update(gomock.Any(), customerID, gomock.Any()).
+			Return(nil, serviceerrors.NewConflictError("duplicate key error"))
+
+		_, err := svc.UpdateCustomer(context.Background(), customerID, &dto.UpdateCustomerRequest{Email: strPtr("taken@example.com")})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			t.Fatalf("expected KindConflict, got %v", err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		svc, customerRepo := setupCustomerService(t)
+
+		customerRepo.EXPECT().
+			Update(gomock.Any(), customerID, gomock.Any()).
+			Return(nil, serviceerrors.NewNotFoundError("entity not found"))
+
+		_, err := svc.UpdateCustomer(context.Background(), customerID, &dto.UpdateCustomerRequest{Phone: strPtr("")})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+}
+
+func newTestAddressRequest() *dto.SaveAddressRequest {
+	return &dto.SaveAddressRequest{
+		Label: " home ",
+		AddressRequest: dto.AddressRequest{
+			Recipient:  "Ada Lovelace",
+			Line1:      "Rua A, 1",
+			City:       "São Paulo",
+			PostalCode: "01000-000",
+			Country:    "BR",
+		},
+	}
+}
+
+func TestCustomerService_AddAddress(t *testing.T) {
+	customerID := domain.ID("aabbccddee112233aabbccdd")
+	addressID := domain.ID("aabbccddee112233aabbcc01")
+
+	t.Run("success", func(t *testing.T) {
+		svc, customerRepo := setupCustomerService(t)
+
+		customerRepo.EXPECT().
+			AddAddress(gomock.Any(), customerID, gomock.Any(), true).
+			DoAndReturn(func(_ context.Context, _ domain.ID, address *domain.CustomerAddress, _ bool) (*domain.Customer, error) {
+				if address.Label != "home" || address.Line1 != "Rua A, 1" {
+					t.Fatalf("unexpected address %+v", address)
+				}
+				address.ID = addressID
+				return &domain.Customer{ID: customerID, Addresses: []domain.CustomerAddress{*address}, DefaultAddressID: addressID}, nil
+			})
+
+		request := newTestAddressRequest()
+		request.Default = true
+		customer, address, err := svc.AddAddress(context.Background(), customerID, request)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if address == nil || address.ID != addressID || customer.DefaultAddressID != addressID {
+			t.Fatalf("expected saved default address %s, got %+v", addressID, address)
+		}
+	})
+
+	t.Run("customer not found", func(t *testing.T) {
+		svc, customerRepo := setupCustomerService(t)
+
+		customerRepo.EXPECT().
+			AddAddress(gomock.Any(), customerID, gomock.Any(), false).
+			Return(nil, serviceerrors.NewNotFoundError("entity not found"))
+
+		_, _, err := svc.AddAddress(context.Background(), customerID, newTestAddressRequest())
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+
+	t.Run("address book full", func(t *testing.T) {
+		svc, customerRepo := setupCustomerService(t)
+
+		customerRepo.EXPECT().
+			AddAddress(gomock.Any(), customerID, gomock.Any(), false).
+			Return(nil, serviceerrors.NewUnprocessableEntityError("a customer can have at most 20 addresses"))
+
+		_, _, err := svc.AddAddress(context.Background(), customerID, newTestAddressRequest())
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindUnprocessableEntity) {
+			t.Fatalf("expected KindUnprocessableEntity, got %v", err)
+		}
+	})
+}
+
+func TestCustomerService_ReplaceAddress(t *testing.T) {
+	customerID := domain.ID("aabbccddee112233aabbccdd")
+	addressID := domain.ID("aabbccddee112233aabbcc01")
+	customer := &domain.Customer{
+		ID:               customerID,
+		Addresses:        []domain.CustomerAddress{{ID: addressID, Address: domain.Address{Line1: "Old street"}}},
+		DefaultAddressID: addressID,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		svc, customerRepo := setupCustomerService(t)
+
+		customerRepo.EXPECT().GetByID(gomock.Any(), customerID).Return(customer, nil)
+		customerRepo.EXPECT().
+			ReplaceAddress(gomock.Any(), customerID, gomock.Any(), false).
+			DoAndReturn(func(_ context.Context, _ domain.ID, address domain.CustomerAddress, _ bool) (*domain.Customer, error) {
+				if address.ID != addressID || address.Line1 != "Rua A, 1" {
+					t.Fatalf("unexpected address %+v", address)
+				}
+				return &domain.Customer{ID: customerID, Addresses: []domain.CustomerAddress{address}, DefaultAddressID: addressID}, nil
+			})
+
+		_, address, err := svc.ReplaceAddress(context.Background(), customerID, addressID, newTestAddressRequest())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if address.Line1 != "Rua A, 1" {
+			t.Fatalf("expected replaced address, got %+v", address)
+		}
+	})
+
+	t.Run("address not found", func(t *testing.T) {
+		svc, customerRepo := setupCustomerService(t)
+
+		customerRepo.EXPECT().GetByID(gomock.Any(), customerID).Return(customer, nil)
+
+		_, _, err := svc.ReplaceAddress(context.Background(), customerID, "aabbccddee112233aabbcc02", newTestAddressRequest())
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+
+	t.Run("invalid address ID", func(t *testing.T) {
+		svc, _ := setupCustomerService(t)
+
+		_, _, err := svc.ReplaceAddress(context.Background(), customerID, "nope", newTestAddressRequest())
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+}
+
+func TestCustomerService_RemoveAddress(t *testing.T) {
+	customerID := domain.ID("aabbccddee112233aabbccdd")
+	addressID := domain.ID("aabbccddee112233aabbcc01")
+
+	t.Run("success", func(t *testing.T) {
+		svc, customerRepo := setupCustomerService(t)
+
+		customerRepo.EXPECT().
+			GetByID(gomock.Any(), customerID).
+			Return(&domain.Customer{ID: customerID, Addresses: []domain.CustomerAddress{{ID: addressID}}, DefaultAddressID: addressID}, nil)
+		customerRepo.EXPECT().
+			RemoveAddress(gomock.Any(), customerID, addressID).
+			Return(&domain.Customer{ID: customerID}, nil)
+
+		if err := svc.RemoveAddress(context.Background(), customerID, addressID); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("customer not found", func(t *testing.T) {
+		svc, customerRepo := setupCustomerService(t)
+
+		customerRepo.EXPECT().
+			GetByID(gomock.Any(), customerID).
+			Return(nil, serviceerrors.NewNotFoundError("entity not found"))
+
+		err := svc.RemoveAddress(context.Background(), customerID, addressID)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+}
+
+func TestCustomerService_Exists(t *testing.T) {
+	customerID := domain.ID("aabbccddee112233aabbccdd")
+	cacheKey := "customer-exists:" + string(customerID)
+	found, missing := true, false
+
+	t.Run("cache miss checks the repository and caches the hit", func(t *testing.T) {
+		svc, customerRepo, existenceCache := setupCustomerServiceWithCache(t)
+
+		existenceCache.EXPECT().Get(gomock.Any(), cacheKey).Return(nil, nil)
 		customerRepo.EXPECT().
 			Exists(gomock.Any(), customerID).
 			Return(true, nil)
+		existenceCache.EXPECT().
+			SetNX(gomock.Any(), cacheKey, &found, customerExistsCacheTTL).
+			Return(true, nil)
 
 		err := svc.Exists(context.Background(), customerID)
 		if err != nil {
@@ -69,13 +375,16 @@ func TestCustomerService_Exists(t *testing.T) {
 		}
 	})
 
-	t.Run("customer not found", func(t *testing.T) {
-		svc, customerRepo := setupCustomerService(t)
-		customerID := domain.ID("aabbccddee112233aabbccdd")
+	t.Run("customer not found is cached for a shorter time", func(t *testing.T) {
+		svc, customerRepo, existenceCache := setupCustomerServiceWithCache(t)
 
+		existenceCache.EXPECT().Get(gomock.Any(), cacheKey).Return(nil, nil)
 		customerRepo.EXPECT().
 			Exists(gomock.Any(), customerID).
 			Return(false, serviceerrors.NewNotFoundError("entity not found"))
+		existenceCache.EXPECT().
+			SetNX(gomock.Any(), cacheKey, &missing, customerMissingCacheTTL).
+			Return(true, nil)
 
 		err := svc.Exists(context.Background(), customerID)
 		if err == nil {
@@ -86,17 +395,75 @@ func TestCustomerService_Exists(t *testing.T) {
 		}
 	})
 
-	t.Run("repository error returns nil (logs and swallows)", func(t *testing.T) {
-		svc, customerRepo := setupCustomerService(t)
-		customerID := domain.ID("aabbccddee112233aabbccdd")
+	t.Run("does not overwrite an entry cached meanwhile", func(t *testing.T) {
+		svc, customerRepo, existenceCache := setupCustomerServiceWithCache(t)
+
+		existenceCache.EXPECT().Get(gomock.Any(), cacheKey).Return(nil, nil)
+		customerRepo.EXPECT().
+			Exists(gomock.Any(), customerID).
+			Return(true, nil)
+		// An erasure committed after the read has cached the customer as missing.
+		existenceCache.EXPECT().
+			SetNX(gomock.Any(), cacheKey, &found, customerExistsCacheTTL).
+			Return(false, nil)
+		existenceCache.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		if err := svc.Exists(context.Background(), customerID); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("cached hit skips the repository", func(t *testing.T) {
+		svc, _, existenceCache := setupCustomerServiceWithCache(t)
+
+		existenceCache.EXPECT().Get(gomock.Any(), cacheKey).Return(&found, nil)
 
+		err := svc.Exists(context.Background(), customerID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("cached miss skips the repository", func(t *testing.T) {
+		svc, _, existenceCache := setupCustomerServiceWithCache(t)
+
+		existenceCache.EXPECT().Get(gomock.Any(), cacheKey).Return(&missing, nil)
+
+		err := svc.Exists(context.Background(), customerID)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+
+	t.Run("repository error is returned and not cached", func(t *testing.T) {
+		svc, customerRepo, existenceCache := setupCustomerServiceWithCache(t)
+		dbErr := errors.New("unexpected db error")
+
+		existenceCache.EXPECT().Get(gomock.Any(), cacheKey).Return(nil, nil)
+		customerRepo.EXPECT().
+			Exists(gomock.Any(), customerID).
+			Return(false, dbErr)
+
+		err := svc.Exists(context.Background(), customerID)
+		if !errors.Is(err, dbErr) {
+			t.Fatalf("expected the repository error, got %v", err)
+		}
+	})
+
+	t.Run("cache errors fall back to the repository", func(t *testing.T) {
+		svc, customerRepo, existenceCache := setupCustomerServiceWithCache(t)
+
+		existenceCache.EXPECT().Get(gomock.Any(), cacheKey).Return(nil, errors.New("redis down"))
 		customerRepo.EXPECT().
 			Exists(gomock.Any(), customerID).
-			Return(false, errors.New("unexpected db error"))
+			Return(true, nil)
+		existenceCache.EXPECT().
+			SetNX(gomock.Any(), cacheKey, gomock.Any(), gomock.Any()).
+			Return(false, errors.New("redis down"))
 
 		err := svc.Exists(context.Background(), customerID)
 		if err != nil {
-			t.Fatalf("expected nil (swallowed error), got %v", err)
+			t.Fatalf("expected no error, got %v", err)
 		}
 	})
 }
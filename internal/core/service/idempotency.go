@@ -82,6 +82,25 @@ func (s *IdempotencyService[T]) Release(ctx context.Context, key string) {
 	}
 }
 
+// ReplaceResult stores result in place of the one recorded for a completed key, keeping its
+// payload hash, so requests retried with the key replay it instead. Keys that expired or are
+// still being processed are left alone.
+func (s *IdempotencyService[T]) ReplaceResult(ctx context.Context, key string, result *T) error {
+	entry, err := s.cache.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("idempotency lookup failed: %w", err)
+	}
+	if entry == nil || entry.Status != IdempotencyCompleted {
+		return nil
+	}
+
+	entry.Result = result
+	if err := s.cache.Set(ctx, key, entry, s.ttl); err != nil {
+		return fmt.Errorf("idempotency replace failed: %w", err)
+	}
+	return nil
+}
+
 func (s *IdempotencyService[T]) checkEntry(ctx context.Context, key, payloadHash string) (*T, error) {
 	entry, err := s.cache.Get(ctx, key)
 	if err != nil {
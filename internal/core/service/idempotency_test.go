@@ -302,3 +302,41 @@ func TestIdempotencyService_Release(t *testing.T) {
 		svc.Release(context.Background(), "key")
 	})
 }
+
+func TestIdempotencyService_ReplaceResult(t *testing.T) {
+	t.Run("replaces the result of a completed key and keeps its hash", func(t *testing.T) {
+		svc, cache := setupIdempotencyService(t)
+		key := "idem-key-1"
+
+		cache.EXPECT().Get(gomock.Any(), key).Return(&IdempotencyEntry[testPayload]{
+			Status:      IdempotencyCompleted,
+			PayloadHash: "hash123",
+			Result:      &testPayload{Value: "original"},
+		}, nil)
+		cache.EXPECT().
+			Set(gomock.Any(), key, gomock.Any(), 15*time.Minute).
+			DoAndReturn(func(_ context.Context, _ string, entry *IdempotencyEntry[testPayload], _ time.Duration) error {
+				if entry.Status != IdempotencyCompleted || entry.PayloadHash != "hash123" || entry.Result.Value != "redacted" {
+					t.Fatalf("unexpected entry %+v", entry)
+				}
+				return nil
+			})
+
+		if err := svc.ReplaceResult(context.Background(), key, &testPayload{Value: "redacted"}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("leaves expired and in-flight keys alone", func(t *testing.T) {
+		svc, cache := setupIdempotencyService(t)
+
+		cache.EXPECT().Get(gomock.Any(), "expired").Return(nil, nil)
+		cache.EXPECT().Get(gomock.Any(), "in-flight").Return(&IdempotencyEntry[testPayload]{Status: IdempotencyProcessing}, nil)
+
+		for _, key := range []string{"expired", "in-flight"} {
+			if err := svc.ReplaceResult(context.Background(), key, &testPayload{}); err != nil {
+				t.Fatalf("expected no error for %s, got %v", key, err)
+			}
+		}
+	})
+}
@@ -3,6 +3,9 @@ package service
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/rafaelleal24/challenge/internal/core/domain"
@@ -14,25 +17,61 @@ import (
 )
 
 const (
-	ORDER_MAX_ITEMS = 100
-	orderCacheTTL   = 15 * time.Minute
+	ORDER_MAX_ITEMS       = 100
+	orderCacheTTL         = 15 * time.Minute
+	orderListDefaultLimit = 20
+	orderListMaxLimit     = 100
+	orderExpiryActor      = "system"
+	orderExpiryReason     = "order expired"
 )
 
 type OrderService struct {
 	orderRepository port.OrderPort
 	productService  *ProductService
+	reservations    *ReservationService
 	customerService *CustomerService
 	orderCache      port.CachePort[domain.Order]
 	idempotency     *IdempotencyService[domain.Order]
 	txManager       port.TransactionManager
+	quoteSigner     *QuoteSigner
 }
 
-func (s *OrderService) getCacheKey(orderID domain.ID) string {
+func orderCacheKey(orderID domain.ID) string {
 	return fmt.Sprintf("order:%s", orderID)
 }
 
+func newInvalidTransitionError(from, to domain.OrderStatus) error {
+	allowed := from.AllowedTransitions()
+	allowedStatuses := make([]string, len(allowed))
+	for i, status := range allowed {
+		allowedStatuses[i] = string(status)
+	}
+	return serviceerrors.NewInvalidTransitionError(
+		fmt.Sprintf("cannot change order status from %s to %s", from, to),
+		map[string]any{
+			"current_status":   string(from),
+			"allowed_statuses": allowedStatuses,
+		},
+	)
+}
+
+// checkOrderVersion accepts the order when expectedVersions is nil or names its version.
+func checkOrderVersion(order *domain.Order, expectedVersions []int64) error {
+	if expectedVersions == nil || slices.Contains(expectedVersions, order.Version) {
+		return nil
+	}
+	if len(expectedVersions) == 0 {
+		return serviceerrors.NewConflictError(fmt.Sprintf("order version is %d, no expected version given", order.Version))
+	}
+	expected := make([]string, len(expectedVersions))
+	for i, version := range expectedVersions {
+		expected[i] = strconv.FormatInt(version, 10)
+	}
+	return serviceerrors.NewConflictError(fmt.Sprintf("order version is %d, expected %s", order.Version, strings.Join(expected, " or ")))
+}
+
 func (s *OrderService) GetOrderByID(ctx context.Context, orderID domain.ID) (*domain.Order, error) {
-	cached, err := s.orderCache.Get(ctx, s.getCacheKey(orderID))
+	cached, err := s.orderCache.Get(ctx, orderCacheKey(orderID))
 	if err != nil {
 		logger.Error(ctx, "cache: get order failed", err, map[string]any{
 			"order_id": orderID,
@@ -50,7 +89,7 @@ func (s *OrderService) GetOrderByID(ctx context.Context, orderID domain.ID) (*do
 		return nil, err
 	}
 
-	if err := s.orderCache.Set(ctx, s.getCacheKey(orderID), order, orderCacheTTL); err != nil {
+	if err := s.orderCache.Set(ctx, orderCacheKey(orderID), order, orderCacheTTL); err != nil {
 		logger.Error(ctx, "cache: set order failed", err, map[string]any{
 			"order_id": orderID,
 		})
@@ -59,27 +98,52 @@ func (s *OrderService) GetOrderByID(ctx context.Context, orderID domain.ID) (*do
 	return order, nil
 }
 
-func (s *OrderService) UpdateOrderStatus(ctx context.Context, orderID domain.ID, status domain.OrderStatus) error {
+// UpdateOrderStatus moves the order to status. When expectedVersions is not nil the update is
+// rejected with a conflict unless the order is at one of those versions, that is unless it is
+// as the caller last read it.
+func (s *OrderService) UpdateOrderStatus(ctx context.Context, orderID domain.ID, status domain.OrderStatus, expectedVersions []int64, actor, reason string) error {
 	if !status.IsValid() {
 		return serviceerrors.NewInvalidRequestError("invalid status")
 	}
+	if status == domain.OrderStatusCancelled {
+		_, err := s.cancelOrder(ctx, orderID, expectedVersions, actor, reason)
+		return err
+	}
 
 	order, err := s.orderRepository.GetByID(ctx, orderID)
 	if err != nil {
 		return err
 	}
+	if err := checkOrderVersion(order, expectedVersions); err != nil {
+		return err
+	}
 	if order.Status == status {
 		return serviceerrors.NewUnprocessableEntityError("order already has this status")
 	}
+	if !order.Status.CanTransitionTo(status) {
+		return newInvalidTransitionError(order.Status, status)
+	}
 
-	event := domain.NewOrderUpdateStatusEvent(orderID, status, order.Status, time.Now(), order.CustomerID)
-	if err := s.orderRepository.UpdateStatusWithOutbox(ctx, orderID, status, event); err != nil {
+	change := domain.NewOrderStatusChange(order.Status, status, actor, reason)
+	event := domain.NewOrderUpdateStatusEvent(orderID, status, order.Status, change.ChangedAt, order.CustomerID)
+	err = s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := s.orderRepository.UpdateStatusWithOutbox(txCtx, orderID, order.Version, change, event); err != nil {
+			return err
+		}
+		if status == domain.OrderStatusProcessing {
+			return s.reservations.Commit(txCtx, order)
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error(ctx, "transaction: update order status failed", err, map[string]any{
+			"order_id": orderID,
+		})
 		return err
 	}
 
-	order.Status = status
-	order.UpdatedAt = time.Now()
-	if err := s.orderCache.Set(ctx, s.getCacheKey(orderID), order, orderCacheTTL); err != nil {
+	order.ApplyStatusChange(change)
+	if err := s.orderCache.Set(ctx, orderCacheKey(orderID), order, orderCacheTTL); err != nil {
 		logger.Error(ctx, "cache: update order failed", err, map[string]any{
 			"order_id": orderID,
 		})
@@ -87,26 +151,349 @@ func (s *OrderService) UpdateOrderStatus(ctx context.Context, orderID domain.ID,
 
 	logger.Info(ctx, "Order status updated", map[string]any{
 		"order_id":   orderID,
-		"old_status": order.Status,
-		"new_status": status,
+		"old_status": change.From,
+		"new_status": change.To,
+		"actor":      actor,
 	})
 
 	return nil
 }
 
-func (s *OrderService) getOrderItems(ctx context.Context, dtoItems []dto.OrderItem) ([]domain.OrderItem, error) {
+func (s *OrderService) CancelOrder(ctx context.Context, orderID domain.ID, actor, reason string) (*domain.Order, error) {
+	return s.cancelOrder(ctx, orderID, nil, actor, reason)
+}
+
+func (s *OrderService) cancelOrder(ctx context.Context, orderID domain.ID, expectedVersions []int64, actor, reason string) (*domain.Order, error) {
+	order, err := s.orderRepository.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOrderVersion(order, expectedVersions); err != nil {
+		return nil, err
+	}
+	if order.Status == domain.OrderStatusCancelled {
+		return nil, serviceerrors.NewUnprocessableEntityError("order already has this status")
+	}
+	if !order.Status.CanTransitionTo(domain.OrderStatusCancelled) {
+		return nil, newInvalidTransitionError(order.Status, domain.OrderStatusCancelled)
+	}
+
+	change := domain.NewOrderStatusChange(order.Status, domain.OrderStatusCancelled, actor, reason)
+	event := domain.NewOrderCancelledEvent(order, reason, change.ChangedAt)
+
+	err = s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := s.orderRepository.UpdateStatusWithOutbox(txCtx, orderID, order.Version, change, event); err != nil {
+			return err
+		}
+		// Stock of orders not yet processed is only reserved.
+		if change.From == domain.OrderStatusCreated {
+			return s.reservations.Release(txCtx, order)
+		}
+		for _, item := range order.Items {
+			if err := s.productService.RestoreStock(txCtx, item.ProductID, item.SKU, item.Quantity, orderID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error(ctx, "transaction: cancel order failed", err, map[string]any{
+			"order_id": orderID,
+		})
+		return nil, err
+	}
+
+	order.ApplyStatusChange(change)
+	if err := s.orderCache.Set(ctx, orderCacheKey(orderID), order, orderCacheTTL); err != nil {
+		logger.Error(ctx, "cache: update order failed", err, map[string]any{
+			"order_id": orderID,
+		})
+	}
+
+	logger.Info(ctx, "Order cancelled", map[string]any{
+		"order_id":   orderID,
+		"old_status": change.From,
+		"actor":      actor,
+		"reason":     reason,
+	})
+
+	return order, nil
+}
+
+// ExpireStaleOrders cancels up to limit orders that are still created and were placed
+// before cutoff. Each cancellation is conditioned on the version that was read, so an
+// order changed concurrently, or already expired by another replica, is skipped.
+func (s *OrderService) ExpireStaleOrders(ctx context.Context, cutoff time.Time, limit int) (int, error) {
+	stale, err := s.findStaleOrders(ctx, cutoff, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, order := range stale {
+		_, err := s.cancelOrder(ctx, order.ID, []int64{order.Version}, orderExpiryActor, orderExpiryReason)
+		if serviceerrors.IsOfKind(err, serviceerrors.KindConflict) ||
+			serviceerrors.IsOfKind(err, serviceerrors.KindInvalidTransition) ||
+			serviceerrors.IsOfKind(err, serviceerrors.KindUnprocessableEntity) {
+			continue
+		}
+		if err != nil {
+			logger.Error(ctx, "order: expire stale order failed", err, map[string]any{
+				"order_id": order.ID,
+			})
+			continue
+		}
+		expired++
+	}
+
+	return expired, nil
+}
+
+// findStaleOrders returns up to limit created orders placed before cutoff, oldest first, so
+// the longest-waiting orders are expired first. The query is served by the status and
+// created_at index.
+func (s *OrderService) findStaleOrders(ctx context.Context, cutoff time.Time, limit int) ([]*domain.Order, error) {
+	page, err := s.orderRepository.List(ctx,
+		port.OrderFilter{Status: domain.OrderStatusCreated, CreatedBefore: cutoff},
+		port.PageRequest{Limit: int64(limit), SortBy: port.OrderSortCreatedAt},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+func (s *OrderService) GetOrderStatusHistory(ctx context.Context, orderID domain.ID) ([]domain.OrderStatusChange, error) {
+	order, err := s.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return order.StatusHistory, nil
+}
+
+func (s *OrderService) ListOrders(ctx context.Context, request *dto.ListOrdersRequest) (*port.Page[*domain.Order], error) {
+	filter, err := newOrderFilter(request)
+	if err != nil {
+		return nil, err
+	}
+
+	page := port.PageRequest{
+		Limit:        request.Limit,
+		Cursor:       request.Cursor,
+		SortBy:       request.Sort,
+		Descending:   request.Order != "asc",
+		IncludeTotal: request.IncludeTotal,
+	}
+	if page.Limit <= 0 {
+		page.Limit = orderListDefaultLimit
+	}
+	if page.Limit > orderListMaxLimit {
+		page.Limit = orderListMaxLimit
+	}
+	if page.SortBy == "" {
+		page.SortBy = port.OrderSortCreatedAt
+	}
+	if page.SortBy != port.OrderSortCreatedAt && page.SortBy != port.OrderSortTotalAmount {
+		return nil, serviceerrors.NewInvalidRequestError("invalid sort field")
+	}
+
+	// The plain customer and status listings, newest first, have dedicated queries.
+	if isNewestFirst(page) && filter.CreatedFrom.IsZero() && filter.CreatedTo.IsZero() && filter.MinTotal == nil && filter.MaxTotal == nil {
+		switch {
+		case filter.CustomerID != "":
+			return s.orderRepository.GetByCustomerID(ctx, filter.CustomerID, filter.Status, page.Limit, page.Cursor)
+		case filter.Status != "":
+			return s.orderRepository.GetByStatus(ctx, filter.Status, page.Limit, page.Cursor)
+		}
+	}
+
+	return s.orderRepository.List(ctx, filter, page)
+}
+
+// isNewestFirst reports whether page asks for orders by creation date, newest first, without
+// a total count, which is what GetByCustomerID and GetByStatus return.
+func isNewestFirst(page port.PageRequest) bool {
+	return page.SortBy == port.OrderSortCreatedAt && page.Descending && !page.IncludeTotal
+}
+
+// ListCustomerOrders returns a page of the customer's orders, newest first, optionally only
+// those in the requested status. Erased customers keep their orders for accounting, so their
+// orders stay listable even though they can no longer place new ones.
+func (s *OrderService) ListCustomerOrders(ctx context.Context, customerID domain.ID, request *dto.ListCustomerOrdersRequest) (*port.Page[*domain.Order], error) {
+	if request.Status != "" && !request.Status.IsValid() {
+		return nil, serviceerrors.NewInvalidRequestError("invalid status")
+	}
+	if _, err := s.customerService.GetByID(ctx, customerID); err != nil {
+		return nil, err
+	}
+
+	limit := request.Limit
+	if limit <= 0 {
+		limit = orderListDefaultLimit
+	}
+	if limit > orderListMaxLimit {
+		limit = orderListMaxLimit
+	}
+
+	return s.orderRepository.GetByCustomerID(ctx, customerID, request.Status, limit, request.Cursor)
+}
+
+func newOrderFilter(request *dto.ListOrdersRequest) (port.OrderFilter, error) {
+	if request.CustomerID != "" && !domain.ValidateID(string(request.CustomerID)) {
+		return port.OrderFilter{}, serviceerrors.NewInvalidRequestError("invalid customer ID")
+	}
+	if request.Status != "" && !request.Status.IsValid() {
+		return port.OrderFilter{}, serviceerrors.NewInvalidRequestError("invalid status")
+	}
+	if !request.CreatedFrom.IsZero() && !request.CreatedTo.IsZero() && request.CreatedFrom.After(request.CreatedTo) {
+		return port.OrderFilter{}, serviceerrors.NewInvalidRequestError("created_from must not be after created_to")
+	}
+	if request.MinTotal != nil && request.MaxTotal != nil && *request.MinTotal > *request.MaxTotal {
+		return port.OrderFilter{}, serviceerrors.NewInvalidRequestError("min_total must not be greater than max_total")
+	}
+
+	filter := port.OrderFilter{
+		CustomerID:  request.CustomerID,
+		Status:      request.Status,
+		CreatedFrom: request.CreatedFrom,
+		CreatedTo:   request.CreatedTo,
+	}
+	if request.MinTotal != nil {
+		minTotal := domain.NewAmountFromCents(*request.MinTotal)
+		filter.MinTotal = &minTotal
+	}
+	if request.MaxTotal != nil {
+		maxTotal := domain.NewAmountFromCents(*request.MaxTotal)
+		filter.MaxTotal = &maxTotal
+	}
+	return filter, nil
+}
+
+// getOrderItems prices the requested items at the current product or variant prices and
+// returns the products they were priced from. Products are loaded in one query, and every missing
+// product is reported in a single error.
+func (s *OrderService) getOrderItems(ctx context.Context, dtoItems []dto.OrderItem) ([]domain.OrderItem, []*domain.Product, error) {
+	ids := make([]domain.ID, 0, len(dtoItems))
+	seen := make(map[domain.ID]bool, len(dtoItems))
+	for _, item := range dtoItems {
+		if !seen[item.ProductID] {
+			seen[item.ProductID] = true
+			ids = append(ids, item.ProductID)
+		}
+	}
+
+	found, err := s.productService.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[domain.ID]*domain.Product, len(found))
+	for _, product := range found {
+		byID[product.ID] = product
+	}
+
+	var missing []domain.ID
+	for _, id := range ids {
+		if byID[id] == nil {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, nil, newProductsNotFoundError(missing)
+	}
+
 	items := make([]domain.OrderItem, len(dtoItems))
+	products := make([]*domain.Product, len(dtoItems))
 	for i, item := range dtoItems {
-		product, err := s.productService.GetByID(ctx, item.ProductID)
-		if err != nil {
-			return nil, err
+		product := byID[item.ProductID]
+		if product.IsArchived() {
+			return nil, nil, serviceerrors.NewUnprocessableEntityError(fmt.Sprintf("product %s is no longer available", item.ProductID))
+		}
+		if err := checkVariantSKU(product, item.SKU); err != nil {
+			return nil, nil, err
+		}
+		items[i] = *domain.NewOrderItem(item.ProductID, product.Name, item.Quantity, product.PriceOf(item.SKU))
+		items[i].SKU = item.SKU
+		products[i] = product
+	}
+	return items, products, nil
+}
+
+func newProductsNotFoundError(ids []domain.ID) error {
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		names[i] = string(id)
+	}
+	return serviceerrors.NewNotFoundErrorWithDetails(
+		fmt.Sprintf("products not found: %s", strings.Join(names, ", ")),
+		map[string]any{"product_ids": names},
+	)
+}
+
+func (s *OrderService) QuoteOrder(ctx context.Context, request *dto.QuoteOrderRequest) (*domain.Quote, error) {
+	if len(request.Items) > ORDER_MAX_ITEMS {
+		return nil, serviceerrors.NewUnprocessableEntityError("order items limit exceeded")
+	}
+
+	items, products, err := s.getOrderItems(ctx, request.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	availableStock := make([]int, len(products))
+	for i, product := range products {
+		availableStock[i] = product.AvailableStockOf(items[i].SKU)
+	}
+
+	quote := domain.NewQuote(items, availableStock)
+	quote.Token, quote.ExpiresAt, err = s.quoteSigner.sign(items)
+	if err != nil {
+		return nil, err
+	}
+
+	return quote, nil
+}
+
+// applyQuote caps the current prices at the ones from a still valid quote token, provided the
+// order asks for exactly the quoted items. A quote guards the customer against price rises;
+// a price that dropped since is charged as it is now.
+func (s *OrderService) applyQuote(items []domain.OrderItem, token string) error {
+	quoted, err := s.quoteSigner.verify(token)
+	if err != nil {
+		return err
+	}
+	if len(quoted) != len(items) {
+		return serviceerrors.NewUnprocessableEntityError("order items do not match the quote")
+	}
+	for i := range items {
+		if items[i].ProductID != quoted[i].ProductID || items[i].SKU != quoted[i].SKU || items[i].Quantity != quoted[i].Quantity {
+			return serviceerrors.NewUnprocessableEntityError("order items do not match the quote")
 		}
-		items[i] = *domain.NewOrderItem(item.ProductID, product.Name, item.Quantity, product.Price)
+		items[i].UnitPrice = min(items[i].UnitPrice, quoted[i].UnitPrice)
 	}
-	return items, nil
+	return nil
 }
 
-func (s *OrderService) processOrder(ctx context.Context, request *dto.CreateOrderRequest) (*domain.Order, error) {
+// shippingAddress resolves where the order ships to. A saved address is copied into the order
+// so later changes to the address book do not rewrite it. Orders placed without an address
+// have none.
+func (s *OrderService) shippingAddress(ctx context.Context, request *dto.CreateOrderRequest) (*domain.Address, error) {
+	switch {
+	case request.ShippingAddressID != "" && request.ShippingAddress != nil:
+		return nil, serviceerrors.NewInvalidRequestError("shipping_address_id and shipping_address cannot both be set")
+	case request.ShippingAddressID != "":
+		if !domain.ValidateID(string(request.ShippingAddressID)) {
+			return nil, serviceerrors.NewInvalidRequestError("invalid shipping address ID")
+		}
+		return s.customerService.SavedAddress(ctx, request.CustomerID, request.ShippingAddressID)
+	case request.ShippingAddress != nil:
+		address := newAddress(*request.ShippingAddress)
+		return &address, nil
+	default:
+		return nil, nil
+	}
+}
+
+func (s *OrderService) processOrder(ctx context.Context, idempotencyKey string, request *dto.CreateOrderRequest) (*domain.Order, error) {
 	if len(request.Items) > ORDER_MAX_ITEMS {
 		return nil, serviceerrors.NewUnprocessableEntityError("order items limit exceeded")
 	}
@@ -115,20 +502,39 @@ func (s *OrderService) processOrder(ctx context.Context, request *dto.CreateOrde
 		return nil, err
 	}
 
-	items, err := s.getOrderItems(ctx, request.Items)
+	shippingAddress, err := s.shippingAddress(ctx, request)
 	if err != nil {
 		return nil, err
 	}
 
+	items, _, err := s.getOrderItems(ctx, request.Items)
+	if err != nil {
+		return nil, err
+	}
+	if request.QuoteToken != "" {
+		if err := s.applyQuote(items, request.QuoteToken); err != nil {
+			return nil, err
+		}
+	}
+
 	order := domain.NewOrder(request.CustomerID, domain.OrderStatusCreated, items)
+	order.ShippingAddress = shippingAddress
+	order.IdempotencyKey = idempotencyKey
 
 	err = s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
-		for _, item := range order.Items {
-			if err := s.productService.DeductStock(txCtx, item.ProductID, item.Quantity); err != nil {
-				return err
-			}
+		// The cached existence check above can lag an erasure that is still committing; the
+		// lock makes this order either land before the erasure, and be erased with the
+		// others, or fail.
+		if err := s.customerService.Lock(txCtx, order.CustomerID); err != nil {
+			return err
+		}
+		err := s.orderRepository.CreateWithOutbox(txCtx, order, func(created *domain.Order) domain.Event {
+			return domain.NewOrderCreatedEvent(created)
+		})
+		if err != nil {
+			return err
 		}
-		return s.orderRepository.Create(txCtx, order)
+		return s.reservations.Reserve(txCtx, order)
 	})
 	if err != nil {
 		logger.Error(ctx, "transaction: create order failed", err, map[string]any{
@@ -145,7 +551,7 @@ func (s *OrderService) processOrder(ctx context.Context, request *dto.CreateOrde
 
 func (s *OrderService) CreateOrder(ctx context.Context, idempotencyKey string, request *dto.CreateOrderRequest) (*domain.Order, error) {
 	if idempotencyKey == "" {
-		return s.processOrder(ctx, request)
+		return s.processOrder(ctx, "", request)
 	}
 
 	payloadHash := utils.HashJSON(request)
@@ -161,7 +567,7 @@ func (s *OrderService) CreateOrder(ctx context.Context, idempotencyKey string, r
 		return existing, nil
 	}
 
-	order, err := s.processOrder(ctx, request)
+	order, err := s.processOrder(ctx, idempotencyKey, request)
 	if err != nil {
 		s.idempotency.Release(ctx, idempotencyKey)
 		logger.Error(ctx, "idempotency: release failed", err, map[string]any{
@@ -178,17 +584,21 @@ func (s *OrderService) CreateOrder(ctx context.Context, idempotencyKey string, r
 func NewOrderService(
 	orderRepository port.OrderPort,
 	productService *ProductService,
+	reservations *ReservationService,
 	customerService *CustomerService,
 	orderCache port.CachePort[domain.Order],
 	idempotency *IdempotencyService[domain.Order],
 	txManager port.TransactionManager,
+	quoteSigner *QuoteSigner,
 ) *OrderService {
 	return &OrderService{
 		orderRepository: orderRepository,
 		productService:  productService,
+		reservations:    reservations,
 		customerService: customerService,
 		orderCache:      orderCache,
 		idempotency:     idempotency,
 		txManager:       txManager,
+		quoteSigner:     quoteSigner,
 	}
 }
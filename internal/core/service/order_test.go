@@ -3,11 +3,13 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/rafaelleal24/challenge/internal/core/domain"
 	"github.com/rafaelleal24/challenge/internal/core/dto"
+	"github.com/rafaelleal24/challenge/internal/core/port"
 	"github.com/rafaelleal24/challenge/internal/core/port/mock"
 	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
 	"github.com/rafaelleal24/challenge/internal/core/utils"
@@ -18,11 +20,31 @@ type orderMocks struct {
 	orderRepo    *mock.MockOrderPort
 	productSvc   *ProductService
 	productRepo  *mock.MockProductPort
+	reservations *mock.MockReservationPort
+	movements    *mock.MockStockMovementPort
 	customerSvc  *CustomerService
 	customerRepo *mock.MockCustomerPort
 	orderCache   *mock.MockCachePort[domain.Order]
 	idemCache    *mock.MockCachePort[IdempotencyEntry[domain.Order]]
 	txManager    *mock.MockTransactionManager
+	quoteSigner  *QuoteSigner
+}
+
+type statusChangeMatcher struct {
+	to domain.OrderStatus
+}
+
+func (m statusChangeMatcher) Matches(x any) bool {
+	change, ok := x.(domain.OrderStatusChange)
+	return ok && change.To == m.to
+}
+
+func (m statusChangeMatcher) String() string {
+	return fmt.Sprintf("is a status change to %s", m.to)
+}
+
+func statusChangeTo(status domain.OrderStatus) gomock.Matcher {
+	return statusChangeMatcher{to: status}
 }
 
 func setupOrderService(t *testing.T) (*OrderService, *orderMocks) {
@@ -31,25 +53,37 @@ func setupOrderService(t *testing.T) (*OrderService, *orderMocks) {
 	orderRepo := mock.NewMockOrderPort(ctrl)
 	productRepo := mock.NewMockProductPort(ctrl)
 	customerRepo := mock.NewMockCustomerPort(ctrl)
+	reservationRepo := mock.NewMockReservationPort(ctrl)
 	orderCache := mock.NewMockCachePort[domain.Order](ctrl)
 	idemCache := mock.NewMockCachePort[IdempotencyEntry[domain.Order]](ctrl)
 	txManager := mock.NewMockTransactionManager(ctrl)
-
-	productSvc := NewProductService(productRepo)
-	customerSvc := NewCustomerService(customerRepo)
+	stockMovements := mock.NewMockStockMovementPort(ctrl)
+
+	productSvc := NewProductService(productRepo, mock.NewMockCategoryPort(ctrl), stockMovements, txManager, 10)
+	reservationSvc := NewReservationService(reservationRepo, productSvc, txManager, 15*time.Minute)
+	// The customer existence cache always misses, so each test states what the repository returns.
+	customerCache := mock.NewMockCachePort[bool](ctrl)
+	customerCache.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	customerCache.EXPECT().SetNX(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil).AnyTimes()
+	customerSvc := NewCustomerService(customerRepo, customerCache)
 	idemSvc := NewIdempotencyService[domain.Order](idemCache, 15*time.Minute, 50*time.Millisecond, 500*time.Millisecond)
 
-	svc := NewOrderService(orderRepo, productSvc, customerSvc, orderCache, idemSvc, txManager)
+	quoteSigner := NewQuoteSigner([]byte("test-secret"), 10*time.Minute)
+
+	svc := NewOrderService(orderRepo, productSvc, reservationSvc, customerSvc, orderCache, idemSvc, txManager, quoteSigner)
 
 	return svc, &orderMocks{
 		orderRepo:    orderRepo,
 		productSvc:   productSvc,
 		productRepo:  productRepo,
+		reservations: reservationRepo,
+		movements:    stockMovements,
 		customerSvc:  customerSvc,
 		customerRepo: customerRepo,
 		orderCache:   orderCache,
 		idemCache:    idemCache,
 		txManager:    txManager,
+		quoteSigner:  quoteSigner,
 	}
 }
 
@@ -196,25 +230,220 @@ func TestOrderService_UpdateOrderStatus(t *testing.T) {
 			GetByID(gomock.Any(), orderID).
 			Return(existingOrder, nil)
 
+		m.txManager.EXPECT().
+			WithTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
+
 		m.orderRepo.EXPECT().
-			UpdateStatusWithOutbox(gomock.Any(), orderID, domain.OrderStatusProcessing, gomock.Any()).
+			UpdateStatusWithOutbox(gomock.Any(), orderID, gomock.Any(), statusChangeTo(domain.OrderStatusProcessing), gomock.Any()).
 			Return(nil)
 
+		reservation := &domain.Reservation{
+			ID:        "eeffaabbee112233aabbccd1",
+			OrderID:   orderID,
+			ProductID: "aabbccddee112233aabbccd1",
+			Quantity:  2,
+			Status:    domain.ReservationStatusActive,
+		}
+		m.reservations.EXPECT().
+			GetByOrderID(gomock.Any(), orderID).
+			Return([]*domain.Reservation{reservation}, nil)
+		m.reservations.EXPECT().
+			UpdateStatus(gomock.Any(), reservation.ID, domain.ReservationStatusActive, domain.ReservationStatusCommitted).
+			Return(nil)
+		m.productRepo.EXPECT().
+			CommitReservedStock(gomock.Any(), reservation.ProductID, "", 2).
+			Return(nil)
+		m.movements.EXPECT().
+			Create(gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		m.orderCache.EXPECT().
+			Set(gomock.Any(), "order:"+string(orderID), gomock.Any(), orderCacheTTL).
+			Return(nil)
+
+		err := svc.UpdateOrderStatus(context.Background(), orderID, domain.OrderStatusProcessing, nil, "", "")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("records status change with actor and reason", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+		orderID := domain.ID("aabbccddee112233aabbccdd")
+		existingOrder := &domain.Order{
+			ID:      orderID,
+			Status:  domain.OrderStatusProcessing,
+			Version: 3,
+		}
+
+		m.orderRepo.EXPECT().
+			GetByID(gomock.Any(), orderID).
+			Return(existingOrder, nil)
+
+		m.txManager.EXPECT().
+			WithTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
+
+		m.orderRepo.EXPECT().
+			UpdateStatusWithOutbox(gomock.Any(), orderID, gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ domain.ID, expectedVersion int64, change domain.OrderStatusChange, _ domain.Event) error {
+				if expectedVersion != 3 {
+					t.Fatalf("expected update conditioned on version 3, got %d", expectedVersion)
+				}
+				if change.From != domain.OrderStatusProcessing || change.To != domain.OrderStatusShipped {
+					t.Fatalf("expected processing -> shipped, got %s -> %s", change.From, change.To)
+				}
+				if change.Actor != "warehouse-1" || change.Reason != "picked up by carrier" {
+					t.Fatalf("unexpected actor/reason: %q / %q", change.Actor, change.Reason)
+				}
+				return nil
+			})
+
+		m.orderCache.EXPECT().
+			Set(gomock.Any(), "order:"+string(orderID), gomock.Any(), orderCacheTTL).
+			DoAndReturn(func(_ context.Context, _ string, order *domain.Order, _ time.Duration) error {
+				if order.Status != domain.OrderStatusShipped {
+					t.Fatalf("expected cached status 'shipped', got %q", order.Status)
+				}
+				if len(order.StatusHistory) != 1 || order.StatusHistory[0].To != domain.OrderStatusShipped {
+					t.Fatalf("expected cached history with the new change, got %+v", order.StatusHistory)
+				}
+				if order.Version != 4 {
+					t.Fatalf("expected cached version 4, got %d", order.Version)
+				}
+				return nil
+			})
+
+		err := svc.UpdateOrderStatus(context.Background(), orderID, domain.OrderStatusShipped, nil, "warehouse-1", "picked up by carrier")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("stale expected version returns conflict", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+		orderID := domain.ID("aabbccddee112233aabbccdd")
+		existingOrder := &domain.Order{
+			ID:      orderID,
+			Status:  domain.OrderStatusCreated,
+			Version: 2,
+		}
+		staleVersion := int64(1)
+
+		m.orderRepo.EXPECT().
+			GetByID(gomock.Any(), orderID).
+			Return(existingOrder, nil)
+
+		err := svc.UpdateOrderStatus(context.Background(), orderID, domain.OrderStatusProcessing, []int64{staleVersion}, "", "")
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			t.Fatalf("expected KindConflict, got %v", err)
+		}
+	})
+
+	t.Run("accepts any of the expected versions", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+		orderID := domain.ID("aabbccddee112233aabbccdd")
+		existingOrder := &domain.Order{
+			ID:      orderID,
+			Status:  domain.OrderStatusProcessing,
+			Version: 2,
+		}
+
+		m.orderRepo.EXPECT().
+			GetByID(gomock.Any(), orderID).
+			Return(existingOrder, nil)
+		m.txManager.EXPECT().
+			WithTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
+		m.orderRepo.EXPECT().
+			UpdateStatusWithOutbox(gomock.Any(), orderID, int64(2), statusChangeTo(domain.OrderStatusShipped), gomock.Any()).
+			Return(nil)
 		m.orderCache.EXPECT().
 			Set(gomock.Any(), "order:"+string(orderID), gomock.Any(), orderCacheTTL).
 			Return(nil)
 
-		err := svc.UpdateOrderStatus(context.Background(), orderID, domain.OrderStatusProcessing)
+		err := svc.UpdateOrderStatus(context.Background(), orderID, domain.OrderStatusShipped, []int64{1, 2}, "", "")
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
 	})
 
+	t.Run("no expected version matches", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+		orderID := domain.ID("aabbccddee112233aabbccdd")
+
+		m.orderRepo.EXPECT().
+			GetByID(gomock.Any(), orderID).
+			Return(&domain.Order{ID: orderID, Status: domain.OrderStatusCreated, Version: 2}, nil)
+
+		err := svc.UpdateOrderStatus(context.Background(), orderID, domain.OrderStatusProcessing, []int64{}, "", "")
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			t.Fatalf("expected KindConflict, got %v", err)
+		}
+	})
+
+	t.Run("stale expected version on cancel returns conflict", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+		orderID := domain.ID("aabbccddee112233aabbccdd")
+		existingOrder := &domain.Order{
+			ID:      orderID,
+			Status:  domain.OrderStatusCreated,
+			Version: 2,
+		}
+		staleVersion := int64(1)
+
+		m.orderRepo.EXPECT().
+			GetByID(gomock.Any(), orderID).
+			Return(existingOrder, nil)
+
+		err := svc.UpdateOrderStatus(context.Background(), orderID, domain.OrderStatusCancelled, []int64{staleVersion}, "", "")
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			t.Fatalf("expected KindConflict, got %v", err)
+		}
+	})
+
+	t.Run("concurrent update conflict is returned and cache untouched", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+		orderID := domain.ID("aabbccddee112233aabbccdd")
+		existingOrder := &domain.Order{
+			ID:      orderID,
+			Status:  domain.OrderStatusCreated,
+			Version: 2,
+		}
+		currentVersion := int64(2)
+
+		m.orderRepo.EXPECT().
+			GetByID(gomock.Any(), orderID).
+			Return(existingOrder, nil)
+
+		m.txManager.EXPECT().
+			WithTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
+
+		m.orderRepo.EXPECT().
+			UpdateStatusWithOutbox(gomock.Any(), orderID, int64(2), statusChangeTo(domain.OrderStatusProcessing), gomock.Any()).
+			Return(serviceerrors.NewConflictError("order was modified by another request"))
+
+		err := svc.UpdateOrderStatus(context.Background(), orderID, domain.OrderStatusProcessing, []int64{currentVersion}, "", "")
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			t.Fatalf("expected KindConflict, got %v", err)
+		}
+	})
+
 	t.Run("invalid status", func(t *testing.T) {
 		svc, _ := setupOrderService(t)
 		orderID := domain.ID("aabbccddee112233aabbccdd")
 
-		err := svc.UpdateOrderStatus(context.Background(), orderID, domain.OrderStatus("invalid"))
+		err := svc.UpdateOrderStatus(context.Background(), orderID, domain.OrderStatus("invalid"), nil, "", "")
 		if err == nil {
 			t.Fatal("expected error, got nil")
 		}
@@ -235,7 +464,7 @@ func TestOrderService_UpdateOrderStatus(t *testing.T) {
 			GetByID(gomock.Any(), orderID).
 			Return(existingOrder, nil)
 
-		err := svc.UpdateOrderStatus(context.Background(), orderID, domain.OrderStatusProcessing)
+		err := svc.UpdateOrderStatus(context.Background(), orderID, domain.OrderStatusProcessing, nil, "", "")
 		if err == nil {
 			t.Fatal("expected error, got nil")
 		}
@@ -244,6 +473,54 @@ func TestOrderService_UpdateOrderStatus(t *testing.T) {
 		}
 	})
 
+	t.Run("transition not allowed", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+		orderID := domain.ID("aabbccddee112233aabbccdd")
+		existingOrder := &domain.Order{
+			ID:     orderID,
+			Status: domain.OrderStatusDelivered,
+		}
+
+		m.orderRepo.EXPECT().
+			GetByID(gomock.Any(), orderID).
+			Return(existingOrder, nil)
+
+		err := svc.UpdateOrderStatus(context.Background(), orderID, domain.OrderStatusCreated, nil, "", "")
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidTransition) {
+			t.Fatalf("expected KindInvalidTransition, got %v", err)
+		}
+	})
+
+	t.Run("transition error lists allowed statuses", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+		orderID := domain.ID("aabbccddee112233aabbccdd")
+		existingOrder := &domain.Order{
+			ID:     orderID,
+			Status: domain.OrderStatusCreated,
+		}
+
+		m.orderRepo.EXPECT().
+			GetByID(gomock.Any(), orderID).
+			Return(existingOrder, nil)
+
+		err := svc.UpdateOrderStatus(context.Background(), orderID, domain.OrderStatusShipped, nil, "", "")
+		var svcErr *serviceerrors.ServiceError
+		if !errors.As(err, &svcErr) {
+			t.Fatalf("expected ServiceError, got %v", err)
+		}
+		details, ok := svcErr.Details.(map[string]any)
+		if !ok {
+			t.Fatalf("expected details map, got %T", svcErr.Details)
+		}
+		allowed, ok := details["allowed_statuses"].([]string)
+		if !ok || len(allowed) != 2 || allowed[0] != "processing" || allowed[1] != "cancelled" {
+			t.Fatalf("expected allowed statuses [processing cancelled], got %v", details["allowed_statuses"])
+		}
+	})
+
 	t.Run("order not found", func(t *testing.T) {
 		svc, m := setupOrderService(t)
 		orderID := domain.ID("aabbccddee112233aabbccdd")
@@ -252,7 +529,7 @@ func TestOrderService_UpdateOrderStatus(t *testing.T) {
 			GetByID(gomock.Any(), orderID).
 			Return(nil, serviceerrors.NewNotFoundError("order not found"))
 
-		err := svc.UpdateOrderStatus(context.Background(), orderID, domain.OrderStatusProcessing)
+		err := svc.UpdateOrderStatus(context.Background(), orderID, domain.OrderStatusProcessing, nil, "", "")
 		if err == nil {
 			t.Fatal("expected error, got nil")
 		}
@@ -261,6 +538,51 @@ func TestOrderService_UpdateOrderStatus(t *testing.T) {
 		}
 	})
 
+	t.Run("commit fails when expired reservation stock is gone", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+		orderID := domain.ID("aabbccddee112233aabbccdd")
+		existingOrder := &domain.Order{
+			ID:     orderID,
+			Status: domain.OrderStatusCreated,
+		}
+		released := &domain.Reservation{
+			ID:        "eeffaabbee112233aabbccd1",
+			OrderID:   orderID,
+			ProductID: "aabbccddee112233aabbccd1",
+			Quantity:  2,
+			Status:    domain.ReservationStatusReleased,
+		}
+
+		m.orderRepo.EXPECT().
+			GetByID(gomock.Any(), orderID).
+			Return(existingOrder, nil)
+
+		m.txManager.EXPECT().
+			WithTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
+
+		m.orderRepo.EXPECT().
+			UpdateStatusWithOutbox(gomock.Any(), orderID, gomock.Any(), statusChangeTo(domain.OrderStatusProcessing), gomock.Any()).
+			Return(nil)
+
+		m.reservations.EXPECT().
+			GetByOrderID(gomock.Any(), orderID).
+			Return([]*domain.Reservation{released}, nil)
+		m.reservations.EXPECT().
+			UpdateStatus(gomock.Any(), released.ID, domain.ReservationStatusReleased, domain.ReservationStatusCommitted).
+			Return(nil)
+		m.productRepo.EXPECT().
+			DeductStockBatch(gomock.Any(), []port.StockLine{{ProductID: released.ProductID, Quantity: 2}}, gomock.Any()).
+			Return(serviceerrors.NewUnprocessableEntityError("insufficient stock"))
+
+		err := svc.UpdateOrderStatus(context.Background(), orderID, domain.OrderStatusProcessing, nil, "", "")
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindUnprocessableEntity) {
+			t.Fatalf("expected KindUnprocessableEntity, got %v", err)
+		}
+	})
+
 	t.Run("update repo error", func(t *testing.T) {
 		svc, m := setupOrderService(t)
 		orderID := domain.ID("aabbccddee112233aabbccdd")
@@ -273,11 +595,17 @@ func TestOrderService_UpdateOrderStatus(t *testing.T) {
 			GetByID(gomock.Any(), orderID).
 			Return(existingOrder, nil)
 
+		m.txManager.EXPECT().
+			WithTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
+
 		m.orderRepo.EXPECT().
-			UpdateStatusWithOutbox(gomock.Any(), orderID, domain.OrderStatusProcessing, gomock.Any()).
+			UpdateStatusWithOutbox(gomock.Any(), orderID, gomock.Any(), statusChangeTo(domain.OrderStatusProcessing), gomock.Any()).
 			Return(errors.New("db error"))
 
-		err := svc.UpdateOrderStatus(context.Background(), orderID, domain.OrderStatusProcessing)
+		err := svc.UpdateOrderStatus(context.Background(), orderID, domain.OrderStatusProcessing, nil, "", "")
 		if err == nil {
 			t.Fatal("expected error, got nil")
 		}
@@ -291,46 +619,834 @@ func TestOrderService_UpdateOrderStatus(t *testing.T) {
 			Status: domain.OrderStatusCreated,
 		}
 
-		m.orderRepo.EXPECT().
-			GetByID(gomock.Any(), orderID).
-			Return(existingOrder, nil)
+		m.orderRepo.EXPECT().
+			GetByID(gomock.Any(), orderID).
+			Return(existingOrder, nil)
+
+		m.txManager.EXPECT().
+			WithTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
+
+		m.orderRepo.EXPECT().
+			UpdateStatusWithOutbox(gomock.Any(), orderID, gomock.Any(), statusChangeTo(domain.OrderStatusProcessing), gomock.Any()).
+			Return(nil)
+
+		m.reservations.EXPECT().
+			GetByOrderID(gomock.Any(), orderID).
+			Return(nil, nil)
+
+		m.orderCache.EXPECT().
+			Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(errors.New("cache error"))
+
+		err := svc.UpdateOrderStatus(context.Background(), orderID, domain.OrderStatusProcessing, nil, "", "")
+		if err != nil {
+			t.Fatalf("expected no error (cache failure non-fatal), got %v", err)
+		}
+	})
+}
+
+// --- CancelOrder ---
+
+func TestOrderService_CancelOrder(t *testing.T) {
+	orderID := domain.ID("aabbccddee112233aabbccdd")
+	customerID := domain.ID("ccddaabbee112233aabbccdd")
+	productID1 := domain.ID("aabbccddee112233aabbccd1")
+	productID2 := domain.ID("aabbccddee112233aabbccd2")
+
+	newOrder := func(status domain.OrderStatus) *domain.Order {
+		return &domain.Order{
+			ID:         orderID,
+			CustomerID: customerID,
+			Status:     status,
+			Items: []domain.OrderItem{
+				{ProductID: productID1, Quantity: 2},
+				{ProductID: productID2, Quantity: 3},
+			},
+		}
+	}
+
+	t.Run("success - restores stock and writes event in one transaction", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+
+		m.orderRepo.EXPECT().
+			GetByID(gomock.Any(), orderID).
+			Return(newOrder(domain.OrderStatusProcessing), nil)
+
+		m.txManager.EXPECT().
+			WithTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
+
+		m.orderRepo.EXPECT().
+			UpdateStatusWithOutbox(gomock.Any(), orderID, gomock.Any(), statusChangeTo(domain.OrderStatusCancelled), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ domain.ID, _ int64, _ domain.OrderStatusChange, event domain.Event) error {
+				cancelled, ok := event.(*domain.OrderCancelledEvent)
+				if !ok {
+					t.Fatalf("expected *domain.OrderCancelledEvent, got %T", event)
+				}
+				if cancelled.Reason != "changed my mind" {
+					t.Fatalf("expected reason 'changed my mind', got %q", cancelled.Reason)
+				}
+				if cancelled.OldStatus != domain.OrderStatusProcessing {
+					t.Fatalf("expected old status 'processing', got %q", cancelled.OldStatus)
+				}
+				return nil
+			})
+
+		m.productRepo.EXPECT().
+			RestoreStock(gomock.Any(), productID1, "", 2).
+			Return(nil)
+		m.productRepo.EXPECT().
+			RestoreStock(gomock.Any(), productID2, "", 3).
+			Return(nil)
+
+		m.movements.EXPECT().
+			Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, movement *domain.StockMovement) error {
+				if movement.OrderID != orderID || movement.Reason != domain.StockMovementReasonCancellation {
+					t.Fatalf("unexpected movement %+v", movement)
+				}
+				return nil
+			}).
+			Times(2)
+		m.orderCache.EXPECT().
+			Set(gomock.Any(), "order:"+string(orderID), gomock.Any(), orderCacheTTL).
+			Return(nil)
+
+		order, err := svc.CancelOrder(context.Background(), orderID, "", "changed my mind")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if order.Status != domain.OrderStatusCancelled {
+			t.Fatalf("expected status 'cancelled', got %q", order.Status)
+		}
+	})
+
+	t.Run("already cancelled", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+
+		m.orderRepo.EXPECT().
+			GetByID(gomock.Any(), orderID).
+			Return(newOrder(domain.OrderStatusCancelled), nil)
+
+		_, err := svc.CancelOrder(context.Background(), orderID, "", "reason")
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindUnprocessableEntity) {
+			t.Fatalf("expected KindUnprocessableEntity, got %v", err)
+		}
+	})
+
+	t.Run("shipped order cannot be cancelled", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+
+		m.orderRepo.EXPECT().
+			GetByID(gomock.Any(), orderID).
+			Return(newOrder(domain.OrderStatusShipped), nil)
+
+		_, err := svc.CancelOrder(context.Background(), orderID, "", "reason")
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidTransition) {
+			t.Fatalf("expected KindInvalidTransition, got %v", err)
+		}
+	})
+
+	t.Run("order not found", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+
+		m.orderRepo.EXPECT().
+			GetByID(gomock.Any(), orderID).
+			Return(nil, serviceerrors.NewNotFoundError("entity not found"))
+
+		_, err := svc.CancelOrder(context.Background(), orderID, "", "reason")
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+
+	t.Run("restore stock fails inside transaction", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+
+		m.orderRepo.EXPECT().
+			GetByID(gomock.Any(), orderID).
+			Return(newOrder(domain.OrderStatusProcessing), nil)
+
+		m.txManager.EXPECT().
+			WithTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
+
+		m.orderRepo.EXPECT().
+			UpdateStatusWithOutbox(gomock.Any(), orderID, gomock.Any(), statusChangeTo(domain.OrderStatusCancelled), gomock.Any()).
+			Return(nil)
+
+		m.productRepo.EXPECT().
+			RestoreStock(gomock.Any(), productID1, "", 2).
+			Return(errors.New("db error"))
+
+		_, err := svc.CancelOrder(context.Background(), orderID, "", "reason")
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+
+	t.Run("created order without reservations restores its stock", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+
+		m.orderRepo.EXPECT().
+			GetByID(gomock.Any(), orderID).
+			Return(newOrder(domain.OrderStatusCreated), nil)
+
+		m.txManager.EXPECT().
+			WithTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
+
+		m.orderRepo.EXPECT().
+			UpdateStatusWithOutbox(gomock.Any(), orderID, gomock.Any(), statusChangeTo(domain.OrderStatusCancelled), gomock.Any()).
+			Return(nil)
+
+		m.reservations.EXPECT().
+			GetByOrderID(gomock.Any(), orderID).
+			Return(nil, nil)
+		m.productRepo.EXPECT().
+			RestoreStock(gomock.Any(), productID1, "", 2).
+			Return(nil)
+		m.productRepo.EXPECT().
+			RestoreStock(gomock.Any(), productID2, "", 3).
+			Return(nil)
+
+		m.movements.EXPECT().
+			Create(gomock.Any(), gomock.Any()).
+			Return(nil).
+			Times(2)
+		m.orderCache.EXPECT().
+			Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		if _, err := svc.CancelOrder(context.Background(), orderID, "", "reason"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("status update to cancelled uses the cancel flow", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+
+		m.orderRepo.EXPECT().
+			GetByID(gomock.Any(), orderID).
+			Return(newOrder(domain.OrderStatusCreated), nil)
+
+		m.txManager.EXPECT().
+			WithTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
+
+		m.orderRepo.EXPECT().
+			UpdateStatusWithOutbox(gomock.Any(), orderID, gomock.Any(), statusChangeTo(domain.OrderStatusCancelled), gomock.AssignableToTypeOf(&domain.OrderCancelledEvent{})).
+			Return(nil)
+
+		reservations := []*domain.Reservation{
+			{ID: "eeffaabbee112233aabbccd1", OrderID: orderID, ProductID: productID1, Quantity: 2, Status: domain.ReservationStatusActive},
+			{ID: "eeffaabbee112233aabbccd2", OrderID: orderID, ProductID: productID2, Quantity: 3, Status: domain.ReservationStatusReleased},
+		}
+		m.reservations.EXPECT().
+			GetByOrderID(gomock.Any(), orderID).
+			Return(reservations, nil)
+		m.reservations.EXPECT().
+			UpdateStatus(gomock.Any(), reservations[0].ID, domain.ReservationStatusActive, domain.ReservationStatusReleased).
+			Return(nil)
+		m.productRepo.EXPECT().
+			ReleaseReservedStock(gomock.Any(), productID1, "", 2).
+			Return(nil)
+
+		m.orderCache.EXPECT().
+			Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		err := svc.UpdateOrderStatus(context.Background(), orderID, domain.OrderStatusCancelled, nil, "", "")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+}
+
+// --- GetOrderStatusHistory ---
+
+func TestOrderService_GetOrderStatusHistory(t *testing.T) {
+	t.Run("returns history of the order", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+		orderID := domain.ID("aabbccddee112233aabbccdd")
+		cachedOrder := &domain.Order{
+			ID:     orderID,
+			Status: domain.OrderStatusProcessing,
+			StatusHistory: []domain.OrderStatusChange{
+				{From: domain.OrderStatusCreated, To: domain.OrderStatusProcessing, Actor: "ops"},
+			},
+		}
+
+		m.orderCache.EXPECT().
+			Get(gomock.Any(), "order:"+string(orderID)).
+			Return(cachedOrder, nil)
+
+		history, err := svc.GetOrderStatusHistory(context.Background(), orderID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(history) != 1 || history[0].Actor != "ops" {
+			t.Fatalf("expected one change by 'ops', got %+v", history)
+		}
+	})
+
+	t.Run("order not found", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+		orderID := domain.ID("aabbccddee112233aabbccdd")
+
+		m.orderCache.EXPECT().
+			Get(gomock.Any(), gomock.Any()).
+			Return(nil, nil)
+
+		m.orderRepo.EXPECT().
+			GetByID(gomock.Any(), orderID).
+			Return(nil, serviceerrors.NewNotFoundError("entity not found"))
+
+		_, err := svc.GetOrderStatusHistory(context.Background(), orderID)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+}
+
+// --- ListOrders ---
+
+func TestOrderService_ListOrders(t *testing.T) {
+	t.Run("applies defaults and builds filter", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+		minTotal, maxTotal := 1000, 5000
+		req := &dto.ListOrdersRequest{
+			CustomerID:   "ccddaabbee112233aabbccdd",
+			Status:       domain.OrderStatusCreated,
+			MinTotal:     &minTotal,
+			MaxTotal:     &maxTotal,
+			IncludeTotal: true,
+		}
+		total := int64(1)
+		expected := &port.Page[*domain.Order]{
+			Items: []*domain.Order{{ID: "aabbccddee112233aabbccdd"}},
+			Total: &total,
+		}
+
+		m.orderRepo.EXPECT().
+			List(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, filter port.OrderFilter, page port.PageRequest) (*port.Page[*domain.Order], error) {
+				if filter.CustomerID != req.CustomerID || filter.Status != req.Status {
+					t.Fatalf("unexpected filter %+v", filter)
+				}
+				if filter.MinTotal == nil || *filter.MinTotal != domain.Amount(1000) {
+					t.Fatalf("expected min total 1000, got %v", filter.MinTotal)
+				}
+				if filter.MaxTotal == nil || *filter.MaxTotal != domain.Amount(5000) {
+					t.Fatalf("expected max total 5000, got %v", filter.MaxTotal)
+				}
+				if page.Limit != orderListDefaultLimit {
+					t.Fatalf("expected default limit %d, got %d", orderListDefaultLimit, page.Limit)
+				}
+				if page.SortBy != port.OrderSortCreatedAt || !page.Descending {
+					t.Fatalf("expected created_at desc, got %s desc=%v", page.SortBy, page.Descending)
+				}
+				if !page.IncludeTotal {
+					t.Fatal("expected include total to be forwarded")
+				}
+				return expected, nil
+			})
+
+		page, err := svc.ListOrders(context.Background(), req)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(page.Items) != 1 || page.Total == nil || *page.Total != 1 {
+			t.Fatalf("unexpected page %+v", page)
+		}
+	})
+
+	t.Run("ascending sort by total amount", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+		req := &dto.ListOrdersRequest{Sort: "total_amount", Order: "asc", Limit: 5, Cursor: "abc"}
+
+		m.orderRepo.EXPECT().
+			List(gomock.Any(), gomock.Any(), port.PageRequest{Limit: 5, Cursor: "abc", SortBy: port.OrderSortTotalAmount}).
+			Return(&port.Page[*domain.Order]{}, nil)
+
+		if _, err := svc.ListOrders(context.Background(), req); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("customer only uses GetByCustomerID", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+		customerID := domain.ID("ccddaabbee112233aabbccdd")
+		req := &dto.ListOrdersRequest{CustomerID: customerID, Status: domain.OrderStatusProcessing, Cursor: "abc"}
+
+		m.orderRepo.EXPECT().
+			GetByCustomerID(gomock.Any(), customerID, domain.OrderStatusProcessing, int64(orderListDefaultLimit), "abc").
+			Return(&port.Page[*domain.Order]{}, nil)
+
+		if _, err := svc.ListOrders(context.Background(), req); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("status only uses GetByStatus", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+		req := &dto.ListOrdersRequest{Status: domain.OrderStatusShipped, Limit: 10}
+
+		m.orderRepo.EXPECT().
+			GetByStatus(gomock.Any(), domain.OrderStatusShipped, int64(10), "").
+			Return(&port.Page[*domain.Order]{}, nil)
+
+		if _, err := svc.ListOrders(context.Background(), req); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	invalid := []struct {
+		name string
+		req  *dto.ListOrdersRequest
+	}{
+		{"invalid customer ID", &dto.ListOrdersRequest{CustomerID: "bad-id"}},
+		{"invalid status", &dto.ListOrdersRequest{Status: "unknown"}},
+		{"invalid sort field", &dto.ListOrdersRequest{Sort: "customer_id"}},
+		{"inverted date range", &dto.ListOrdersRequest{
+			CreatedFrom: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			CreatedTo:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		}},
+		{"inverted total range", &dto.ListOrdersRequest{MinTotal: intPtr(500), MaxTotal: intPtr(100)}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _ := setupOrderService(t)
+
+			_, err := svc.ListOrders(context.Background(), tt.req)
+			if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+				t.Fatalf("expected KindInvalidRequest, got %v", err)
+			}
+		})
+	}
+
+	t.Run("repository error", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+
+		m.orderRepo.EXPECT().
+			List(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, errors.New("db error"))
+
+		if _, err := svc.ListOrders(context.Background(), &dto.ListOrdersRequest{}); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+// --- QuoteOrder ---
+
+func TestOrderService_ListCustomerOrders(t *testing.T) {
+	customerID := domain.ID("ccddaabbee112233aabbccdd")
+
+	t.Run("pages newest first with default limit and status filter", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+		expected := &port.Page[*domain.Order]{
+			Items:      []*domain.Order{{ID: "aabbccddee112233aabbccdd", CustomerID: customerID}},
+			NextCursor: "next",
+		}
+
+		m.customerRepo.EXPECT().GetByID(gomock.Any(), customerID).Return(&domain.Customer{ID: customerID}, nil)
+		m.orderRepo.EXPECT().
+			GetByCustomerID(gomock.Any(), customerID, domain.OrderStatusShipped, int64(orderListDefaultLimit), "abc").
+			Return(expected, nil)
+
+		page, err := svc.ListCustomerOrders(context.Background(), customerID, &dto.ListCustomerOrdersRequest{
+			Status: domain.OrderStatusShipped,
+			Cursor: "abc",
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if page != expected {
+			t.Fatalf("expected repository page, got %+v", page)
+		}
+	})
+
+	t.Run("erased customer's orders stay listable", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+		erasedAt := time.Now()
+		expected := &port.Page[*domain.Order]{Items: []*domain.Order{{ID: "aabbccddee112233aabbccdd", CustomerID: customerID}}}
+
+		m.customerRepo.EXPECT().GetByID(gomock.Any(), customerID).Return(&domain.Customer{ID: customerID, ErasedAt: &erasedAt}, nil)
+		m.orderRepo.EXPECT().
+			GetByCustomerID(gomock.Any(), customerID, domain.OrderStatus(""), int64(orderListDefaultLimit), "").
+			Return(expected, nil)
+
+		page, err := svc.ListCustomerOrders(context.Background(), customerID, &dto.ListCustomerOrdersRequest{})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if page != expected {
+			t.Fatalf("expected repository page, got %+v", page)
+		}
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		svc, _ := setupOrderService(t)
+
+		_, err := svc.ListCustomerOrders(context.Background(), customerID, &dto.ListCustomerOrdersRequest{Status: "lost"})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+
+	t.Run("customer not found", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+
+		m.customerRepo.EXPECT().
+			GetByID(gomock.Any(), customerID).
+			Return(nil, serviceerrors.NewNotFoundError("entity not found"))
+
+		_, err := svc.ListCustomerOrders(context.Background(), customerID, &dto.ListCustomerOrdersRequest{})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+}
+
+func TestOrderService_QuoteOrder(t *testing.T) {
+	productID := domain.ID("aabbccddee112233aabbccd1")
+	otherProductID := domain.ID("aabbccddee112233aabbccd2")
+
+	t.Run("prices items and reports availability", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+		req := &dto.QuoteOrderRequest{
+			Items: []dto.OrderItem{
+				{ProductID: productID, Quantity: 2},
+				{ProductID: otherProductID, Quantity: 5},
+			},
+		}
+
+		m.productRepo.EXPECT().
+			GetByIDs(gomock.Any(), []domain.ID{productID, otherProductID}).
+			Return([]*domain.Product{
+				{ID: otherProductID, Name: "B", Price: 250, Stock: 3},
+				{ID: productID, Name: "A", Price: 1000, Stock: 10},
+			}, nil)
+
+		quote, err := svc.QuoteOrder(context.Background(), req)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if quote.TotalAmount != domain.Amount(3250) {
+			t.Fatalf("expected total 3250, got %d", quote.TotalAmount)
+		}
+		if quote.Items[0].LineTotal != domain.Amount(2000) || !quote.Items[0].IsAvailable() {
+			t.Fatalf("unexpected first item %+v", quote.Items[0])
+		}
+		if quote.Items[1].IsAvailable() || quote.IsAvailable() {
+			t.Fatal("expected second item and quote to be unavailable")
+		}
+		if quote.Token == "" || !quote.ExpiresAt.After(time.Now()) {
+			t.Fatalf("expected a token with a future expiry, got %q / %v", quote.Token, quote.ExpiresAt)
+		}
+	})
+
+	t.Run("product not found", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+
+		m.productRepo.EXPECT().
+			GetByIDs(gomock.Any(), []domain.ID{productID}).
+			Return([]*domain.Product{}, nil)
+
+		_, err := svc.QuoteOrder(context.Background(), &dto.QuoteOrderRequest{
+			Items: []dto.OrderItem{{ProductID: productID, Quantity: 1}},
+		})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+
+	t.Run("exceeds max items", func(t *testing.T) {
+		svc, _ := setupOrderService(t)
+
+		_, err := svc.QuoteOrder(context.Background(), &dto.QuoteOrderRequest{
+			Items: make([]dto.OrderItem, ORDER_MAX_ITEMS+1),
+		})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindUnprocessableEntity) {
+			t.Fatalf("expected KindUnprocessableEntity, got %v", err)
+		}
+	})
+}
+
+// --- CreateOrder (processOrder) ---
+
+func TestOrderService_CreateOrder(t *testing.T) {
+	customerID := domain.ID("ccddaabbee112233aabbccdd")
+	productID := domain.ID("aabbccddee112233aabbccd1")
+
+	validRequest := &dto.CreateOrderRequest{
+		CustomerID: customerID,
+		Items: []dto.OrderItem{
+			{ProductID: productID, Quantity: 2},
+		},
+	}
+
+	product := &domain.Product{
+		ID:    productID,
+		Name:  "Test Product",
+		Price: domain.Amount(2999),
+		Stock: 50,
+	}
+
+	t.Run("success without idempotency key", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+
+		m.customerRepo.EXPECT().
+			Exists(gomock.Any(), customerID).
+			Return(true, nil)
+
+		m.productRepo.EXPECT().
+			GetByIDs(gomock.Any(), []domain.ID{productID}).
+			Return([]*domain.Product{product}, nil)
+
+		m.txManager.EXPECT().
+			WithTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
+
+		m.productRepo.EXPECT().
+			ReserveStockBatch(gomock.Any(), []port.StockLine{{ProductID: productID, Quantity: 2}}, gomock.Any()).
+			Return(nil)
+
+		m.reservations.EXPECT().
+			Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, reservations []*domain.Reservation) error {
+				if len(reservations) != 1 {
+					t.Fatalf("expected 1 reservation, got %d", len(reservations))
+				}
+				r := reservations[0]
+				if r.OrderID != "aabbccddee112233aabbccdd" || r.ProductID != productID || r.Quantity != 2 {
+					t.Fatalf("unexpected reservation %+v", r)
+				}
+				if r.Status != domain.ReservationStatusActive || r.ExpiresAt.Before(time.Now().Add(14*time.Minute)) {
+					t.Fatalf("expected active reservation expiring in ~15m, got %s / %v", r.Status, r.ExpiresAt)
+				}
+				return nil
+			})
+
+		m.customerRepo.EXPECT().Lock(gomock.Any(), customerID).Return(nil)
+		m.orderRepo.EXPECT().
+			CreateWithOutbox(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, order *domain.Order, _ func(*domain.Order) domain.Event) error {
+				order.ID = domain.ID("aabbccddee112233aabbccdd")
+				return nil
+			})
+
+		order, err := svc.CreateOrder(context.Background(), "", validRequest)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if order == nil {
+			t.Fatal("expected order, got nil")
+		}
+		if order.CustomerID != customerID {
+			t.Fatalf("expected customer id %s, got %s", customerID, order.CustomerID)
+		}
+		if len(order.Items) != 1 {
+			t.Fatalf("expected 1 item, got %d", len(order.Items))
+		}
+		expectedTotal := domain.Amount(2999).Multiply(2)
+		if order.TotalAmount != expectedTotal {
+			t.Fatalf("expected total %d, got %d", expectedTotal, order.TotalAmount)
+		}
+	})
+
+	t.Run("fails when the customer is erased before the order commits", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+
+		m.customerRepo.EXPECT().
+			Exists(gomock.Any(), customerID).
+			Return(true, nil)
+		m.productRepo.EXPECT().
+			GetByIDs(gomock.Any(), []domain.ID{productID}).
+			Return([]*domain.Product{product}, nil)
+		m.txManager.EXPECT().
+			WithTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
+		m.customerRepo.EXPECT().
+			Lock(gomock.Any(), customerID).
+			Return(serviceerrors.NewNotFoundError("entity not found"))
+
+		_, err := svc.CreateOrder(context.Background(), "", validRequest)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+
+	t.Run("honours quoted prices", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+		token, _, err := m.quoteSigner.sign([]domain.OrderItem{
+			*domain.NewOrderItem(productID, product.Name, 2, domain.Amount(2500)),
+		})
+		if err != nil {
+			t.Fatalf("setup: sign quote failed: %v", err)
+		}
+		req := &dto.CreateOrderRequest{CustomerID: customerID, Items: validRequest.Items, QuoteToken: token}
+
+		m.customerRepo.EXPECT().
+			Exists(gomock.Any(), customerID).
+			Return(true, nil)
+
+		m.productRepo.EXPECT().
+			GetByIDs(gomock.Any(), []domain.ID{productID}).
+			Return([]*domain.Product{product}, nil)
+
+		m.txManager.EXPECT().
+			WithTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
+
+		m.productRepo.EXPECT().
+			ReserveStockBatch(gomock.Any(), []port.StockLine{{ProductID: productID, Quantity: 2}}, gomock.Any()).
+			Return(nil)
+
+		m.reservations.EXPECT().
+			Create(gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		m.customerRepo.EXPECT().Lock(gomock.Any(), customerID).Return(nil)
+		m.orderRepo.EXPECT().
+			CreateWithOutbox(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		order, err := svc.CreateOrder(context.Background(), "", req)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if order.Items[0].UnitPrice != domain.Amount(2500) {
+			t.Fatalf("expected quoted unit price 2500, got %d", order.Items[0].UnitPrice)
+		}
+		if order.TotalAmount != domain.Amount(5000) {
+			t.Fatalf("expected total 5000, got %d", order.TotalAmount)
+		}
+	})
+
+	t.Run("charges the current price when it dropped below the quote", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+		token, _, err := m.quoteSigner.sign([]domain.OrderItem{
+			*domain.NewOrderItem(productID, product.Name, 2, domain.Amount(3500)),
+		})
+		if err != nil {
+			t.Fatalf("setup: sign quote failed: %v", err)
+		}
+		req := &dto.CreateOrderRequest{CustomerID: customerID, Items: validRequest.Items, QuoteToken: token}
+
+		m.customerRepo.EXPECT().
+			Exists(gomock.Any(), customerID).
+			Return(true, nil)
+
+		m.productRepo.EXPECT().
+			GetByIDs(gomock.Any(), []domain.ID{productID}).
+			Return([]*domain.Product{product}, nil)
+
+		m.txManager.EXPECT().
+			WithTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
+
+		m.productRepo.EXPECT().
+			ReserveStockBatch(gomock.Any(), []port.StockLine{{ProductID: productID, Quantity: 2}}, gomock.Any()).
+			Return(nil)
 
-		m.orderRepo.EXPECT().
-			UpdateStatusWithOutbox(gomock.Any(), orderID, domain.OrderStatusShipped, gomock.Any()).
+		m.reservations.EXPECT().
+			Create(gomock.Any(), gomock.Any()).
 			Return(nil)
 
-		m.orderCache.EXPECT().
-			Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-			Return(errors.New("cache error"))
+		m.customerRepo.EXPECT().Lock(gomock.Any(), customerID).Return(nil)
+		m.orderRepo.EXPECT().
+			CreateWithOutbox(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil)
 
-		err := svc.UpdateOrderStatus(context.Background(), orderID, domain.OrderStatusShipped)
+		order, err := svc.CreateOrder(context.Background(), "", req)
 		if err != nil {
-			t.Fatalf("expected no error (cache failure non-fatal), got %v", err)
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if order.Items[0].UnitPrice != domain.Amount(2999) {
+			t.Fatalf("expected current unit price 2999, got %d", order.Items[0].UnitPrice)
+		}
+		if order.TotalAmount != domain.Amount(5998) {
+			t.Fatalf("expected total 5998, got %d", order.TotalAmount)
 		}
 	})
-}
-
-// --- CreateOrder (processOrder) ---
 
-func TestOrderService_CreateOrder(t *testing.T) {
-	customerID := domain.ID("ccddaabbee112233aabbccdd")
-	productID := domain.ID("aabbccddee112233aabbccd1")
-
-	validRequest := &dto.CreateOrderRequest{
-		CustomerID: customerID,
-		Items: []dto.OrderItem{
-			{ProductID: productID, Quantity: 2},
+	quoteFailures := []struct {
+		name  string
+		token func(m *orderMocks) string
+		kind  serviceerrors.ErrorKind
+	}{
+		{
+			name: "rejects tampered quote token",
+			token: func(m *orderMocks) string {
+				token, _, _ := m.quoteSigner.sign([]domain.OrderItem{*domain.NewOrderItem(productID, product.Name, 2, 2500)})
+				return token + "x"
+			},
+			kind: serviceerrors.KindInvalidRequest,
+		},
+		{
+			name: "rejects expired quote token",
+			token: func(m *orderMocks) string {
+				expired := NewQuoteSigner([]byte("test-secret"), -time.Minute)
+				token, _, _ := expired.sign([]domain.OrderItem{*domain.NewOrderItem(productID, product.Name, 2, 2500)})
+				return token
+			},
+			kind: serviceerrors.KindUnprocessableEntity,
+		},
+		{
+			name: "rejects quote for different items",
+			token: func(m *orderMocks) string {
+				token, _, _ := m.quoteSigner.sign([]domain.OrderItem{*domain.NewOrderItem(productID, product.Name, 1, 2500)})
+				return token
+			},
+			kind: serviceerrors.KindUnprocessableEntity,
 		},
 	}
-
-	product := &domain.Product{
-		ID:    productID,
-		Name:  "Test Product",
-		Price: domain.Amount(2999),
-		Stock: 50,
+	for _, tt := range quoteFailures {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, m := setupOrderService(t)
+			req := &dto.CreateOrderRequest{CustomerID: customerID, Items: validRequest.Items, QuoteToken: tt.token(m)}
+
+			m.customerRepo.EXPECT().
+				Exists(gomock.Any(), customerID).
+				Return(true, nil)
+
+			m.productRepo.EXPECT().
+				GetByIDs(gomock.Any(), []domain.ID{productID}).
+				Return([]*domain.Product{product}, nil)
+
+			_, err := svc.CreateOrder(context.Background(), "", req)
+			if !serviceerrors.IsOfKind(err, tt.kind) {
+				t.Fatalf("expected %v, got %v", tt.kind, err)
+			}
+		})
 	}
 
-	t.Run("success without idempotency key", func(t *testing.T) {
+	t.Run("writes order.created event with the persisted order", func(t *testing.T) {
 		svc, m := setupOrderService(t)
 
 		m.customerRepo.EXPECT().
@@ -338,8 +1454,8 @@ func TestOrderService_CreateOrder(t *testing.T) {
 			Return(true, nil)
 
 		m.productRepo.EXPECT().
-			GetByID(gomock.Any(), productID).
-			Return(product, nil)
+			GetByIDs(gomock.Any(), []domain.ID{productID}).
+			Return([]*domain.Product{product}, nil)
 
 		m.txManager.EXPECT().
 			WithTransaction(gomock.Any(), gomock.Any()).
@@ -348,13 +1464,20 @@ func TestOrderService_CreateOrder(t *testing.T) {
 			})
 
 		m.productRepo.EXPECT().
-			DeductStock(gomock.Any(), productID, 2).
+			ReserveStockBatch(gomock.Any(), []port.StockLine{{ProductID: productID, Quantity: 2}}, gomock.Any()).
 			Return(nil)
 
-		m.orderRepo.EXPECT().
+		var event domain.Event
+		m.reservations.EXPECT().
 			Create(gomock.Any(), gomock.Any()).
-			DoAndReturn(func(_ context.Context, order *domain.Order) error {
+			Return(nil)
+
+		m.customerRepo.EXPECT().Lock(gomock.Any(), customerID).Return(nil)
+		m.orderRepo.EXPECT().
+			CreateWithOutbox(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, order *domain.Order, buildEvent func(*domain.Order) domain.Event) error {
 				order.ID = domain.ID("aabbccddee112233aabbccdd")
+				event = buildEvent(order)
 				return nil
 			})
 
@@ -362,18 +1485,22 @@ func TestOrderService_CreateOrder(t *testing.T) {
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
-		if order == nil {
-			t.Fatal("expected order, got nil")
+
+		created, ok := event.(*domain.OrderCreatedEvent)
+		if !ok {
+			t.Fatalf("expected *domain.OrderCreatedEvent, got %T", event)
 		}
-		if order.CustomerID != customerID {
-			t.Fatalf("expected customer id %s, got %s", customerID, order.CustomerID)
+		if created.OrderID != order.ID {
+			t.Fatalf("expected event order id %s, got %s", order.ID, created.OrderID)
 		}
-		if len(order.Items) != 1 {
-			t.Fatalf("expected 1 item, got %d", len(order.Items))
+		if created.CustomerID != customerID {
+			t.Fatalf("expected event customer id %s, got %s", customerID, created.CustomerID)
 		}
-		expectedTotal := domain.Amount(2999).Multiply(2)
-		if order.TotalAmount != expectedTotal {
-			t.Fatalf("expected total %d, got %d", expectedTotal, order.TotalAmount)
+		if created.TotalAmount != order.TotalAmount {
+			t.Fatalf("expected event total %d, got %d", order.TotalAmount, created.TotalAmount)
+		}
+		if len(created.Items) != 1 || created.Items[0].ProductID != productID {
+			t.Fatalf("expected event items to match order, got %+v", created.Items)
 		}
 	})
 
@@ -414,6 +1541,112 @@ func TestOrderService_CreateOrder(t *testing.T) {
 		}
 	})
 
+	expectOrderPlaced := func(m *orderMocks) {
+		m.productRepo.EXPECT().
+			GetByIDs(gomock.Any(), []domain.ID{productID}).
+			Return([]*domain.Product{product}, nil)
+		m.txManager.EXPECT().
+			WithTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
+		m.productRepo.EXPECT().ReserveStockBatch(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		m.reservations.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+		m.customerRepo.EXPECT().Lock(gomock.Any(), customerID).Return(nil)
+		m.orderRepo.EXPECT().
+			CreateWithOutbox(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, order *domain.Order, _ func(*domain.Order) domain.Event) error {
+				order.ID = domain.ID("aabbccddee112233aabbccdd")
+				return nil
+			})
+	}
+
+	t.Run("copies the saved shipping address into the order", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+		addressID := domain.ID("aabbccddee112233aabbcc01")
+		customer := &domain.Customer{
+			ID: customerID,
+			Addresses: []domain.CustomerAddress{
+				{ID: addressID, Label: "home", Address: domain.Address{Recipient: "Ada", Line1: "Rua A, 1", City: "São Paulo", PostalCode: "01000-000", Country: "BR"}},
+			},
+			DefaultAddressID: addressID,
+		}
+
+		m.customerRepo.EXPECT().Exists(gomock.Any(), customerID).Return(true, nil)
+		m.customerRepo.EXPECT().GetByID(gomock.Any(), customerID).Return(customer, nil)
+		expectOrderPlaced(m)
+
+		order, err := svc.CreateOrder(context.Background(), "", &dto.CreateOrderRequest{
+			CustomerID:        customerID,
+			Items:             validRequest.Items,
+			ShippingAddressID: addressID,
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if order.ShippingAddress == nil || *order.ShippingAddress != customer.Addresses[0].Address {
+			t.Fatalf("expected the saved address, got %+v", order.ShippingAddress)
+		}
+
+		customer.Addresses[0].Line1 = "Rua B, 2"
+		if order.ShippingAddress.Line1 != "Rua A, 1" {
+			t.Fatalf("expected the order to keep its own copy, got %q", order.ShippingAddress.Line1)
+		}
+	})
+
+	t.Run("uses an inline shipping address", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+
+		m.customerRepo.EXPECT().Exists(gomock.Any(), customerID).Return(true, nil)
+		expectOrderPlaced(m)
+
+		order, err := svc.CreateOrder(context.Background(), "", &dto.CreateOrderRequest{
+			CustomerID: customerID,
+			Items:      validRequest.Items,
+			ShippingAddress: &dto.AddressRequest{
+				Recipient: " Ada ", Line1: "Rua A, 1", City: "São Paulo", PostalCode: "01000-000", Country: "BR",
+			},
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if order.ShippingAddress == nil || order.ShippingAddress.Recipient != "Ada" || order.ShippingAddress.Country != "BR" {
+			t.Fatalf("expected the inline address, got %+v", order.ShippingAddress)
+		}
+	})
+
+	t.Run("unknown saved shipping address", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+
+		m.customerRepo.EXPECT().Exists(gomock.Any(), customerID).Return(true, nil)
+		m.customerRepo.EXPECT().GetByID(gomock.Any(), customerID).Return(&domain.Customer{ID: customerID}, nil)
+
+		_, err := svc.CreateOrder(context.Background(), "", &dto.CreateOrderRequest{
+			CustomerID:        customerID,
+			Items:             validRequest.Items,
+			ShippingAddressID: "aabbccddee112233aabbcc01",
+		})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+
+	t.Run("saved and inline shipping address together", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+
+		m.customerRepo.EXPECT().Exists(gomock.Any(), customerID).Return(true, nil)
+
+		_, err := svc.CreateOrder(context.Background(), "", &dto.CreateOrderRequest{
+			CustomerID:        customerID,
+			Items:             validRequest.Items,
+			ShippingAddressID: "aabbccddee112233aabbcc01",
+			ShippingAddress:   &dto.AddressRequest{Recipient: "Ada"},
+		})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+
 	t.Run("product not found", func(t *testing.T) {
 		svc, m := setupOrderService(t)
 
@@ -422,8 +1655,8 @@ func TestOrderService_CreateOrder(t *testing.T) {
 			Return(true, nil)
 
 		m.productRepo.EXPECT().
-			GetByID(gomock.Any(), productID).
-			Return(nil, serviceerrors.NewNotFoundError("product not found"))
+			GetByIDs(gomock.Any(), []domain.ID{productID}).
+			Return([]*domain.Product{}, nil)
 
 		_, err := svc.CreateOrder(context.Background(), "", validRequest)
 		if err == nil {
@@ -434,7 +1667,144 @@ func TestOrderService_CreateOrder(t *testing.T) {
 		}
 	})
 
-	t.Run("deduct stock fails inside transaction", func(t *testing.T) {
+	t.Run("reports every missing product at once", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+		missingID := domain.ID("aabbccddee112233aabbccd8")
+		otherMissingID := domain.ID("aabbccddee112233aabbccd9")
+		req := &dto.CreateOrderRequest{
+			CustomerID: customerID,
+			Items: []dto.OrderItem{
+				{ProductID: missingID, Quantity: 1},
+				{ProductID: productID, Quantity: 1},
+				{ProductID: otherMissingID, Quantity: 1},
+				{ProductID: missingID, Quantity: 2},
+			},
+		}
+
+		m.customerRepo.EXPECT().
+			Exists(gomock.Any(), customerID).
+			Return(true, nil)
+
+		m.productRepo.EXPECT().
+			GetByIDs(gomock.Any(), []domain.ID{missingID, productID, otherMissingID}).
+			Return([]*domain.Product{{ID: productID, Name: "Widget", Price: 1000, Stock: 10}}, nil)
+
+		_, err := svc.CreateOrder(context.Background(), "", req)
+		var serviceErr *serviceerrors.ServiceError
+		if !errors.As(err, &serviceErr) || serviceErr.Kind != serviceerrors.KindNotFound {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+		details, ok := serviceErr.Details.(map[string]any)
+		if !ok {
+			t.Fatalf("expected map details, got %T", serviceErr.Details)
+		}
+		missing, _ := details["product_ids"].([]string)
+		if len(missing) != 2 || missing[0] != string(missingID) || missing[1] != string(otherMissingID) {
+			t.Fatalf("expected missing ids [%s %s], got %v", missingID, otherMissingID, details["product_ids"])
+		}
+	})
+
+	t.Run("archived product", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+		archivedAt := time.Now()
+
+		m.customerRepo.EXPECT().
+			Exists(gomock.Any(), customerID).
+			Return(true, nil)
+
+		m.productRepo.EXPECT().
+			GetByIDs(gomock.Any(), []domain.ID{productID}).
+			Return([]*domain.Product{&domain.Product{ID: productID, Name: "Widget", Price: 1000, Stock: 10, ArchivedAt: &archivedAt}}, nil)
+
+		_, err := svc.CreateOrder(context.Background(), "", validRequest)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindUnprocessableEntity) {
+			t.Fatalf("expected KindUnprocessableEntity, got %v", err)
+		}
+	})
+
+	t.Run("orders a variant at its price", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+		largePrice := domain.Amount(3499)
+		tee := &domain.Product{
+			ID:    productID,
+			Name:  "Tee",
+			Price: 2999,
+			Stock: 10,
+			Variants: []domain.ProductVariant{
+				{SKU: "TEE-M", Name: "Medium", Stock: 4},
+				{SKU: "TEE-L", Name: "Large", Price: &largePrice, Stock: 6},
+			},
+		}
+		req := &dto.CreateOrderRequest{
+			CustomerID: customerID,
+			Items:      []dto.OrderItem{{ProductID: productID, SKU: "TEE-L", Quantity: 2}},
+		}
+
+		m.customerRepo.EXPECT().
+			Exists(gomock.Any(), customerID).
+			Return(true, nil)
+
+		m.productRepo.EXPECT().
+			GetByIDs(gomock.Any(), []domain.ID{productID}).
+			Return([]*domain.Product{tee}, nil)
+
+		m.txManager.EXPECT().
+			WithTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
+
+		m.productRepo.EXPECT().
+			ReserveStockBatch(gomock.Any(), []port.StockLine{{ProductID: productID, SKU: "TEE-L", Quantity: 2}}, gomock.Any()).
+			Return(nil)
+
+		m.reservations.EXPECT().
+			Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, reservations []*domain.Reservation) error {
+				if len(reservations) != 1 || reservations[0].SKU != "TEE-L" {
+					t.Fatalf("expected one reservation for TEE-L, got %+v", reservations)
+				}
+				return nil
+			})
+
+		m.customerRepo.EXPECT().Lock(gomock.Any(), customerID).Return(nil)
+		m.orderRepo.EXPECT().
+			CreateWithOutbox(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		order, err := svc.CreateOrder(context.Background(), "", req)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if order.Items[0].SKU != "TEE-L" || order.Items[0].UnitPrice != largePrice {
+			t.Fatalf("expected TEE-L at %d, got %+v", largePrice, order.Items[0])
+		}
+	})
+
+	t.Run("product with variants requires a sku", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+		tee := &domain.Product{
+			ID:       productID,
+			Name:     "Tee",
+			Price:    2999,
+			Stock:    4,
+			Variants: []domain.ProductVariant{{SKU: "TEE-M", Name: "Medium", Stock: 4}},
+		}
+
+		m.customerRepo.EXPECT().
+			Exists(gomock.Any(), customerID).
+			Return(true, nil)
+
+		m.productRepo.EXPECT().
+			GetByIDs(gomock.Any(), []domain.ID{productID}).
+			Return([]*domain.Product{tee}, nil)
+
+		_, err := svc.CreateOrder(context.Background(), "", validRequest)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+	t.Run("reserve stock fails inside transaction", func(t *testing.T) {
 		svc, m := setupOrderService(t)
 
 		m.customerRepo.EXPECT().
@@ -442,8 +1812,8 @@ func TestOrderService_CreateOrder(t *testing.T) {
 			Return(true, nil)
 
 		m.productRepo.EXPECT().
-			GetByID(gomock.Any(), productID).
-			Return(product, nil)
+			GetByIDs(gomock.Any(), []domain.ID{productID}).
+			Return([]*domain.Product{product}, nil)
 
 		m.txManager.EXPECT().
 			WithTransaction(gomock.Any(), gomock.Any()).
@@ -451,8 +1821,13 @@ func TestOrderService_CreateOrder(t *testing.T) {
 				return fn(ctx)
 			})
 
+		m.customerRepo.EXPECT().Lock(gomock.Any(), customerID).Return(nil)
+		m.orderRepo.EXPECT().
+			CreateWithOutbox(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil)
+
 		m.productRepo.EXPECT().
-			DeductStock(gomock.Any(), productID, 2).
+			ReserveStockBatch(gomock.Any(), []port.StockLine{{ProductID: productID, Quantity: 2}}, gomock.Any()).
 			Return(errors.New("insufficient stock"))
 
 		_, err := svc.CreateOrder(context.Background(), "", validRequest)
@@ -469,8 +1844,8 @@ func TestOrderService_CreateOrder(t *testing.T) {
 			Return(true, nil)
 
 		m.productRepo.EXPECT().
-			GetByID(gomock.Any(), productID).
-			Return(product, nil)
+			GetByIDs(gomock.Any(), []domain.ID{productID}).
+			Return([]*domain.Product{product}, nil)
 
 		m.txManager.EXPECT().
 			WithTransaction(gomock.Any(), gomock.Any()).
@@ -478,12 +1853,9 @@ func TestOrderService_CreateOrder(t *testing.T) {
 				return fn(ctx)
 			})
 
-		m.productRepo.EXPECT().
-			DeductStock(gomock.Any(), productID, 2).
-			Return(nil)
-
+		m.customerRepo.EXPECT().Lock(gomock.Any(), customerID).Return(nil)
 		m.orderRepo.EXPECT().
-			Create(gomock.Any(), gomock.Any()).
+			CreateWithOutbox(gomock.Any(), gomock.Any(), gomock.Any()).
 			Return(errors.New("insert failed"))
 
 		_, err := svc.CreateOrder(context.Background(), "", validRequest)
@@ -515,11 +1887,8 @@ func TestOrderService_CreateOrder(t *testing.T) {
 			Return(true, nil)
 
 		m.productRepo.EXPECT().
-			GetByID(gomock.Any(), productID).
-			Return(product, nil)
-		m.productRepo.EXPECT().
-			GetByID(gomock.Any(), productID2).
-			Return(product2, nil)
+			GetByIDs(gomock.Any(), []domain.ID{productID, productID2}).
+			Return([]*domain.Product{product, product2}, nil)
 
 		m.txManager.EXPECT().
 			WithTransaction(gomock.Any(), gomock.Any()).
@@ -528,15 +1897,20 @@ func TestOrderService_CreateOrder(t *testing.T) {
 			})
 
 		m.productRepo.EXPECT().
-			DeductStock(gomock.Any(), productID, 2).
+			ReserveStockBatch(gomock.Any(), []port.StockLine{
+				{ProductID: productID, Quantity: 2},
+				{ProductID: productID2, Quantity: 3},
+			}, gomock.Any()).
 			Return(nil)
-		m.productRepo.EXPECT().
-			DeductStock(gomock.Any(), productID2, 3).
+
+		m.reservations.EXPECT().
+			Create(gomock.Any(), gomock.Any()).
 			Return(nil)
 
+		m.customerRepo.EXPECT().Lock(gomock.Any(), customerID).Return(nil)
 		m.orderRepo.EXPECT().
-			Create(gomock.Any(), gomock.Any()).
-			DoAndReturn(func(_ context.Context, order *domain.Order) error {
+			CreateWithOutbox(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, order *domain.Order, _ func(*domain.Order) domain.Event) error {
 				order.ID = domain.ID("aabbccddee112233aabbccdd")
 				return nil
 			})
@@ -586,19 +1960,24 @@ func TestOrderService_CreateOrder_Idempotency(t *testing.T) {
 			Exists(gomock.Any(), customerID).
 			Return(true, nil)
 		m.productRepo.EXPECT().
-			GetByID(gomock.Any(), productID).
-			Return(product, nil)
+			GetByIDs(gomock.Any(), []domain.ID{productID}).
+			Return([]*domain.Product{product}, nil)
 		m.txManager.EXPECT().
 			WithTransaction(gomock.Any(), gomock.Any()).
 			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
 				return fn(ctx)
 			})
 		m.productRepo.EXPECT().
-			DeductStock(gomock.Any(), productID, 1).
+			ReserveStockBatch(gomock.Any(), []port.StockLine{{ProductID: productID, Quantity: 1}}, gomock.Any()).
 			Return(nil)
-		m.orderRepo.EXPECT().
+		m.reservations.EXPECT().
 			Create(gomock.Any(), gomock.Any()).
-			DoAndReturn(func(_ context.Context, order *domain.Order) error {
+			Return(nil)
+
+		m.customerRepo.EXPECT().Lock(gomock.Any(), customerID).Return(nil)
+		m.orderRepo.EXPECT().
+			CreateWithOutbox(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, order *domain.Order, _ func(*domain.Order) domain.Event) error {
 				order.ID = domain.ID("aabbccddee112233aabbccdd")
 				return nil
 			})
@@ -688,3 +2067,81 @@ func TestOrderService_CreateOrder_Idempotency(t *testing.T) {
 		}
 	})
 }
+
+func TestOrderService_ExpireStaleOrders(t *testing.T) {
+	now := time.Now()
+	cutoff := now.Add(-time.Hour)
+	stale := &domain.Order{ID: "aabbccddee112233aabb0002", Status: domain.OrderStatusCreated, Version: 1, CreatedAt: now.Add(-2 * time.Hour)}
+	modified := &domain.Order{ID: "aabbccddee112233aabb0003", Status: domain.OrderStatusCreated, Version: 1, CreatedAt: now.Add(-3 * time.Hour)}
+
+	staleFilter := port.OrderFilter{Status: domain.OrderStatusCreated, CreatedBefore: cutoff}
+
+	t.Run("cancels stale orders oldest first and skips concurrently modified ones", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+
+		m.orderRepo.EXPECT().
+			List(gomock.Any(), staleFilter, port.PageRequest{Limit: 10, SortBy: port.OrderSortCreatedAt}).
+			Return(&port.Page[*domain.Order]{Items: []*domain.Order{modified, stale}, NextCursor: "next"}, nil)
+
+		m.orderRepo.EXPECT().GetByID(gomock.Any(), stale.ID).Return(stale, nil)
+		m.txManager.EXPECT().
+			WithTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
+		m.orderRepo.EXPECT().
+			UpdateStatusWithOutbox(gomock.Any(), stale.ID, int64(1), statusChangeTo(domain.OrderStatusCancelled), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ domain.ID, _ int64, change domain.OrderStatusChange, _ domain.Event) error {
+				if change.Actor != orderExpiryActor || change.Reason != orderExpiryReason {
+					t.Fatalf("expected expiry actor and reason, got %q / %q", change.Actor, change.Reason)
+				}
+				return nil
+			})
+		m.reservations.EXPECT().GetByOrderID(gomock.Any(), stale.ID).Return(nil, nil)
+		m.orderCache.EXPECT().Set(gomock.Any(), "order:"+string(stale.ID), gomock.Any(), orderCacheTTL).Return(nil)
+
+		// Processed by another request after the listing was read.
+		m.orderRepo.EXPECT().
+			GetByID(gomock.Any(), modified.ID).
+			Return(&domain.Order{ID: modified.ID, Status: domain.OrderStatusProcessing, Version: 2}, nil)
+
+		expired, err := svc.ExpireStaleOrders(context.Background(), cutoff, 10)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if expired != 1 {
+			t.Fatalf("expected 1 expired order, got %d", expired)
+		}
+	})
+
+	t.Run("asks for no more than the limit", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+
+		m.orderRepo.EXPECT().
+			List(gomock.Any(), staleFilter, port.PageRequest{Limit: 1, SortBy: port.OrderSortCreatedAt}).
+			Return(&port.Page[*domain.Order]{Items: []*domain.Order{modified}, NextCursor: "next"}, nil)
+		m.orderRepo.EXPECT().
+			GetByID(gomock.Any(), modified.ID).
+			Return(nil, serviceerrors.NewNotFoundError("order not found"))
+
+		expired, err := svc.ExpireStaleOrders(context.Background(), cutoff, 1)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if expired != 0 {
+			t.Fatalf("expected 0 expired orders, got %d", expired)
+		}
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		svc, m := setupOrderService(t)
+
+		m.orderRepo.EXPECT().
+			List(gomock.Any(), staleFilter, gomock.Any()).
+			Return(nil, errors.New("db error"))
+
+		if _, err := svc.ExpireStaleOrders(context.Background(), cutoff, 10); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
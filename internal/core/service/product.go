@@ -2,25 +2,61 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/rafaelleal24/challenge/internal/core/domain"
 	"github.com/rafaelleal24/challenge/internal/core/dto"
 	"github.com/rafaelleal24/challenge/internal/core/logger"
 	"github.com/rafaelleal24/challenge/internal/core/port"
+	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
+)
+
+const (
+	productListDefaultLimit = 20
+	productListMaxLimit     = 100
 )
 
 type ProductService struct {
 	productRepository port.ProductPort
+	categories        port.CategoryPort
+	stockMovements    port.StockMovementPort
+	txManager         port.TransactionManager
+	lowStockThreshold int
 }
 
-func NewProductService(productRepository port.ProductPort) *ProductService {
-	return &ProductService{productRepository: productRepository}
+func NewProductService(productRepository port.ProductPort, categories port.CategoryPort, stockMovements port.StockMovementPort, txManager port.TransactionManager, lowStockThreshold int) *ProductService {
+	return &ProductService{
+		productRepository: productRepository,
+		categories:        categories,
+		stockMovements:    stockMovements,
+		txManager:         txManager,
+		lowStockThreshold: lowStockThreshold,
+	}
 }
 
 func (s *ProductService) CreateProduct(ctx context.Context, request *dto.CreateProductRequest) (*domain.Product, error) {
 	product := domain.NewProduct(request.Name, request.Description, domain.NewAmountFromCents(request.Price), request.Stock)
+	product.SKU = request.SKU
+	product.LowStockThreshold = request.LowStockThreshold
+	product.Tags = domain.NormalizeTags(request.Tags)
+	product.CategoryID = request.CategoryID
+	if len(request.Variants) > 0 {
+		variants, err := newProductVariants(request.Variants)
+		if err != nil {
+			return nil, err
+		}
+		product.Variants = variants
+		product.Stock = 0
+		for _, variant := range variants {
+			product.Stock += variant.Stock
+		}
+	}
 
-	if err := s.productRepository.Create(ctx, product); err != nil {
+	err := s.inCategory(ctx, request.CategoryID, func(ctx context.Context) error {
+		return s.productRepository.Create(ctx, product)
+	})
+	if err != nil {
 		logger.Error(ctx, "product: create failed", err, map[string]any{
 			"name":        request.Name,
 			"description": request.Description,
@@ -34,14 +70,357 @@ func (s *ProductService) CreateProduct(ctx context.Context, request *dto.CreateP
 	return product, nil
 }
 
+// newProductVariants builds the variants of a new product. SKUs must be unique within the
+// product; the index on SKU only catches clashes with other products.
+func newProductVariants(requests []dto.CreateVariantRequest) ([]domain.ProductVariant, error) {
+	variants := make([]domain.ProductVariant, len(requests))
+	seen := make(map[string]bool, len(requests))
+	for i, request := range requests {
+		if seen[request.SKU] {
+			return nil, serviceerrors.NewInvalidRequestError(fmt.Sprintf("duplicate sku %s", request.SKU))
+		}
+		seen[request.SKU] = true
+
+		var price *domain.Amount
+		if request.Price != nil {
+			amount := domain.NewAmountFromCents(*request.Price)
+			price = &amount
+		}
+		variants[i] = domain.NewProductVariant(request.SKU, request.Name, price, request.Stock)
+	}
+	return variants, nil
+}
+
 func (s *ProductService) GetByID(ctx context.Context, id domain.ID) (*domain.Product, error) {
 	return s.productRepository.GetByID(ctx, id)
 }
 
-func (s *ProductService) GetAll(ctx context.Context) ([]*domain.Product, error) {
-	return s.productRepository.GetAll(ctx)
+func (s *ProductService) GetByIDs(ctx context.Context, ids []domain.ID) ([]*domain.Product, error) {
+	return s.productRepository.GetByIDs(ctx, ids)
+}
+
+func (s *ProductService) ListProducts(ctx context.Context, request *dto.ListProductsRequest) (*port.Page[*domain.Product], error) {
+	if request.MinPrice != nil && request.MaxPrice != nil && *request.MinPrice > *request.MaxPrice {
+		return nil, serviceerrors.NewInvalidRequestError("min_price must not be greater than max_price")
+	}
+
+	filter := port.ProductFilter{
+		NamePrefix:  request.NamePrefix,
+		InStockOnly: request.InStock,
+		Tag:         strings.ToLower(strings.TrimSpace(request.Tag)),
+	}
+	if request.Category != "" {
+		categoryIDs, err := s.categorySubtree(ctx, domain.ID(request.Category))
+		if err != nil {
+			return nil, err
+		}
+		filter.CategoryIDs = categoryIDs
+	}
+	if request.MinPrice != nil {
+		minPrice := domain.NewAmountFromCents(*request.MinPrice)
+		filter.MinPrice = &minPrice
+	}
+	if request.MaxPrice != nil {
+		maxPrice := domain.NewAmountFromCents(*request.MaxPrice)
+		filter.MaxPrice = &maxPrice
+	}
+
+	page := port.PageRequest{
+		Limit:        request.Limit,
+		Cursor:       request.Cursor,
+		SortBy:       request.Sort,
+		Descending:   request.Order == "desc",
+		IncludeTotal: request.IncludeTotal,
+	}
+	if page.Limit <= 0 {
+		page.Limit = productListDefaultLimit
+	}
+	if page.Limit > productListMaxLimit {
+		page.Limit = productListMaxLimit
+	}
+	if page.SortBy == "" {
+		page.SortBy = port.ProductSortName
+	}
+	if page.SortBy != port.ProductSortName && page.SortBy != port.ProductSortPrice && page.SortBy != port.ProductSortCreatedAt {
+		return nil, serviceerrors.NewInvalidRequestError("invalid sort field")
+	}
+
+	return s.productRepository.List(ctx, filter, page)
+}
+
+// ListLegacyProducts returns the first page of products matching the request filters, at the
+// largest page size. It backs the unpaginated listing older clients rely on, which used to
+// return every product; the page's cursor lets the caller point them at the rest.
+func (s *ProductService) ListLegacyProducts(ctx context.Context, request *dto.ListProductsRequest) (*port.Page[*domain.Product], error) {
+	pageRequest := *request
+	pageRequest.Limit = productListMaxLimit
+	pageRequest.Cursor = ""
+	pageRequest.IncludeTotal = false
+	return s.ListProducts(ctx, &pageRequest)
+}
+
+func (s *ProductService) SearchProducts(ctx context.Context, request *dto.SearchProductsRequest) (*port.Page[*domain.Product], error) {
+	query := strings.TrimSpace(request.Query)
+	if query == "" {
+		return nil, serviceerrors.NewInvalidRequestError("search query must not be empty")
+	}
+
+	page := port.PageRequest{
+		Limit:        request.Limit,
+		Cursor:       request.Cursor,
+		IncludeTotal: request.IncludeTotal,
+	}
+	if page.Limit <= 0 {
+		page.Limit = productListDefaultLimit
+	}
+	if page.Limit > productListMaxLimit {
+		page.Limit = productListMaxLimit
+	}
+
+	return s.productRepository.Search(ctx, query, page)
+}
+
+func (s *ProductService) UpdateProduct(ctx context.Context, id domain.ID, request *dto.UpdateProductRequest) (*domain.Product, error) {
+	if request.Name == nil && request.Description == nil && request.Price == nil && request.LowStockThreshold == nil &&
+		request.CategoryID == nil && request.Tags == nil {
+		return nil, serviceerrors.NewInvalidRequestError("at least one field must be provided")
+	}
+	update := port.ProductUpdate{
+		Name:              request.Name,
+		Description:       request.Description,
+		LowStockThreshold: request.LowStockThreshold,
+		CategoryID:        request.CategoryID,
+	}
+	if request.Price != nil {
+		price := domain.NewAmountFromCents(*request.Price)
+		update.Price = &price
+	}
+	if request.Tags != nil {
+		tags := domain.NormalizeTags(*request.Tags)
+		update.Tags = &tags
+	}
+
+	var categoryID domain.ID
+	if request.CategoryID != nil {
+		categoryID = *request.CategoryID
+	}
+	var product *domain.Product
+	err := s.inCategory(ctx, categoryID, func(ctx context.Context) error {
+		var err error
+		product, err = s.productRepository.Update(ctx, id, update)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info(ctx, "Product updated", map[string]any{"product_id": id})
+	return product, nil
+}
+
+// inCategory runs place, which files a product under categoryID, in a transaction that locks
+// the category first, so it cannot be deleted while the product is being placed in it.
+// Without a category, place runs on its own.
+func (s *ProductService) inCategory(ctx context.Context, categoryID domain.ID, place func(ctx context.Context) error) error {
+	if categoryID == "" {
+		return place(ctx)
+	}
+	if !domain.ValidateID(string(categoryID)) {
+		return serviceerrors.NewInvalidRequestError("invalid category ID")
+	}
+	return s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := s.categories.Lock(txCtx, categoryID); err != nil {
+			if serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+				return serviceerrors.NewNotFoundError(fmt.Sprintf("category %s not found", categoryID))
+			}
+			return err
+		}
+		return place(txCtx)
+	})
+}
+
+// categorySubtree returns the category and all of its subcategories, so listing a category
+// also lists the products filed deeper in the tree.
+func (s *ProductService) categorySubtree(ctx context.Context, id domain.ID) ([]domain.ID, error) {
+	if !domain.ValidateID(string(id)) {
+		return nil, serviceerrors.NewInvalidRequestError("invalid category ID")
+	}
+	categories, err := s.categories.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tree := domain.NewCategoryTree(categories)
+	if tree.Get(id) == nil {
+		return nil, serviceerrors.NewNotFoundError(fmt.Sprintf("category %s not found", id))
+	}
+	return tree.Subtree(id), nil
+}
+
+// ArchiveProduct withdraws a product from sale; existing orders keep referencing it.
+func (s *ProductService) ArchiveProduct(ctx context.Context, id domain.ID) error {
+	if err := s.productRepository.Archive(ctx, id); err != nil {
+		return err
+	}
+
+	logger.Info(ctx, "Product archived", map[string]any{"product_id": id})
+	return nil
+}
+
+// DeductStockBatch removes the stock sold to an order for all lines at once, reporting every
+// product short of stock together. Like the other order-driven stock changes it is meant to
+// run inside the caller's transaction, which then also covers the movements.
+func (s *ProductService) DeductStockBatch(ctx context.Context, lines []port.StockLine, orderID domain.ID) error {
+	if err := s.productRepository.DeductStockBatch(ctx, lines, s.batchStockAlert(lines)); err != nil {
+		return err
+	}
+	for _, line := range lines {
+		if err := s.recordOrderMovement(ctx, line.ProductID, line.SKU, -line.Quantity, domain.StockMovementReasonOrderDeduction, orderID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RestoreStock puts back stock deducted for an order that was cancelled.
+func (s *ProductService) RestoreStock(ctx context.Context, id domain.ID, sku string, quantity int, orderID domain.ID) error {
+	if err := s.productRepository.RestoreStock(ctx, id, sku, quantity); err != nil {
+		return err
+	}
+	return s.recordOrderMovement(ctx, id, sku, quantity, domain.StockMovementReasonCancellation, orderID)
+}
+
+// ReserveStockBatch holds stock for all lines at once, reporting every product short of
+// stock together.
+func (s *ProductService) ReserveStockBatch(ctx context.Context, lines []port.StockLine) error {
+	return s.productRepository.ReserveStockBatch(ctx, lines, s.batchStockAlert(lines))
+}
+
+func (s *ProductService) ReleaseReservedStock(ctx context.Context, id domain.ID, sku string, quantity int) error {
+	return s.productRepository.ReleaseReservedStock(ctx, id, sku, quantity)
+}
+
+// CommitReservedStock turns units reserved for an order into a stock deduction.
+func (s *ProductService) CommitReservedStock(ctx context.Context, id domain.ID, sku string, quantity int, orderID domain.ID) error {
+	if err := s.productRepository.CommitReservedStock(ctx, id, sku, quantity); err != nil {
+		return err
+	}
+	return s.recordOrderMovement(ctx, id, sku, -quantity, domain.StockMovementReasonOrderDeduction, orderID)
+}
+
+// stockAlert raises low-stock and out-of-stock events for a change that took quantity units
+// out of the available stock of a product or variant, by deducting or reserving them.
+func (s *ProductService) stockAlert(quantity int) port.StockAlertBuilder {
+	return func(product *domain.Product, sku string) domain.Event {
+		return domain.NewStockAlertEvent(product, sku, product.AvailableStockOf(sku)+quantity, s.lowStockThreshold)
+	}
+}
+
+// batchStockAlert is stockAlert for a batch, where each product or variant lost the sum of
+// its lines.
+func (s *ProductService) batchStockAlert(lines []port.StockLine) port.StockAlertBuilder {
+	type stockKey struct {
+		productID domain.ID
+		sku       string
+	}
+	quantities := make(map[stockKey]int, len(lines))
+	for _, line := range lines {
+		quantities[stockKey{line.ProductID, line.SKU}] += line.Quantity
+	}
+	return func(product *domain.Product, sku string) domain.Event {
+		return s.stockAlert(quantities[stockKey{product.ID, sku}])(product, sku)
+	}
+}
+
+func (s *ProductService) recordOrderMovement(ctx context.Context, id domain.ID, sku string, quantity int, reason domain.StockMovementReason, orderID domain.ID) error {
+	movement := domain.NewStockMovement(id, sku, quantity, reason)
+	movement.OrderID = orderID
+	return s.stockMovements.Create(ctx, movement)
 }
 
-func (s *ProductService) DeductStock(ctx context.Context, id domain.ID, quantity int) error {
-	return s.productRepository.DeductStock(ctx, id, quantity)
+// checkVariantSKU checks that sku names one of the product's variants, or is empty when the
+// product has none, so stock is always changed at the level it is kept.
+func checkVariantSKU(product *domain.Product, sku string) error {
+	switch {
+	case !product.HasVariants() && sku != "":
+		return serviceerrors.NewInvalidRequestError(fmt.Sprintf("product %s has no variants", product.ID))
+	case product.HasVariants() && sku == "":
+		return serviceerrors.NewInvalidRequestError(fmt.Sprintf("product %s is sold by variant, a sku is required", product.ID))
+	case product.HasVariants() && product.Variant(sku) == nil:
+		return serviceerrors.NewNotFoundError(fmt.Sprintf("product %s has no variant %s", product.ID, sku))
+	}
+	return nil
+}
+
+// AdjustStock applies a manual stock change and records it in one transaction. Removing
+// stock never dips into units held by reservations.
+func (s *ProductService) AdjustStock(ctx context.Context, id domain.ID, request *dto.StockAdjustmentRequest, actor string) (*domain.StockMovement, error) {
+	reason := domain.StockMovementReason(request.Reason)
+	if !reason.IsManual() {
+		return nil, serviceerrors.NewInvalidRequestError("invalid stock adjustment reason")
+	}
+	if request.Quantity == 0 {
+		return nil, serviceerrors.NewInvalidRequestError("quantity must not be zero")
+	}
+	if request.Quantity < 0 && reason != domain.StockMovementReasonCorrection {
+		return nil, serviceerrors.NewInvalidRequestError("only corrections may remove stock")
+	}
+
+	product, err := s.productRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVariantSKU(product, request.SKU); err != nil {
+		return nil, err
+	}
+
+	movement := domain.NewStockMovement(id, request.SKU, request.Quantity, reason)
+	movement.Actor = actor
+	movement.Note = request.Note
+
+	err = s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		var err error
+		if request.Quantity > 0 {
+			err = s.productRepository.RestoreStock(txCtx, id, request.SKU, request.Quantity)
+		} else {
+			err = s.productRepository.DeductStock(txCtx, id, request.SKU, -request.Quantity, s.stockAlert(-request.Quantity))
+		}
+		if err != nil {
+			return err
+		}
+		return s.stockMovements.Create(txCtx, movement)
+	})
+	if err != nil {
+		logger.Error(ctx, "transaction: adjust stock failed", err, map[string]any{
+			"product_id": id,
+			"sku":        request.SKU,
+			"quantity":   request.Quantity,
+			"reason":     request.Reason,
+		})
+		return nil, err
+	}
+
+	logger.Info(ctx, "Stock adjusted", map[string]any{
+		"product_id": id,
+		"sku":        request.SKU,
+		"quantity":   request.Quantity,
+		"reason":     request.Reason,
+		"actor":      actor,
+	})
+	return movement, nil
+}
+
+func (s *ProductService) GetStockMovements(ctx context.Context, id domain.ID, request *dto.ListStockMovementsRequest) (*port.Page[*domain.StockMovement], error) {
+	if _, err := s.productRepository.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	limit := request.Limit
+	if limit <= 0 {
+		limit = productListDefaultLimit
+	}
+	if limit > productListMaxLimit {
+		limit = productListMaxLimit
+	}
+
+	return s.stockMovements.GetByProductID(ctx, id, limit, request.Cursor)
 }
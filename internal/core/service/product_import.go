@@ -0,0 +1,563 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/logger"
+	"github.com/rafaelleal24/challenge/internal/core/port"
+	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
+)
+
+const (
+	// ProductImportMaxRows bounds an upload so its rows fit in a single import document.
+	ProductImportMaxRows = 10000
+	// productImportProgressEvery is how many rows are applied between progress saves, which
+	// also bounds the rows an interrupted import applies twice.
+	productImportProgressEvery = 100
+	productExportFlushEvery    = 100
+	productImportMaxLineBytes  = 1 << 20
+	productImportMaxTags       = 20
+	productImportMaxTagLength  = 50
+	productImportMaxSKULength  = 64
+	productImportTagSeparator  = "|"
+)
+
+// productImportColumns are the CSV columns, in the order exports write them.
+var productImportColumns = []string{"sku", "name", "description", "price", "stock", "low_stock_threshold", "category_id", "tags"}
+
+// productImportRecord is a row of an import or export. NDJSON lines use it directly; CSV
+// columns map onto its fields, with tags joined by productImportTagSeparator.
+type productImportRecord struct {
+	SKU               string   `json:"sku"`
+	Name              string   `json:"name"`
+	Description       string   `json:"description"`
+	Price             *int     `json:"price"`
+	Stock             *int     `json:"stock"`
+	LowStockThreshold *int     `json:"low_stock_threshold,omitempty"`
+	CategoryID        string   `json:"category_id,omitempty"`
+	Tags              []string `json:"tags,omitempty"`
+}
+
+type ProductImportService struct {
+	productRepository port.ProductPort
+	categories        port.CategoryPort
+	stockMovements    port.StockMovementPort
+	imports           port.ProductImportPort
+	txManager         port.TransactionManager
+	staleAfter        time.Duration
+}
+
+func NewProductImportService(productRepository port.ProductPort, categories port.CategoryPort, stockMovements port.StockMovementPort, imports port.ProductImportPort, txManager port.TransactionManager, staleAfter time.Duration) *ProductImportService {
+	return &ProductImportService{
+		productRepository: productRepository,
+		categories:        categories,
+		stockMovements:    stockMovements,
+		imports:           imports,
+		txManager:         txManager,
+		staleAfter:        staleAfter,
+	}
+}
+
+// StartImport validates an upload and queues its valid rows to be applied in the background.
+// Malformed rows are reported on the import; only an unreadable upload fails the request.
+func (s *ProductImportService) StartImport(ctx context.Context, format domain.ProductImportFormat, body io.Reader) (*domain.ProductImport, error) {
+	var records []productImportLine
+	var err error
+	switch format {
+	case domain.ProductImportFormatCSV:
+		records, err = readProductImportCSV(body)
+	case domain.ProductImportFormatNDJSON:
+		records, err = readProductImportNDJSON(body)
+	default:
+		return nil, serviceerrors.NewInvalidRequestError("import format must be csv or ndjson")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, serviceerrors.NewInvalidRequestError("import has no rows")
+	}
+
+	rows, rowErrors := validateProductImport(records)
+	productImport := domain.NewProductImport(format, rows, len(records), rowErrors)
+	if err := s.imports.Create(ctx, productImport); err != nil {
+		logger.Error(ctx, "product import: create failed", err, map[string]any{
+			"format": format,
+			"rows":   len(records),
+		})
+		return nil, err
+	}
+
+	logger.Info(ctx, "Product import queued", map[string]any{
+		"import_id": productImport.ID,
+		"rows":      productImport.Total,
+		"invalid":   productImport.Failed,
+	})
+	return productImport, nil
+}
+
+func (s *ProductImportService) GetImport(ctx context.Context, id domain.ID) (*domain.ProductImport, error) {
+	productImport, err := s.imports.GetByID(ctx, id)
+	if err != nil {
+		if serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			return nil, serviceerrors.NewNotFoundError(fmt.Sprintf("product import %s not found", id))
+		}
+		return nil, err
+	}
+	return productImport, nil
+}
+
+// ProcessNext claims the next queued import and applies its rows, reporting whether there
+// was one. Rows the catalogue rejects are recorded on the import; other errors stop it
+// where it is, to be picked up again once it goes stale.
+func (s *ProductImportService) ProcessNext(ctx context.Context) (bool, error) {
+	productImport, err := s.imports.ClaimNext(ctx, time.Now().Add(-s.staleAfter))
+	if err != nil || productImport == nil {
+		return false, err
+	}
+
+	stopped, err := s.applyImport(ctx, productImport)
+	if err != nil || stopped {
+		return true, err
+	}
+
+	logger.Info(ctx, "Product import completed", map[string]any{
+		"import_id": productImport.ID,
+		"created":   productImport.Created,
+		"updated":   productImport.Updated,
+		"failed":    productImport.Failed,
+	})
+	return true, nil
+}
+
+// applyImport applies the pending rows of a claimed import and completes it. It stops early,
+// without an error, when saving progress shows another worker has claimed the import since:
+// this one was slow enough to be presumed gone, and the import is left to the other.
+func (s *ProductImportService) applyImport(ctx context.Context, productImport *domain.ProductImport) (stopped bool, err error) {
+	saveProgress := func() (bool, error) {
+		err := s.imports.SaveProgress(ctx, productImport)
+		if serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			logger.Warn(ctx, "Product import claimed by another worker", map[string]any{"import_id": productImport.ID})
+			return true, nil
+		}
+		return false, err
+	}
+
+	for _, row := range productImport.Pending() {
+		if err := s.applyImportRow(ctx, productImport, row); err != nil {
+			return false, err
+		}
+		productImport.Processed++
+		if productImport.Processed%productImportProgressEvery == 0 {
+			if stopped, err := saveProgress(); err != nil || stopped {
+				return stopped, err
+			}
+		}
+	}
+
+	finishedAt := time.Now()
+	productImport.Status = domain.ProductImportStatusCompleted
+	productImport.FinishedAt = &finishedAt
+	return saveProgress()
+}
+
+// applyImportRow upserts the product of a row in a transaction. A row filed under a category
+// locks it, as ProductService does, so the category cannot be deleted under it, and the stock
+// of a product the row creates is recorded as an import movement.
+func (s *ProductImportService) applyImportRow(ctx context.Context, productImport *domain.ProductImport, row domain.ProductImportRow) error {
+	var created bool
+	err := s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		if row.CategoryID != "" {
+			if err := s.categories.Lock(txCtx, row.CategoryID); err != nil {
+				if serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+					return serviceerrors.NewNotFoundError(fmt.Sprintf("category %s not found", row.CategoryID))
+				}
+				return err
+			}
+		}
+		product := row.NewProduct()
+		var err error
+		created, err = s.productRepository.UpsertBySKU(txCtx, product)
+		if err != nil || !created || product.Stock == 0 {
+			return err
+		}
+		// A created product starts with the imported stock, which goes into the ledger like
+		// any other change.
+		movement := domain.NewStockMovement(product.ID, "", product.Stock, domain.StockMovementReasonImport)
+		movement.Note = fmt.Sprintf("product import %s, line %d", productImport.ID, row.Line)
+		return s.stockMovements.Create(txCtx, movement)
+	})
+	if err != nil {
+		var svcErr *serviceerrors.ServiceError
+		if errors.As(err, &svcErr) {
+			productImport.Fail(domain.ProductImportError{Line: row.Line, SKU: row.SKU, Message: svcErr.Message})
+			return nil
+		}
+		return err
+	}
+
+	if created {
+		productImport.Created++
+	} else {
+		productImport.Updated++
+	}
+	return nil
+}
+
+// ExportProducts writes every product that is not archived in the import format, so an
+// export can be edited and imported back. Products sold by variant are written as a row per
+// variant, which the import matches by variant SKU. Products with neither a SKU nor variants
+// could not be matched back and would be imported as new products, so they are left out.
+func (s *ProductImportService) ExportProducts(ctx context.Context, format domain.ProductImportFormat, w io.Writer) error {
+	var write func(record productImportRecord) error
+	var flush func() error
+	switch format {
+	case domain.ProductImportFormatCSV:
+		csvWriter := csv.NewWriter(w)
+		if err := csvWriter.Write(productImportColumns); err != nil {
+			return err
+		}
+		write = func(record productImportRecord) error { return csvWriter.Write(record.csvFields()) }
+		flush = func() error {
+			csvWriter.Flush()
+			return csvWriter.Error()
+		}
+	case domain.ProductImportFormatNDJSON:
+		encoder := json.NewEncoder(w)
+		write = func(record productImportRecord) error { return encoder.Encode(record) }
+		flush = func() error { return nil }
+	default:
+		return serviceerrors.NewInvalidRequestError("export format must be csv or ndjson")
+	}
+
+	count, skipped := 0, 0
+	err := s.productRepository.Stream(ctx, func(product *domain.Product) error {
+		records := newProductImportRecords(product)
+		if len(records) == 0 {
+			skipped++
+			return nil
+		}
+		for _, record := range records {
+			if err := write(record); err != nil {
+				return err
+			}
+		}
+		count++
+		if count%productExportFlushEvery == 0 {
+			return flushExport(w, flush)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if skipped > 0 {
+		logger.Warn(ctx, "product export left out products without a SKU", map[string]any{"skipped": skipped})
+	}
+	return flushExport(w, flush)
+}
+
+// flushExport pushes buffered rows through to the client when the writer supports it, so a
+// large export streams instead of arriving at once.
+func flushExport(w io.Writer, flush func() error) error {
+	if err := flush(); err != nil {
+		return err
+	}
+	if flusher, ok := w.(interface{ Flush() }); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// newProductImportRecords returns the rows a product is exported as: one per variant, or a
+// single row for a product with its own SKU. A variant row only has a price when the variant
+// overrides its product's.
+func newProductImportRecords(product *domain.Product) []productImportRecord {
+	record := func(sku, name string, price *domain.Amount, stock int) productImportRecord {
+		var cents *int
+		if price != nil {
+			value := int(*price)
+			cents = &value
+		}
+		return productImportRecord{
+			SKU:               sku,
+			Name:              name,
+			Description:       product.Description,
+			Price:             cents,
+			Stock:             &stock,
+			LowStockThreshold: product.LowStockThreshold,
+			CategoryID:        string(product.CategoryID),
+			Tags:              product.Tags,
+		}
+	}
+
+	if len(product.Variants) > 0 {
+		records := make([]productImportRecord, len(product.Variants))
+		for i, variant := range product.Variants {
+			records[i] = record(variant.SKU, variant.Name, variant.Price, variant.Stock)
+		}
+		return records
+	}
+	if product.SKU == "" {
+		return nil
+	}
+	return []productImportRecord{record(product.SKU, product.Name, &product.Price, product.Stock)}
+}
+
+func (r productImportRecord) csvFields() []string {
+	optionalInt := func(value *int) string {
+		if value == nil {
+			return ""
+		}
+		return strconv.Itoa(*value)
+	}
+	return []string{
+		r.SKU,
+		r.Name,
+		r.Description,
+		optionalInt(r.Price),
+		optionalInt(r.Stock),
+		optionalInt(r.LowStockThreshold),
+		r.CategoryID,
+		strings.Join(r.Tags, productImportTagSeparator),
+	}
+}
+
+// productImportLine is a record read from an upload, or the reason it could not be read.
+type productImportLine struct {
+	line   int
+	record productImportRecord
+	err    string
+}
+
+func readProductImportCSV(body io.Reader) ([]productImportLine, error) {
+	reader := csv.NewReader(body)
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, productImportReadError(err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !isProductImportColumn(name) {
+			return nil, serviceerrors.NewInvalidRequestError(fmt.Sprintf("unknown column %q", name))
+		}
+		if _, ok := columns[name]; ok {
+			return nil, serviceerrors.NewInvalidRequestError(fmt.Sprintf("duplicate column %q", name))
+		}
+		columns[name] = i
+	}
+	for _, required := range []string{"sku", "name", "price"} {
+		if _, ok := columns[required]; !ok {
+			return nil, serviceerrors.NewInvalidRequestError(fmt.Sprintf("missing column %q", required))
+		}
+	}
+
+	var lines []productImportLine
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			return lines, nil
+		}
+		if len(lines) == ProductImportMaxRows {
+			return nil, serviceerrors.NewInvalidRequestError(fmt.Sprintf("import exceeds %d rows", ProductImportMaxRows))
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) && errors.Is(parseErr.Err, csv.ErrFieldCount) {
+			short := productImportLine{line: parseErr.StartLine, err: fmt.Sprintf("expected %d fields, got %d", len(header), len(fields))}
+			if i := columns["sku"]; i < len(fields) {
+				short.record.SKU = fields[i]
+			}
+			lines = append(lines, short)
+			continue
+		}
+		if err != nil {
+			return nil, productImportReadError(err)
+		}
+		line, _ := reader.FieldPos(0)
+		record, recordErr := parseProductImportCSVRecord(columns, fields)
+		lines = append(lines, productImportLine{line: line, record: record, err: recordErr})
+	}
+}
+
+func isProductImportColumn(name string) bool {
+	for _, column := range productImportColumns {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
+
+func parseProductImportCSVRecord(columns map[string]int, fields []string) (productImportRecord, string) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(fields[i])
+		}
+		return ""
+	}
+	var record productImportRecord
+	record.SKU = field("sku")
+	record.Name = field("name")
+	record.Description = field("description")
+	record.CategoryID = field("category_id")
+	if tags := field("tags"); tags != "" {
+		record.Tags = strings.Split(tags, productImportTagSeparator)
+	}
+
+	for _, number := range []struct {
+		name   string
+		target **int
+	}{
+		{"price", &record.Price},
+		{"stock", &record.Stock},
+		{"low_stock_threshold", &record.LowStockThreshold},
+	} {
+		value := field(number.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return record, fmt.Sprintf("%s must be a whole number", number.name)
+		}
+		*number.target = &parsed
+	}
+	return record, ""
+}
+
+func readProductImportNDJSON(body io.Reader) ([]productImportLine, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), productImportMaxLineBytes)
+
+	var lines []productImportLine
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		if len(lines) == ProductImportMaxRows {
+			return nil, serviceerrors.NewInvalidRequestError(fmt.Sprintf("import exceeds %d rows", ProductImportMaxRows))
+		}
+
+		var record productImportRecord
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&record); err != nil {
+			lines = append(lines, productImportLine{line: line, err: fmt.Sprintf("invalid JSON: %v", err)})
+			continue
+		}
+		lines = append(lines, productImportLine{line: line, record: record})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, productImportReadError(err)
+	}
+	return lines, nil
+}
+
+// productImportReadError reports an upload that cannot be read any further. Malformed CSV
+// and overlong lines are the client's to fix; anything else, such as the connection
+// dropping, is returned as is.
+func productImportReadError(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return serviceerrors.NewInvalidRequestError(fmt.Sprintf("invalid CSV on line %d: %v", parseErr.StartLine, parseErr.Err))
+	}
+	if errors.Is(err, bufio.ErrTooLong) {
+		return serviceerrors.NewInvalidRequestError(fmt.Sprintf("a line is longer than %d bytes", productImportMaxLineBytes))
+	}
+	return err
+}
+
+// validateProductImport turns the records read from an upload into rows, reporting the
+// records that cannot be imported. A SKU may appear only once per upload.
+func validateProductImport(lines []productImportLine) ([]domain.ProductImportRow, []domain.ProductImportError) {
+	var rows []domain.ProductImportRow
+	var rowErrors []domain.ProductImportError
+	firstLine := make(map[string]int, len(lines))
+
+	for _, line := range lines {
+		record := line.record
+		record.SKU = strings.TrimSpace(record.SKU)
+
+		message := line.err
+		if message == "" {
+			message = validateProductImportRecord(record)
+		}
+		if message == "" {
+			if first, ok := firstLine[record.SKU]; ok {
+				message = fmt.Sprintf("duplicate sku, first seen on line %d", first)
+			}
+		}
+		if message != "" {
+			rowErrors = append(rowErrors, domain.ProductImportError{Line: line.line, SKU: record.SKU, Message: message})
+			continue
+		}
+
+		firstLine[record.SKU] = line.line
+		rows = append(rows, newProductImportRow(line.line, record))
+	}
+	return rows, rowErrors
+}
+
+func validateProductImportRecord(record productImportRecord) string {
+	switch {
+	case record.SKU == "":
+		return "sku is required"
+	case len(record.SKU) > productImportMaxSKULength:
+		return fmt.Sprintf("sku must be at most %d characters", productImportMaxSKULength)
+	case strings.TrimSpace(record.Name) == "":
+		return "name is required"
+	case record.Price != nil && *record.Price <= 0:
+		return "price must be greater than 0"
+	case record.Stock != nil && *record.Stock < 0:
+		return "stock must not be negative"
+	case record.LowStockThreshold != nil && *record.LowStockThreshold < 0:
+		return "low_stock_threshold must not be negative"
+	case record.CategoryID != "" && !domain.ValidateID(record.CategoryID):
+		return "invalid category_id"
+	case len(record.Tags) > productImportMaxTags:
+		return fmt.Sprintf("at most %d tags are allowed", productImportMaxTags)
+	}
+	for _, tag := range record.Tags {
+		if len(tag) > productImportMaxTagLength {
+			return fmt.Sprintf("tags must be at most %d characters", productImportMaxTagLength)
+		}
+	}
+	return ""
+}
+
+func newProductImportRow(line int, record productImportRecord) domain.ProductImportRow {
+	row := domain.ProductImportRow{
+		Line:              line,
+		SKU:               record.SKU,
+		Name:              strings.TrimSpace(record.Name),
+		Description:       record.Description,
+		LowStockThreshold: record.LowStockThreshold,
+		CategoryID:        domain.ID(record.CategoryID),
+		Tags:              domain.NormalizeTags(record.Tags),
+	}
+	if record.Price != nil {
+		price := domain.NewAmountFromCents(*record.Price)
+		row.Price = &price
+	}
+	if record.Stock != nil {
+		row.Stock = *record.Stock
+	}
+	return row
+}
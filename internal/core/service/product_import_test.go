@@ -0,0 +1,414 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/port/mock"
+	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
+	"go.uber.org/mock/gomock"
+)
+
+type productImportMocks struct {
+	productRepo    *mock.MockProductPort
+	categories     *mock.MockCategoryPort
+	stockMovements *mock.MockStockMovementPort
+	imports        *mock.MockProductImportPort
+}
+
+func setupProductImportService(t *testing.T) (*ProductImportService, *productImportMocks) {
+	ctrl := gomock.NewController(t)
+	productRepo := mock.NewMockProductPort(ctrl)
+	categories := mock.NewMockCategoryPort(ctrl)
+	stockMovements := mock.NewMockStockMovementPort(ctrl)
+	imports := mock.NewMockProductImportPort(ctrl)
+	txManager := mock.NewMockTransactionManager(ctrl)
+	txManager.EXPECT().
+		WithTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		}).
+		AnyTimes()
+	svc := NewProductImportService(productRepo, categories, stockMovements, imports, txManager, 5*time.Minute)
+	return svc, &productImportMocks{
+		productRepo:    productRepo,
+		categories:     categories,
+		stockMovements: stockMovements,
+		imports:        imports,
+	}
+}
+
+// expectImportCreated captures the import StartImport stores.
+func expectImportCreated(m *productImportMocks) *domain.ProductImport {
+	var created domain.ProductImport
+	m.imports.EXPECT().
+		Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, productImport *domain.ProductImport) error {
+			productImport.ID = "aabbccddee112233aabbcc99"
+			created = *productImport
+			return nil
+		})
+	return &created
+}
+
+func TestProductImportService_StartImport_CSV(t *testing.T) {
+	t.Run("queues valid rows and reports the others", func(t *testing.T) {
+		svc, m := setupProductImportService(t)
+		created := expectImportCreated(m)
+		body := strings.Join([]string{
+			"\ufeffSKU,name,price,stock,tags",
+			"TEE-1,Tee,1500,10,Summer|sale",
+			"TEE-2,,1500,10,",
+			"TEE-3,Polo,cheap,10,",
+			"TEE-1,Tee again,1500,10,",
+			"TEE-4,Cap,900",
+			"MUG-1,Mug,800,,",
+		}, "\n")
+
+		productImport, err := svc.StartImport(context.Background(), domain.ProductImportFormatCSV, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if productImport.Status != domain.ProductImportStatusPending || productImport.Total != 6 || productImport.Failed != 4 {
+			t.Fatalf("unexpected import %+v", productImport)
+		}
+		if len(created.Rows) != 2 || created.Rows[0].SKU != "TEE-1" || created.Rows[1].SKU != "MUG-1" {
+			t.Fatalf("unexpected rows %+v", created.Rows)
+		}
+		if tags := created.Rows[0].Tags; len(tags) != 2 || tags[0] != "sale" || tags[1] != "summer" {
+			t.Fatalf("expected normalized tags, got %v", tags)
+		}
+		if *created.Rows[0].Price != domain.NewAmountFromCents(1500) || created.Rows[0].Line != 2 {
+			t.Fatalf("unexpected row %+v", created.Rows[0])
+		}
+
+		want := []domain.ProductImportError{
+			{Line: 3, SKU: "TEE-2", Message: "name is required"},
+			{Line: 4, SKU: "TEE-3", Message: "price must be a whole number"},
+			{Line: 5, SKU: "TEE-1", Message: "duplicate sku, first seen on line 2"},
+			{Line: 6, SKU: "TEE-4", Message: "expected 5 fields, got 3"},
+		}
+		if len(productImport.Errors) != len(want) {
+			t.Fatalf("expected %d errors, got %+v", len(want), productImport.Errors)
+		}
+		for i, rowError := range productImport.Errors {
+			if rowError != want[i] {
+				t.Fatalf("expected error %+v, got %+v", want[i], rowError)
+			}
+		}
+	})
+
+	t.Run("rejects unknown columns", func(t *testing.T) {
+		svc, _ := setupProductImportService(t)
+
+		_, err := svc.StartImport(context.Background(), domain.ProductImportFormatCSV, strings.NewReader("sku,name,price,colour\n"))
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+
+	t.Run("requires the sku, name and price columns", func(t *testing.T) {
+		svc, _ := setupProductImportService(t)
+
+		_, err := svc.StartImport(context.Background(), domain.ProductImportFormatCSV, strings.NewReader("sku,name\nA,B\n"))
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+
+	t.Run("rejects an upload without rows", func(t *testing.T) {
+		svc, _ := setupProductImportService(t)
+
+		_, err := svc.StartImport(context.Background(), domain.ProductImportFormatCSV, strings.NewReader("sku,name,price\n"))
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+
+	t.Run("rejects malformed csv", func(t *testing.T) {
+		svc, _ := setupProductImportService(t)
+
+		_, err := svc.StartImport(context.Background(), domain.ProductImportFormatCSV, strings.NewReader("sku,name,price\nA,\"B,1\n"))
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+
+	t.Run("rejects a malformed first field", func(t *testing.T) {
+		svc, _ := setupProductImportService(t)
+
+		_, err := svc.StartImport(context.Background(), domain.ProductImportFormatCSV, strings.NewReader("sku,name,price\nA,B,1\na\"b,n,1\n"))
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "line 3") {
+			t.Fatalf("expected the error to name line 3, got %v", err)
+		}
+	})
+
+	t.Run("rejects too many rows", func(t *testing.T) {
+		svc, _ := setupProductImportService(t)
+		body := "sku,name,price\n" + strings.Repeat("A,B,1\n", ProductImportMaxRows+1)
+
+		_, err := svc.StartImport(context.Background(), domain.ProductImportFormatCSV, strings.NewReader(body))
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+}
+
+func TestProductImportService_StartImport_NDJSON(t *testing.T) {
+	svc, m := setupProductImportService(t)
+	created := expectImportCreated(m)
+	body := strings.Join([]string{
+		`{"sku":"TEE-1","name":"Tee","price":1500,"stock":4,"category_id":"aabbccddee112233aabbcc01"}`,
+		``,
+		`{"sku":"TEE-2","name":"Polo","price":0}`,
+		`{"sku":"TEE-3","name":"Cap","price":900,"colour":"red"}`,
+		`not json`,
+	}, "\n")
+
+	productImport, err := svc.StartImport(context.Background(), domain.ProductImportFormatNDJSON, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if productImport.Total != 4 || productImport.Failed != 3 {
+		t.Fatalf("unexpected import %+v", productImport)
+	}
+	if len(created.Rows) != 1 || created.Rows[0].Stock != 4 || created.Rows[0].CategoryID != "aabbccddee112233aabbcc01" {
+		t.Fatalf("unexpected rows %+v", created.Rows)
+	}
+	lines := []int{3, 4, 5}
+	for i, rowError := range productImport.Errors {
+		if rowError.Line != lines[i] {
+			t.Fatalf("expected error on line %d, got %+v", lines[i], rowError)
+		}
+	}
+	if productImport.Errors[0].Message != "price must be greater than 0" {
+		t.Fatalf("unexpected message %q", productImport.Errors[0].Message)
+	}
+}
+
+func TestProductImportService_ProcessNext(t *testing.T) {
+	categoryID := domain.ID("aabbccddee112233aabbcc01")
+
+	t.Run("nothing queued", func(t *testing.T) {
+		svc, m := setupProductImportService(t)
+
+		m.imports.EXPECT().ClaimNext(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+		found, err := svc.ProcessNext(context.Background())
+		if err != nil || found {
+			t.Fatalf("expected nothing to process, got %v (err %v)", found, err)
+		}
+	})
+
+	price := domain.Amount(100)
+
+	t.Run("upserts the rows and completes the import", func(t *testing.T) {
+		svc, m := setupProductImportService(t)
+		productImport := domain.NewProductImport(domain.ProductImportFormatCSV, []domain.ProductImportRow{
+			{Line: 2, SKU: "DONE", Name: "Already applied", Price: &price},
+			{Line: 3, SKU: "NEW", Name: "New", Price: &price, Stock: 5, CategoryID: categoryID},
+			{Line: 4, SKU: "OLD", Name: "Existing", Price: &price, Stock: 5},
+			{Line: 5, SKU: "GONE", Name: "Missing category", Price: &price, CategoryID: "aabbccddee112233aabbcc02"},
+			{Line: 6, SKU: "ARCHIVED", Name: "Archived", Price: &price},
+		}, 6, []domain.ProductImportError{{Line: 7, Message: "name is required"}})
+		productImport.ID = "aabbccddee112233aabbcc99"
+		productImport.Status = domain.ProductImportStatusRunning
+		productImport.Processed = 1
+
+		m.imports.EXPECT().ClaimNext(gomock.Any(), gomock.Any()).Return(productImport, nil)
+		m.categories.EXPECT().Lock(gomock.Any(), categoryID).Return(nil)
+		m.categories.EXPECT().Lock(gomock.Any(), domain.ID("aabbccddee112233aabbcc02")).Return(serviceerrors.NewNotFoundError("entity not found"))
+		m.productRepo.EXPECT().
+			UpsertBySKU(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, product *domain.Product) (bool, error) {
+				switch product.SKU {
+				case "NEW":
+					if product.CategoryID != categoryID {
+						t.Fatalf("expected category %s, got %s", categoryID, product.CategoryID)
+					}
+					product.ID = "aabbccddee112233aabbcc10"
+					return true, nil
+				case "OLD":
+					return false, nil
+				case "ARCHIVED":
+					return false, serviceerrors.NewConflictError("sku ARCHIVED belongs to an archived product")
+				}
+				t.Fatalf("unexpected upsert of %s", product.SKU)
+				return false, nil
+			}).Times(3)
+		// Only the created product's stock is recorded; the existing product's stands.
+		m.stockMovements.EXPECT().
+			Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, movement *domain.StockMovement) error {
+				if movement.ProductID != "aabbccddee112233aabbcc10" || movement.SKU != "" || movement.Quantity != 5 || movement.Reason != domain.StockMovementReasonImport {
+					t.Fatalf("unexpected movement %+v", movement)
+				}
+				if movement.Note != "product import aabbccddee112233aabbcc99, line 3" {
+					t.Fatalf("unexpected note %q", movement.Note)
+				}
+				return nil
+			})
+		m.imports.EXPECT().
+			SaveProgress(gomock.Any(), productImport).
+			DoAndReturn(func(_ context.Context, saved *domain.ProductImport) error {
+				if saved.Status != domain.ProductImportStatusCompleted || saved.FinishedAt == nil {
+					t.Fatalf("expected a completed import, got %+v", saved)
+				}
+				return nil
+			})
+
+		found, err := svc.ProcessNext(context.Background())
+		if err != nil || !found {
+			t.Fatalf("expected the import to be processed, got %v (err %v)", found, err)
+		}
+		if productImport.Processed != 5 || productImport.Created != 1 || productImport.Updated != 1 || productImport.Failed != 3 {
+			t.Fatalf("unexpected counters %+v", productImport)
+		}
+		if productImport.Errors[1].SKU != "GONE" || productImport.Errors[2].SKU != "ARCHIVED" {
+			t.Fatalf("unexpected errors %+v", productImport.Errors)
+		}
+	})
+
+	t.Run("leaves the import to the worker that claimed it since", func(t *testing.T) {
+		svc, m := setupProductImportService(t)
+		productImport := domain.NewProductImport(domain.ProductImportFormatCSV, []domain.ProductImportRow{
+			{Line: 2, SKU: "A", Name: "A", Price: &price},
+		}, 1, nil)
+		productImport.LeaseID = "stale-lease"
+
+		m.imports.EXPECT().ClaimNext(gomock.Any(), gomock.Any()).Return(productImport, nil)
+		m.productRepo.EXPECT().UpsertBySKU(gomock.Any(), gomock.Any()).Return(true, nil)
+		m.imports.EXPECT().
+			SaveProgress(gomock.Any(), productImport).
+			Return(serviceerrors.NewConflictError("product import is not running under this lease"))
+
+		found, err := svc.ProcessNext(context.Background())
+		if err != nil || !found {
+			t.Fatalf("expected the import to be given up without an error, got %v (err %v)", found, err)
+		}
+	})
+
+	t.Run("stops on storage errors", func(t *testing.T) {
+		svc, m := setupProductImportService(t)
+		productImport := domain.NewProductImport(domain.ProductImportFormatCSV, []domain.ProductImportRow{
+			{Line: 2, SKU: "A", Name: "A", Price: &price},
+		}, 1, nil)
+
+		m.imports.EXPECT().ClaimNext(gomock.Any(), gomock.Any()).Return(productImport, nil)
+		m.productRepo.EXPECT().UpsertBySKU(gomock.Any(), gomock.Any()).Return(false, errors.New("connection reset"))
+
+		found, err := svc.ProcessNext(context.Background())
+		if err == nil || !found {
+			t.Fatalf("expected an error, got %v (found %v)", err, found)
+		}
+		if productImport.Processed != 0 {
+			t.Fatalf("expected the row to stay pending, got %d processed", productImport.Processed)
+		}
+	})
+}
+
+func TestProductImportService_GetImport(t *testing.T) {
+	svc, m := setupProductImportService(t)
+	id := domain.ID("aabbccddee112233aabbcc99")
+
+	m.imports.EXPECT().GetByID(gomock.Any(), id).Return(nil, serviceerrors.NewNotFoundError("entity not found"))
+
+	_, err := svc.GetImport(context.Background(), id)
+	if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+		t.Fatalf("expected KindNotFound, got %v", err)
+	}
+}
+
+func TestProductImportService_ExportProducts(t *testing.T) {
+	threshold := 2
+	hoodieXLPrice := domain.NewAmountFromCents(5500)
+	products := []*domain.Product{
+		{SKU: "TEE-1", Name: "Tee", Description: "Soft, cotton", Price: 1500, Stock: 10, LowStockThreshold: &threshold, Tags: []string{"sale", "summer"}},
+		{Name: "Hoodie", Price: 5000, CategoryID: "aabbccddee112233aabbcc01", Variants: []domain.ProductVariant{
+			domain.NewProductVariant("HOODIE-S", "Small", nil, 2),
+			domain.NewProductVariant("HOODIE-XL", "Extra large", &hoodieXLPrice, 1),
+		}},
+		{Name: "Mug", Price: 800, Stock: 3},
+	}
+	stream := func(_ context.Context, fn func(product *domain.Product) error) error {
+		for _, product := range products {
+			if err := fn(product); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		svc, m := setupProductImportService(t)
+		m.productRepo.EXPECT().Stream(gomock.Any(), gomock.Any()).DoAndReturn(stream)
+
+		var out bytes.Buffer
+		if err := svc.ExportProducts(context.Background(), domain.ProductImportFormatCSV, &out); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		want := "sku,name,description,price,stock,low_stock_threshold,category_id,tags\n" +
+			"TEE-1,Tee,\"Soft, cotton\",1500,10,2,,sale|summer\n" +
+			"HOODIE-S,Small,,,2,,aabbccddee112233aabbcc01,\n" +
+			"HOODIE-XL,Extra large,,5500,1,,aabbccddee112233aabbcc01,\n"
+		if out.String() != want {
+			t.Fatalf("expected\n%s\ngot\n%s", want, out.String())
+		}
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		svc, m := setupProductImportService(t)
+		m.productRepo.EXPECT().Stream(gomock.Any(), gomock.Any()).DoAndReturn(stream)
+
+		var out bytes.Buffer
+		if err := svc.ExportProducts(context.Background(), domain.ProductImportFormatNDJSON, &out); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		want := `{"sku":"TEE-1","name":"Tee","description":"Soft, cotton","price":1500,"stock":10,"low_stock_threshold":2,"tags":["sale","summer"]}` + "\n" +
+			`{"sku":"HOODIE-S","name":"Small","description":"","price":null,"stock":2,"category_id":"aabbccddee112233aabbcc01"}` + "\n" +
+			`{"sku":"HOODIE-XL","name":"Extra large","description":"","price":5500,"stock":1,"category_id":"aabbccddee112233aabbcc01"}` + "\n"
+		if out.String() != want {
+			t.Fatalf("expected\n%s\ngot\n%s", want, out.String())
+		}
+	})
+
+	t.Run("exported csv imports back", func(t *testing.T) {
+		svc, m := setupProductImportService(t)
+		m.productRepo.EXPECT().Stream(gomock.Any(), gomock.Any()).DoAndReturn(stream)
+		created := expectImportCreated(m)
+
+		var out bytes.Buffer
+		if err := svc.ExportProducts(context.Background(), domain.ProductImportFormatCSV, &out); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		productImport, err := svc.StartImport(context.Background(), domain.ProductImportFormatCSV, &out)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		// The mug has no SKU to be matched by, so it was left out of the export.
+		if productImport.Total != 3 || productImport.Failed != 0 || len(created.Rows) != 3 {
+			t.Fatalf("unexpected import %+v", productImport)
+		}
+		skus := make([]string, len(created.Rows))
+		for i, row := range created.Rows {
+			skus[i] = row.SKU
+		}
+		if !slices.Equal(skus, []string{"TEE-1", "HOODIE-S", "HOODIE-XL"}) || created.Rows[0].Description != "Soft, cotton" {
+			t.Fatalf("unexpected rows %+v", created.Rows)
+		}
+		// The small hoodie sells at the hoodie's price and keeps doing so; the extra large one
+		// keeps its own.
+		if created.Rows[1].Price != nil || *created.Rows[2].Price != hoodieXLPrice {
+			t.Fatalf("unexpected variant prices %v, %v", created.Rows[1].Price, created.Rows[2].Price)
+		}
+	})
+}
@@ -7,20 +7,48 @@ import (
 
 	"github.com/rafaelleal24/challenge/internal/core/domain"
 	"github.com/rafaelleal24/challenge/internal/core/dto"
+	"github.com/rafaelleal24/challenge/internal/core/port"
 	"github.com/rafaelleal24/challenge/internal/core/port/mock"
+	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
 	"go.uber.org/mock/gomock"
 )
 
-func setupProductService(t *testing.T) (*ProductService, *mock.MockProductPort) {
+type productMocks struct {
+	productRepo    *mock.MockProductPort
+	categories     *mock.MockCategoryPort
+	stockMovements *mock.MockStockMovementPort
+	txManager      *mock.MockTransactionManager
+}
+
+func setupProductService(t *testing.T) (*ProductService, *productMocks) {
 	ctrl := gomock.NewController(t)
 	productRepo := mock.NewMockProductPort(ctrl)
-	svc := NewProductService(productRepo)
-	return svc, productRepo
+	categories := mock.NewMockCategoryPort(ctrl)
+	stockMovements := mock.NewMockStockMovementPort(ctrl)
+	txManager := mock.NewMockTransactionManager(ctrl)
+	svc := NewProductService(productRepo, categories, stockMovements, txManager, 10)
+	return svc, &productMocks{
+		productRepo:    productRepo,
+		categories:     categories,
+		stockMovements: stockMovements,
+		txManager:      txManager,
+	}
+}
+
+// expectCategoryLock expects the transaction that places a product in a category and the lock
+// it takes on the category.
+func expectCategoryLock(m *productMocks, categoryID domain.ID, err error) {
+	m.txManager.EXPECT().
+		WithTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		})
+	m.categories.EXPECT().Lock(gomock.Any(), categoryID).Return(err)
 }
 
 func TestProductService_CreateProduct(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
-		svc, productRepo := setupProductService(t)
+		svc, m := setupProductService(t)
 		req := &dto.CreateProductRequest{
 			Name:        "Test Product",
 			Description: "A test product",
@@ -28,7 +56,7 @@ func TestProductService_CreateProduct(t *testing.T) {
 			Stock:       50,
 		}
 
-		productRepo.EXPECT().
+		m.productRepo.EXPECT().
 			Create(gomock.Any(), gomock.Any()).
 			DoAndReturn(func(_ context.Context, p *domain.Product) error {
 				p.ID = domain.ID("aabbccddee112233aabbccdd")
@@ -56,15 +84,100 @@ func TestProductService_CreateProduct(t *testing.T) {
 		}
 	})
 
+	t.Run("with variants takes its stock from them", func(t *testing.T) {
+		svc, m := setupProductService(t)
+		largePrice := 3499
+		req := &dto.CreateProductRequest{
+			Name:  "Tee",
+			Price: 2999,
+			Stock: 99,
+			Variants: []dto.CreateVariantRequest{
+				{SKU: "TEE-M", Name: "Medium", Stock: 4},
+				{SKU: "TEE-L", Name: "Large", Price: &largePrice, Stock: 6},
+			},
+		}
+
+		m.productRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+		product, err := svc.CreateProduct(context.Background(), req)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if product.Stock != 10 || len(product.Variants) != 2 {
+			t.Fatalf("expected stock 10 across 2 variants, got %d across %d", product.Stock, len(product.Variants))
+		}
+		if product.PriceOf("TEE-M") != 2999 || product.PriceOf("TEE-L") != 3499 {
+			t.Fatalf("expected variant prices 2999 and 3499, got %d and %d", product.PriceOf("TEE-M"), product.PriceOf("TEE-L"))
+		}
+	})
+
+	t.Run("rejects duplicate skus", func(t *testing.T) {
+		svc, _ := setupProductService(t)
+
+		_, err := svc.CreateProduct(context.Background(), &dto.CreateProductRequest{
+			Name:  "Tee",
+			Price: 2999,
+			Variants: []dto.CreateVariantRequest{
+				{SKU: "TEE-M", Name: "Medium", Stock: 4},
+				{SKU: "TEE-M", Name: "Medium again", Stock: 6},
+			},
+		})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+
+	t.Run("files the product under its category with normalized tags", func(t *testing.T) {
+		svc, m := setupProductService(t)
+		categoryID := domain.ID("aabbccddee112233aabbcc01")
+
+		expectCategoryLock(m, categoryID, nil)
+		m.productRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+		product, err := svc.CreateProduct(context.Background(), &dto.CreateProductRequest{
+			Name:       "Tee",
+			Price:      2999,
+			Stock:      5,
+			CategoryID: categoryID,
+			Tags:       []string{"Summer", " sale ", "summer"},
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if product.CategoryID != categoryID {
+			t.Fatalf("expected category %s, got %s", categoryID, product.CategoryID)
+		}
+		if len(product.Tags) != 2 || product.Tags[0] != "sale" || product.Tags[1] != "summer" {
+			t.Fatalf("expected tags [sale summer], got %v", product.Tags)
+		}
+	})
+
+	t.Run("unknown category", func(t *testing.T) {
+		svc, m := setupProductService(t)
+		categoryID := domain.ID("aabbccddee112233aabbcc01")
+
+		expectCategoryLock(m, categoryID, serviceerrors.NewNotFoundError("entity not found"))
+
+		_, err := svc.CreateProduct(context.Background(), &dto.CreateProductRequest{
+			Name:       "Tee",
+			Price:      2999,
+			Stock:      5,
+			CategoryID: categoryID,
+		})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+
 	t.Run("repository error", func(t *testing.T) {
-		svc, productRepo := setupProductService(t)
+		svc, m := setupProductService(t)
 		req := &dto.CreateProductRequest{
 			Name:  "Test Product",
 			Price: 2999,
 			Stock: 10,
 		}
 
-		productRepo.EXPECT().
+		m.productRepo.EXPECT().
 			Create(gomock.Any(), gomock.Any()).
 			Return(errors.New("insert failed"))
 
@@ -80,7 +193,7 @@ func TestProductService_CreateProduct(t *testing.T) {
 
 func TestProductService_GetByID(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
-		svc, productRepo := setupProductService(t)
+		svc, m := setupProductService(t)
 		productID := domain.ID("aabbccddee112233aabbccdd")
 		expected := &domain.Product{
 			ID:    productID,
@@ -89,7 +202,7 @@ func TestProductService_GetByID(t *testing.T) {
 			Stock: 50,
 		}
 
-		productRepo.EXPECT().
+		m.productRepo.EXPECT().
 			GetByID(gomock.Any(), productID).
 			Return(expected, nil)
 
@@ -103,10 +216,10 @@ func TestProductService_GetByID(t *testing.T) {
 	})
 
 	t.Run("not found", func(t *testing.T) {
-		svc, productRepo := setupProductService(t)
+		svc, m := setupProductService(t)
 		productID := domain.ID("aabbccddee112233aabbccdd")
 
-		productRepo.EXPECT().
+		m.productRepo.EXPECT().
 			GetByID(gomock.Any(), productID).
 			Return(nil, errors.New("not found"))
 
@@ -120,83 +233,647 @@ func TestProductService_GetByID(t *testing.T) {
 	})
 }
 
-func TestProductService_GetAll(t *testing.T) {
-	t.Run("success", func(t *testing.T) {
-		svc, productRepo := setupProductService(t)
+func TestProductService_ListProducts(t *testing.T) {
+	t.Run("applies defaults", func(t *testing.T) {
+		svc, m := setupProductService(t)
 		expected := []*domain.Product{
 			{ID: domain.ID("aabbccddee112233aabbccd1"), Name: "Product 1"},
 			{ID: domain.ID("aabbccddee112233aabbccd2"), Name: "Product 2"},
 		}
 
-		productRepo.EXPECT().
-			GetAll(gomock.Any()).
-			Return(expected, nil)
+		m.productRepo.EXPECT().
+			List(gomock.Any(), port.ProductFilter{}, port.PageRequest{Limit: productListDefaultLimit, SortBy: port.ProductSortName}).
+			Return(&port.Page[*domain.Product]{Items: expected}, nil)
+
+		page, err := svc.ListProducts(context.Background(), &dto.ListProductsRequest{})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(page.Items) != 2 {
+			t.Fatalf("expected 2 products, got %d", len(page.Items))
+		}
+	})
 
-		products, err := svc.GetAll(context.Background())
+	t.Run("maps filters and sort", func(t *testing.T) {
+		svc, m := setupProductService(t)
+		minPrice, maxPrice := 100, 500
+
+		m.productRepo.EXPECT().
+			List(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, filter port.ProductFilter, page port.PageRequest) (*port.Page[*domain.Product], error) {
+				if filter.NamePrefix != "Wid" || !filter.InStockOnly {
+					t.Fatalf("unexpected filter %+v", filter)
+				}
+				if *filter.MinPrice != domain.NewAmountFromCents(minPrice) || *filter.MaxPrice != domain.NewAmountFromCents(maxPrice) {
+					t.Fatalf("unexpected price range %d-%d", *filter.MinPrice, *filter.MaxPrice)
+				}
+				if page.SortBy != port.ProductSortPrice || !page.Descending || page.Limit != 5 || page.Cursor != "abc" {
+					t.Fatalf("unexpected page %+v", page)
+				}
+				return &port.Page[*domain.Product]{}, nil
+			})
+
+		_, err := svc.ListProducts(context.Background(), &dto.ListProductsRequest{
+			NamePrefix: "Wid",
+			MinPrice:   &minPrice,
+			MaxPrice:   &maxPrice,
+			InStock:    true,
+			Sort:       "price",
+			Order:      "desc",
+			Limit:      5,
+			Cursor:     "abc",
+		})
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
-		if len(products) != 2 {
-			t.Fatalf("expected 2 products, got %d", len(products))
+	})
+
+	t.Run("inverted price range", func(t *testing.T) {
+		svc, _ := setupProductService(t)
+		minPrice, maxPrice := 500, 100
+
+		_, err := svc.ListProducts(context.Background(), &dto.ListProductsRequest{MinPrice: &minPrice, MaxPrice: &maxPrice})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+
+	t.Run("invalid sort field", func(t *testing.T) {
+		svc, _ := setupProductService(t)
+
+		_, err := svc.ListProducts(context.Background(), &dto.ListProductsRequest{Sort: "stock"})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
 		}
 	})
 
-	t.Run("empty list", func(t *testing.T) {
-		svc, productRepo := setupProductService(t)
+	t.Run("expands the category to its subtree and normalizes the tag", func(t *testing.T) {
+		svc, m := setupProductService(t)
+		clothing := domain.ID("aabbccddee112233aabbcc01")
+		shirts := domain.ID("aabbccddee112233aabbcc02")
+		books := domain.ID("aabbccddee112233aabbcc03")
 
-		productRepo.EXPECT().
-			GetAll(gomock.Any()).
-			Return([]*domain.Product{}, nil)
+		m.categories.EXPECT().List(gomock.Any()).Return([]*domain.Category{
+			{ID: books, Name: "Books"},
+			{ID: clothing, Name: "Clothing"},
+			{ID: shirts, Name: "Shirts", ParentID: clothing},
+		}, nil)
+		m.productRepo.EXPECT().
+			List(gomock.Any(), port.ProductFilter{CategoryIDs: []domain.ID{clothing, shirts}, Tag: "sale"}, gomock.Any()).
+			Return(&port.Page[*domain.Product]{}, nil)
 
-		products, err := svc.GetAll(context.Background())
+		_, err := svc.ListProducts(context.Background(), &dto.ListProductsRequest{Category: string(clothing), Tag: " Sale "})
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
-		if len(products) != 0 {
-			t.Fatalf("expected 0 products, got %d", len(products))
+	})
+
+	t.Run("unknown category", func(t *testing.T) {
+		svc, m := setupProductService(t)
+
+		m.categories.EXPECT().List(gomock.Any()).Return(nil, nil)
+
+		_, err := svc.ListProducts(context.Background(), &dto.ListProductsRequest{Category: "aabbccddee112233aabbcc01"})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+
+	t.Run("invalid category ID", func(t *testing.T) {
+		svc, _ := setupProductService(t)
+
+		_, err := svc.ListProducts(context.Background(), &dto.ListProductsRequest{Category: "clothing"})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
 		}
 	})
 
 	t.Run("repository error", func(t *testing.T) {
-		svc, productRepo := setupProductService(t)
+		svc, m := setupProductService(t)
 
-		productRepo.EXPECT().
-			GetAll(gomock.Any()).
+		m.productRepo.EXPECT().
+			List(gomock.Any(), gomock.Any(), gomock.Any()).
 			Return(nil, errors.New("db error"))
 
-		_, err := svc.GetAll(context.Background())
+		_, err := svc.ListProducts(context.Background(), &dto.ListProductsRequest{})
 		if err == nil {
 			t.Fatal("expected error, got nil")
 		}
 	})
 }
 
-func TestProductService_DeductStock(t *testing.T) {
-	t.Run("success", func(t *testing.T) {
-		svc, productRepo := setupProductService(t)
-		productID := domain.ID("aabbccddee112233aabbccdd")
+func TestProductService_ListLegacyProducts(t *testing.T) {
+	t.Run("returns one page at the largest size", func(t *testing.T) {
+		svc, m := setupProductService(t)
+		first := &domain.Product{ID: domain.ID("aabbccddee112233aabbccd1"), Name: "Product 1"}
 
-		productRepo.EXPECT().
-			DeductStock(gomock.Any(), productID, 5).
+		m.productRepo.EXPECT().
+			List(gomock.Any(), port.ProductFilter{}, port.PageRequest{Limit: productListMaxLimit, SortBy: port.ProductSortPrice}).
+			Return(&port.Page[*domain.Product]{Items: []*domain.Product{first}, NextCursor: "next"}, nil)
+
+		page, err := svc.ListLegacyProducts(context.Background(), &dto.ListProductsRequest{Sort: "price"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(page.Items) != 1 || page.Items[0] != first || page.NextCursor != "next" {
+			t.Fatalf("expected the first page with its cursor, got %+v", page)
+		}
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		svc, _ := setupProductService(t)
+
+		_, err := svc.ListLegacyProducts(context.Background(), &dto.ListProductsRequest{Sort: "stock"})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+}
+
+func expectStockMovement(t *testing.T, m *productMocks, productID domain.ID, quantity int, reason domain.StockMovementReason) {
+	t.Helper()
+	m.stockMovements.EXPECT().
+		Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, movement *domain.StockMovement) error {
+			if movement.ProductID != productID || movement.Quantity != quantity || movement.Reason != reason {
+				t.Fatalf("unexpected movement %+v", movement)
+			}
+			return nil
+		})
+}
+
+func TestProductService_DeductStockBatch(t *testing.T) {
+	productID := domain.ID("aabbccddee112233aabbccdd")
+	otherProductID := domain.ID("aabbccddee112233aabbccd2")
+	orderID := domain.ID("ffeeddccbb112233aabbccdd")
+	lines := []port.StockLine{
+		{ProductID: productID, Quantity: 5},
+		{ProductID: otherProductID, Quantity: 1},
+	}
+
+	t.Run("success - records an order deduction per line", func(t *testing.T) {
+		svc, m := setupProductService(t)
+
+		m.productRepo.EXPECT().
+			DeductStockBatch(gomock.Any(), lines, gomock.Any()).
 			Return(nil)
+		expectStockMovement(t, m, productID, -5, domain.StockMovementReasonOrderDeduction)
+		expectStockMovement(t, m, otherProductID, -1, domain.StockMovementReasonOrderDeduction)
 
-		err := svc.DeductStock(context.Background(), productID, 5)
+		err := svc.DeductStockBatch(context.Background(), lines, orderID)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
 	})
 
-	t.Run("insufficient stock", func(t *testing.T) {
-		svc, productRepo := setupProductService(t)
-		productID := domain.ID("aabbccddee112233aabbccdd")
+	t.Run("raises low stock alert from the quantity deducted per product", func(t *testing.T) {
+		svc, m := setupProductService(t)
+		batch := []port.StockLine{
+			{ProductID: productID, Quantity: 2},
+			{ProductID: otherProductID, Quantity: 1},
+			{ProductID: productID, Quantity: 3},
+		}
+
+		m.productRepo.EXPECT().
+			DeductStockBatch(gomock.Any(), batch, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ []port.StockLine, alert port.StockAlertBuilder) error {
+				event := alert(&domain.Product{ID: productID, Stock: 8}, "")
+				lowStock, ok := event.(*domain.ProductLowStockEvent)
+				if !ok {
+					t.Fatalf("expected *domain.ProductLowStockEvent, got %T", event)
+				}
+				if lowStock.Threshold != 10 || lowStock.Stock != 8 {
+					t.Fatalf("unexpected event %+v", lowStock)
+				}
+
+				// The other product lost a single unit, going from 10 to 9 and crossing the threshold.
+				if event := alert(&domain.Product{ID: otherProductID, Stock: 9}, ""); event == nil {
+					t.Fatal("expected a low stock alert for the other product")
+				}
+				if event := alert(&domain.Product{ID: otherProductID, Stock: 12}, ""); event != nil {
+					t.Fatalf("expected no alert above the threshold, got %T", event)
+				}
+				return nil
+			})
+		m.stockMovements.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(3)
+
+		if err := svc.DeductStockBatch(context.Background(), batch, orderID); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("raises out of stock alert for the variant that runs out", func(t *testing.T) {
+		svc, m := setupProductService(t)
+		batch := []port.StockLine{
+			{ProductID: productID, SKU: "TEE-M", Quantity: 2},
+			{ProductID: productID, SKU: "TEE-L", Quantity: 1},
+		}
+		// After the deduction the medium tee is gone while the large one still has plenty.
+		tee := &domain.Product{ID: productID, Stock: 20, Variants: []domain.ProductVariant{
+			domain.NewProductVariant("TEE-M", "Medium", nil, 0),
+			domain.NewProductVariant("TEE-L", "Large", nil, 20),
+		}}
 
-		productRepo.EXPECT().
-			DeductStock(gomock.Any(), productID, 999).
-			Return(errors.New("insufficient stock"))
+		m.productRepo.EXPECT().
+			DeductStockBatch(gomock.Any(), batch, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ []port.StockLine, alert port.StockAlertBuilder) error {
+				outOfStock, ok := alert(tee, "TEE-M").(*domain.ProductOutOfStockEvent)
+				if !ok || outOfStock.SKU != "TEE-M" {
+					t.Fatalf("expected TEE-M to be out of stock, got %+v", outOfStock)
+				}
+				if event := alert(tee, "TEE-L"); event != nil {
+					t.Fatalf("expected no alert for TEE-L, got %+v", event)
+				}
+				return nil
+			})
+		m.stockMovements.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(2)
 
-		err := svc.DeductStock(context.Background(), productID, 999)
+		if err := svc.DeductStockBatch(context.Background(), batch, orderID); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("insufficient stock records no movement", func(t *testing.T) {
+		svc, m := setupProductService(t)
+
+		m.productRepo.EXPECT().
+			DeductStockBatch(gomock.Any(), lines, gomock.Any()).
+			Return(serviceerrors.NewUnprocessableEntityError("insufficient stock"))
+
+		err := svc.DeductStockBatch(context.Background(), lines, orderID)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindUnprocessableEntity) {
+			t.Fatalf("expected KindUnprocessableEntity, got %v", err)
+		}
+	})
+}
+
+func TestProductService_RestoreStock(t *testing.T) {
+	productID := domain.ID("aabbccddee112233aabbccdd")
+	orderID := domain.ID("ffeeddccbb112233aabbccdd")
+
+	t.Run("success - records a cancellation", func(t *testing.T) {
+		svc, m := setupProductService(t)
+
+		m.productRepo.EXPECT().
+			RestoreStock(gomock.Any(), productID, "", 5).
+			Return(nil)
+		expectStockMovement(t, m, productID, 5, domain.StockMovementReasonCancellation)
+
+		err := svc.RestoreStock(context.Background(), productID, "", 5, orderID)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		svc, m := setupProductService(t)
+
+		m.productRepo.EXPECT().
+			RestoreStock(gomock.Any(), productID, "", 5).
+			Return(errors.New("db error"))
+
+		err := svc.RestoreStock(context.Background(), productID, "", 5, orderID)
 		if err == nil {
 			t.Fatal("expected error, got nil")
 		}
 	})
 }
+
+func TestProductService_AdjustStock(t *testing.T) {
+	productID := domain.ID("aabbccddee112233aabbccdd")
+
+	// expectAdjustment expects the product lookup and the transaction of an accepted adjustment.
+	expectAdjustment := func(m *productMocks, product *domain.Product) {
+		m.productRepo.EXPECT().GetByID(gomock.Any(), productID).Return(product, nil)
+		m.txManager.EXPECT().
+			WithTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
+	}
+
+	t.Run("restock adds stock", func(t *testing.T) {
+		svc, m := setupProductService(t)
+		expectAdjustment(m, &domain.Product{ID: productID})
+
+		m.productRepo.EXPECT().RestoreStock(gomock.Any(), productID, "", 10).Return(nil)
+		expectStockMovement(t, m, productID, 10, domain.StockMovementReasonRestock)
+
+		movement, err := svc.AdjustStock(context.Background(), productID, &dto.StockAdjustmentRequest{
+			Quantity: 10, Reason: "restock", Note: "supplier delivery",
+		}, "clerk-1")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if movement.Actor != "clerk-1" || movement.Note != "supplier delivery" {
+			t.Fatalf("expected actor and note to be recorded, got %+v", movement)
+		}
+	})
+
+	t.Run("negative correction removes available stock", func(t *testing.T) {
+		svc, m := setupProductService(t)
+		expectAdjustment(m, &domain.Product{ID: productID})
+
+		m.productRepo.EXPECT().DeductStock(gomock.Any(), productID, "", 3, gomock.Any()).Return(nil)
+		expectStockMovement(t, m, productID, -3, domain.StockMovementReasonCorrection)
+
+		if _, err := svc.AdjustStock(context.Background(), productID, &dto.StockAdjustmentRequest{
+			Quantity: -3, Reason: "correction",
+		}, ""); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("negative restock is rejected", func(t *testing.T) {
+		svc, _ := setupProductService(t)
+
+		_, err := svc.AdjustStock(context.Background(), productID, &dto.StockAdjustmentRequest{
+			Quantity: -3, Reason: "restock",
+		}, "")
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+
+	t.Run("order reasons are rejected", func(t *testing.T) {
+		svc, _ := setupProductService(t)
+
+		_, err := svc.AdjustStock(context.Background(), productID, &dto.StockAdjustmentRequest{
+			Quantity: 3, Reason: "cancellation",
+		}, "")
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+
+	t.Run("insufficient stock leaves no movement", func(t *testing.T) {
+		svc, m := setupProductService(t)
+		expectAdjustment(m, &domain.Product{ID: productID})
+
+		m.productRepo.EXPECT().
+			DeductStock(gomock.Any(), productID, "", 50, gomock.Any()).
+			Return(serviceerrors.NewUnprocessableEntityError("insufficient stock"))
+
+		_, err := svc.AdjustStock(context.Background(), productID, &dto.StockAdjustmentRequest{
+			Quantity: -50, Reason: "correction",
+		}, "")
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindUnprocessableEntity) {
+			t.Fatalf("expected KindUnprocessableEntity, got %v", err)
+		}
+	})
+}
+
+func TestProductService_AdjustStock_Variants(t *testing.T) {
+	productID := domain.ID("aabbccddee112233aabbccdd")
+	product := &domain.Product{
+		ID: productID,
+		Variants: []domain.ProductVariant{
+			{SKU: "TEE-S", Name: "Small", Stock: 4},
+			{SKU: "TEE-M", Name: "Medium", Stock: 6},
+		},
+	}
+
+	t.Run("adjusts the variant named by sku", func(t *testing.T) {
+		svc, m := setupProductService(t)
+
+		m.productRepo.EXPECT().GetByID(gomock.Any(), productID).Return(product, nil)
+		m.txManager.EXPECT().
+			WithTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			})
+		m.productRepo.EXPECT().RestoreStock(gomock.Any(), productID, "TEE-M", 5).Return(nil)
+		m.stockMovements.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+		movement, err := svc.AdjustStock(context.Background(), productID, &dto.StockAdjustmentRequest{
+			SKU: "TEE-M", Quantity: 5, Reason: "restock",
+		}, "")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if movement.SKU != "TEE-M" {
+			t.Fatalf("expected movement for TEE-M, got %q", movement.SKU)
+		}
+	})
+
+	t.Run("requires a sku", func(t *testing.T) {
+		svc, m := setupProductService(t)
+
+		m.productRepo.EXPECT().GetByID(gomock.Any(), productID).Return(product, nil)
+
+		_, err := svc.AdjustStock(context.Background(), productID, &dto.StockAdjustmentRequest{
+			Quantity: 5, Reason: "restock",
+		}, "")
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+
+	t.Run("unknown sku", func(t *testing.T) {
+		svc, m := setupProductService(t)
+
+		m.productRepo.EXPECT().GetByID(gomock.Any(), productID).Return(product, nil)
+
+		_, err := svc.AdjustStock(context.Background(), productID, &dto.StockAdjustmentRequest{
+			SKU: "TEE-XL", Quantity: 5, Reason: "restock",
+		}, "")
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+
+	t.Run("sku on a product without variants", func(t *testing.T) {
+		svc, m := setupProductService(t)
+
+		m.productRepo.EXPECT().GetByID(gomock.Any(), productID).Return(&domain.Product{ID: productID}, nil)
+
+		_, err := svc.AdjustStock(context.Background(), productID, &dto.StockAdjustmentRequest{
+			SKU: "TEE-M", Quantity: 5, Reason: "restock",
+		}, "")
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+}
+
+func TestProductService_GetStockMovements(t *testing.T) {
+	productID := domain.ID("aabbccddee112233aabbccdd")
+
+	t.Run("success", func(t *testing.T) {
+		svc, m := setupProductService(t)
+
+		m.productRepo.EXPECT().GetByID(gomock.Any(), productID).Return(&domain.Product{ID: productID}, nil)
+		m.stockMovements.EXPECT().
+			GetByProductID(gomock.Any(), productID, int64(productListDefaultLimit), "abc").
+			Return(&port.Page[*domain.StockMovement]{Items: []*domain.StockMovement{{ProductID: productID}}}, nil)
+
+		page, err := svc.GetStockMovements(context.Background(), productID, &dto.ListStockMovementsRequest{Cursor: "abc"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(page.Items) != 1 {
+			t.Fatalf("expected 1 movement, got %d", len(page.Items))
+		}
+	})
+
+	t.Run("product not found", func(t *testing.T) {
+		svc, m := setupProductService(t)
+
+		m.productRepo.EXPECT().
+			GetByID(gomock.Any(), productID).
+			Return(nil, serviceerrors.NewNotFoundError("product not found"))
+
+		_, err := svc.GetStockMovements(context.Background(), productID, &dto.ListStockMovementsRequest{})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+}
+
+func TestProductService_SearchProducts(t *testing.T) {
+	t.Run("trims query and applies default limit", func(t *testing.T) {
+		svc, m := setupProductService(t)
+
+		m.productRepo.EXPECT().
+			Search(gomock.Any(), "widget", port.PageRequest{Limit: productListDefaultLimit, Cursor: "abc"}).
+			Return(&port.Page[*domain.Product]{Items: []*domain.Product{{Name: "Widget"}}}, nil)
+
+		page, err := svc.SearchProducts(context.Background(), &dto.SearchProductsRequest{Query: "  widget ", Cursor: "abc"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(page.Items) != 1 {
+			t.Fatalf("expected 1 product, got %d", len(page.Items))
+		}
+	})
+
+	t.Run("blank query", func(t *testing.T) {
+		svc, _ := setupProductService(t)
+
+		_, err := svc.SearchProducts(context.Background(), &dto.SearchProductsRequest{Query: "   "})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+}
+
+func TestProductService_UpdateProduct(t *testing.T) {
+	productID := domain.ID("aabbccddee112233aabbccdd")
+
+	t.Run("success - converts price to amount", func(t *testing.T) {
+		svc, m := setupProductService(t)
+		name := "Renamed"
+		price := 1299
+
+		m.productRepo.EXPECT().
+			Update(gomock.Any(), productID, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ domain.ID, update port.ProductUpdate) (*domain.Product, error) {
+				if update.Name == nil || *update.Name != name {
+					t.Fatalf("expected name %q, got %v", name, update.Name)
+				}
+				if update.Description != nil {
+					t.Fatalf("expected description to be left unchanged, got %q", *update.Description)
+				}
+				if update.Price == nil || *update.Price != domain.NewAmountFromCents(price) {
+					t.Fatalf("expected price %d, got %v", price, update.Price)
+				}
+				return &domain.Product{ID: productID, Name: name, Price: *update.Price}, nil
+			})
+
+		product, err := svc.UpdateProduct(context.Background(), productID, &dto.UpdateProductRequest{Name: &name, Price: &price})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if product.Name != name {
+			t.Fatalf("expected name %q, got %q", name, product.Name)
+		}
+	})
+
+	t.Run("moves category and clears tags", func(t *testing.T) {
+		svc, m := setupProductService(t)
+		categoryID := domain.ID("aabbccddee112233aabbcc01")
+		tags := []string{" "}
+
+		expectCategoryLock(m, categoryID, nil)
+		m.productRepo.EXPECT().
+			Update(gomock.Any(), productID, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ domain.ID, update port.ProductUpdate) (*domain.Product, error) {
+				if update.CategoryID == nil || *update.CategoryID != categoryID {
+					t.Fatalf("expected category %s, got %v", categoryID, update.CategoryID)
+				}
+				if update.Tags == nil || len(*update.Tags) != 0 {
+					t.Fatalf("expected tags to be cleared, got %v", update.Tags)
+				}
+				return &domain.Product{ID: productID, CategoryID: categoryID}, nil
+			})
+
+		_, err := svc.UpdateProduct(context.Background(), productID, &dto.UpdateProductRequest{CategoryID: &categoryID, Tags: &tags})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("removes the product from its category", func(t *testing.T) {
+		svc, m := setupProductService(t)
+		var none domain.ID
+
+		m.productRepo.EXPECT().
+			Update(gomock.Any(), productID, port.ProductUpdate{CategoryID: &none}).
+			Return(&domain.Product{ID: productID}, nil)
+
+		_, err := svc.UpdateProduct(context.Background(), productID, &dto.UpdateProductRequest{CategoryID: &none})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("no fields", func(t *testing.T) {
+		svc, _ := setupProductService(t)
+
+		_, err := svc.UpdateProduct(context.Background(), productID, &dto.UpdateProductRequest{})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindInvalidRequest) {
+			t.Fatalf("expected KindInvalidRequest, got %v", err)
+		}
+	})
+
+	t.Run("archived product", func(t *testing.T) {
+		svc, m := setupProductService(t)
+		description := "new"
+
+		m.productRepo.EXPECT().
+			Update(gomock.Any(), productID, gomock.Any()).
+			Return(nil, serviceerrors.NewUnprocessableEntityError("product is archived"))
+
+		_, err := svc.UpdateProduct(context.Background(), productID, &dto.UpdateProductRequest{Description: &description})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindUnprocessableEntity) {
+			t.Fatalf("expected KindUnprocessableEntity, got %v", err)
+		}
+	})
+}
+
+func TestProductService_ArchiveProduct(t *testing.T) {
+	productID := domain.ID("aabbccddee112233aabbccdd")
+
+	t.Run("success", func(t *testing.T) {
+		svc, m := setupProductService(t)
+
+		m.productRepo.EXPECT().Archive(gomock.Any(), productID).Return(nil)
+
+		if err := svc.ArchiveProduct(context.Background(), productID); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		svc, m := setupProductService(t)
+
+		m.productRepo.EXPECT().
+			Archive(gomock.Any(), productID).
+			Return(serviceerrors.NewNotFoundError("product not found"))
+
+		err := svc.ArchiveProduct(context.Background(), productID)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindNotFound) {
+			t.Fatalf("expected KindNotFound, got %v", err)
+		}
+	})
+}
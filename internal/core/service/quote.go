@@ -0,0 +1,88 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
+)
+
+type quoteTokenItem struct {
+	ProductID domain.ID     `json:"product_id"`
+	SKU       string        `json:"sku,omitempty"`
+	Quantity  int           `json:"quantity"`
+	UnitPrice domain.Amount `json:"unit_price"`
+}
+
+type quoteTokenPayload struct {
+	Items     []quoteTokenItem `json:"items"`
+	ExpiresAt time.Time        `json:"expires_at"`
+}
+
+// QuoteSigner issues and verifies quote tokens. A token is the JSON payload and its
+// HMAC-SHA256, both base64url encoded and joined by a dot, so it needs no server-side state.
+type QuoteSigner struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+func NewQuoteSigner(secret []byte, ttl time.Duration) *QuoteSigner {
+	return &QuoteSigner{secret: secret, ttl: ttl}
+}
+
+func (s *QuoteSigner) sign(items []domain.OrderItem) (string, time.Time, error) {
+	payload := quoteTokenPayload{
+		Items:     make([]quoteTokenItem, len(items)),
+		ExpiresAt: time.Now().Add(s.ttl).UTC(),
+	}
+	for i, item := range items {
+		payload.Items[i] = quoteTokenItem{
+			ProductID: item.ProductID,
+			SKU:       item.SKU,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+		}
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(data)
+	return encoded + "." + s.signature(encoded), payload.ExpiresAt, nil
+}
+
+// verify checks the token signature and expiry and returns the quoted items.
+func (s *QuoteSigner) verify(token string) ([]quoteTokenItem, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.signature(encoded))) {
+		return nil, serviceerrors.NewInvalidRequestError("invalid quote token")
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, serviceerrors.NewInvalidRequestError("invalid quote token")
+	}
+	var payload quoteTokenPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, serviceerrors.NewInvalidRequestError("invalid quote token")
+	}
+
+	if time.Now().After(payload.ExpiresAt) {
+		return nil, serviceerrors.NewUnprocessableEntityError("quote has expired")
+	}
+
+	return payload.Items, nil
+}
+
+func (s *QuoteSigner) signature(encoded string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
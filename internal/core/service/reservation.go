@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/logger"
+	"github.com/rafaelleal24/challenge/internal/core/port"
+	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
+)
+
+// reservationReleaseAttempts bounds how often the sweeper tries to release one reservation.
+const reservationReleaseAttempts = 3
+
+type ReservationService struct {
+	reservationRepository port.ReservationPort
+	productService        *ProductService
+	txManager             port.TransactionManager
+	ttl                   time.Duration
+}
+
+func NewReservationService(
+	reservationRepository port.ReservationPort,
+	productService *ProductService,
+	txManager port.TransactionManager,
+	ttl time.Duration,
+) *ReservationService {
+	return &ReservationService{
+		reservationRepository: reservationRepository,
+		productService:        productService,
+		txManager:             txManager,
+		ttl:                   ttl,
+	}
+}
+
+// Reserve holds the stock of every order item until the reservation TTL elapses.
+// It must run in the transaction that creates the order.
+func (s *ReservationService) Reserve(ctx context.Context, order *domain.Order) error {
+	if err := s.productService.ReserveStockBatch(ctx, orderStockLines(order.Items)); err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(s.ttl)
+	reservations := make([]*domain.Reservation, len(order.Items))
+	for i, item := range order.Items {
+		reservations[i] = domain.NewReservation(order.ID, item.ProductID, item.SKU, item.Quantity, expiresAt)
+	}
+	return s.reservationRepository.Create(ctx, reservations)
+}
+
+// Commit turns the order reservations into stock deductions. Reservations that were already
+// released on expiry are deducted from the stock still available, failing if it ran out.
+func (s *ReservationService) Commit(ctx context.Context, order *domain.Order) error {
+	reservations, err := s.reservationRepository.GetByOrderID(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+
+	// Orders placed before reservations existed have none; their stock was deducted at creation.
+	var released []port.StockLine
+	for _, reservation := range reservations {
+		switch reservation.Status {
+		case domain.ReservationStatusActive:
+			if err := s.reservationRepository.UpdateStatus(ctx, reservation.ID, domain.ReservationStatusActive, domain.ReservationStatusCommitted); err != nil {
+				return err
+			}
+			if err := s.productService.CommitReservedStock(ctx, reservation.ProductID, reservation.SKU, reservation.Quantity, order.ID); err != nil {
+				return err
+			}
+		case domain.ReservationStatusReleased:
+			if err := s.reservationRepository.UpdateStatus(ctx, reservation.ID, domain.ReservationStatusReleased, domain.ReservationStatusCommitted); err != nil {
+				return err
+			}
+			released = append(released, port.StockLine{ProductID: reservation.ProductID, SKU: reservation.SKU, Quantity: reservation.Quantity})
+		}
+	}
+	if len(released) == 0 {
+		return nil
+	}
+	return s.productService.DeductStockBatch(ctx, released, order.ID)
+}
+
+// Release gives back the stock held for an order that will not be processed.
+func (s *ReservationService) Release(ctx context.Context, order *domain.Order) error {
+	reservations, err := s.reservationRepository.GetByOrderID(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+
+	// Orders placed before reservations existed had their stock deducted at creation.
+	if len(reservations) == 0 {
+		for _, item := range order.Items {
+			if err := s.productService.RestoreStock(ctx, item.ProductID, item.SKU, item.Quantity, order.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, reservation := range reservations {
+		if reservation.Status != domain.ReservationStatusActive {
+			continue
+		}
+		if err := s.release(ctx, reservation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReleaseExpired releases up to limit expired reservations and returns how many it released.
+// Reservations committed or released concurrently, e.g. by another replica, are skipped, and
+// so are those that keep failing, to be picked up again by the next sweep; one failure never
+// holds back the rest of the batch.
+func (s *ReservationService) ReleaseExpired(ctx context.Context, limit int64) (int, error) {
+	expired, err := s.reservationRepository.GetExpired(ctx, time.Now(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, reservation := range expired {
+		err := s.releaseExpired(ctx, reservation)
+		if serviceerrors.IsOfKind(err, serviceerrors.KindConflict) {
+			continue
+		}
+		if err != nil {
+			logger.Error(ctx, "reservation: release expired failed", err, map[string]any{
+				"reservation_id": reservation.ID,
+				"order_id":       reservation.OrderID,
+			})
+			continue
+		}
+		released++
+	}
+
+	return released, nil
+}
+
+// releaseExpired releases one expired reservation in its own transaction, retrying transient
+// failures such as write conflicts with orders committing the same products.
+func (s *ReservationService) releaseExpired(ctx context.Context, reservation *domain.Reservation) error {
+	var err error
+	for attempt := 1; attempt <= reservationReleaseAttempts; attempt++ {
+		err = s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+			return s.release(txCtx, reservation)
+		})
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindUnavailable) {
+			return err
+		}
+		logger.Warn(ctx, "reservation: release expired interrupted, retrying", map[string]any{
+			"reservation_id": reservation.ID,
+			"attempt":        attempt,
+		})
+	}
+	return err
+}
+
+func orderStockLines(items []domain.OrderItem) []port.StockLine {
+	lines := make([]port.StockLine, len(items))
+	for i, item := range items {
+		lines[i] = port.StockLine{ProductID: item.ProductID, SKU: item.SKU, Quantity: item.Quantity}
+	}
+	return lines
+}
+
+func (s *ReservationService) release(ctx context.Context, reservation *domain.Reservation) error {
+	if err := s.reservationRepository.UpdateStatus(ctx, reservation.ID, domain.ReservationStatusActive, domain.ReservationStatusReleased); err != nil {
+		return err
+	}
+	return s.productService.ReleaseReservedStock(ctx, reservation.ProductID, reservation.SKU, reservation.Quantity)
+}
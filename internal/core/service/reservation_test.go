@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rafaelleal24/challenge/internal/core/domain"
+	"github.com/rafaelleal24/challenge/internal/core/port"
+	"github.com/rafaelleal24/challenge/internal/core/port/mock"
+	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
+	"go.uber.org/mock/gomock"
+)
+
+type reservationMocks struct {
+	reservationRepo *mock.MockReservationPort
+	productRepo     *mock.MockProductPort
+	movements       *mock.MockStockMovementPort
+	txManager       *mock.MockTransactionManager
+}
+
+func setupReservationService(t *testing.T) (*ReservationService, *reservationMocks) {
+	ctrl := gomock.NewController(t)
+	reservationRepo := mock.NewMockReservationPort(ctrl)
+	productRepo := mock.NewMockProductPort(ctrl)
+	txManager := mock.NewMockTransactionManager(ctrl)
+	movements := mock.NewMockStockMovementPort(ctrl)
+
+	svc := NewReservationService(reservationRepo, NewProductService(productRepo, mock.NewMockCategoryPort(ctrl), movements, txManager, 10), txManager, 10*time.Minute)
+	return svc, &reservationMocks{
+		reservationRepo: reservationRepo,
+		productRepo:     productRepo,
+		movements:       movements,
+		txManager:       txManager,
+	}
+}
+
+func TestReservationService_Reserve(t *testing.T) {
+	order := &domain.Order{
+		ID: "aabbccddee112233aabbccdd",
+		Items: []domain.OrderItem{
+			{ProductID: "aabbccddee112233aabbccd1", Quantity: 2},
+			{ProductID: "aabbccddee112233aabbccd2", Quantity: 1},
+		},
+	}
+
+	t.Run("reserves stock and records reservations", func(t *testing.T) {
+		svc, m := setupReservationService(t)
+
+		m.productRepo.EXPECT().ReserveStockBatch(gomock.Any(), []port.StockLine{
+			{ProductID: "aabbccddee112233aabbccd1", Quantity: 2},
+			{ProductID: "aabbccddee112233aabbccd2", Quantity: 1},
+		}, gomock.Any()).Return(nil)
+		m.reservationRepo.EXPECT().
+			Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, reservations []*domain.Reservation) error {
+				if len(reservations) != 2 {
+					t.Fatalf("expected 2 reservations, got %d", len(reservations))
+				}
+				for _, r := range reservations {
+					if r.OrderID != order.ID || r.Status != domain.ReservationStatusActive {
+						t.Fatalf("unexpected reservation %+v", r)
+					}
+				}
+				return nil
+			})
+
+		if err := svc.Reserve(context.Background(), order); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("insufficient stock", func(t *testing.T) {
+		svc, m := setupReservationService(t)
+
+		m.productRepo.EXPECT().
+			ReserveStockBatch(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(serviceerrors.NewUnprocessableEntityError("insufficient stock"))
+
+		err := svc.Reserve(context.Background(), order)
+		if !serviceerrors.IsOfKind(err, serviceerrors.KindUnprocessableEntity) {
+			t.Fatalf("expected KindUnprocessableEntity, got %v", err)
+		}
+	})
+}
+
+func TestReservationService_Commit(t *testing.T) {
+	order := &domain.Order{ID: "aabbccddee112233aabbccdd"}
+
+	t.Run("commits active and skips committed reservations", func(t *testing.T) {
+		svc, m := setupReservationService(t)
+		reservations := []*domain.Reservation{
+			{ID: "eeffaabbee112233aabbccd1", ProductID: "aabbccddee112233aabbccd1", Quantity: 2, Status: domain.ReservationStatusActive},
+			{ID: "eeffaabbee112233aabbccd2", ProductID: "aabbccddee112233aabbccd2", Quantity: 1, Status: domain.ReservationStatusCommitted},
+		}
+
+		m.reservationRepo.EXPECT().GetByOrderID(gomock.Any(), order.ID).Return(reservations, nil)
+		m.reservationRepo.EXPECT().
+			UpdateStatus(gomock.Any(), reservations[0].ID, domain.ReservationStatusActive, domain.ReservationStatusCommitted).
+			Return(nil)
+		m.productRepo.EXPECT().CommitReservedStock(gomock.Any(), reservations[0].ProductID, "", 2).Return(nil)
+		m.movements.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+		if err := svc.Commit(context.Background(), order); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("deducts every released reservation in one batch", func(t *testing.T) {
+		svc, m := setupReservationService(t)
+		reservations := []*domain.Reservation{
+			{ID: "eeffaabbee112233aabbccd1", ProductID: "aabbccddee112233aabbccd1", Quantity: 2, Status: domain.ReservationStatusReleased},
+			{ID: "eeffaabbee112233aabbccd2", ProductID: "aabbccddee112233aabbccd2", Quantity: 1, Status: domain.ReservationStatusReleased},
+		}
+
+		m.reservationRepo.EXPECT().GetByOrderID(gomock.Any(), order.ID).Return(reservations, nil)
+		for _, r := range reservations {
+			m.reservationRepo.EXPECT().
+				UpdateStatus(gomock.Any(), r.ID, domain.ReservationStatusReleased, domain.ReservationStatusCommitted).
+				Return(nil)
+		}
+		m.productRepo.EXPECT().
+			DeductStockBatch(gomock.Any(), []port.StockLine{
+				{ProductID: "aabbccddee112233aabbccd1", Quantity: 2},
+				{ProductID: "aabbccddee112233aabbccd2", Quantity: 1},
+			}, gomock.Any()).
+			Return(nil)
+		m.movements.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+		if err := svc.Commit(context.Background(), order); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("order without reservations is left alone", func(t *testing.T) {
+		svc, m := setupReservationService(t)
+
+		m.reservationRepo.EXPECT().GetByOrderID(gomock.Any(), order.ID).Return(nil, nil)
+
+		if err := svc.Commit(context.Background(), order); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+}
+
+func TestReservationService_Release(t *testing.T) {
+	t.Run("releases only active reservations", func(t *testing.T) {
+		svc, m := setupReservationService(t)
+		order := &domain.Order{ID: "aabbccddee112233aabbccdd"}
+		reservations := []*domain.Reservation{
+			{ID: "eeffaabbee112233aabbccd1", ProductID: "aabbccddee112233aabbccd1", Quantity: 2, Status: domain.ReservationStatusActive},
+			{ID: "eeffaabbee112233aabbccd2", ProductID: "aabbccddee112233aabbccd2", Quantity: 1, Status: domain.ReservationStatusReleased},
+		}
+
+		m.reservationRepo.EXPECT().GetByOrderID(gomock.Any(), order.ID).Return(reservations, nil)
+		m.reservationRepo.EXPECT().
+			UpdateStatus(gomock.Any(), reservations[0].ID, domain.ReservationStatusActive, domain.ReservationStatusReleased).
+			Return(nil)
+		m.productRepo.EXPECT().ReleaseReservedStock(gomock.Any(), reservations[0].ProductID, "", 2).Return(nil)
+
+		if err := svc.Release(context.Background(), order); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+}
+
+func TestReservationService_ReleaseExpired(t *testing.T) {
+	t.Run("releases expired reservations and skips conflicts", func(t *testing.T) {
+		svc, m := setupReservationService(t)
+		expired := []*domain.Reservation{
+			{ID: "eeffaabbee112233aabbccd1", ProductID: "aabbccddee112233aabbccd1", Quantity: 2, Status: domain.ReservationStatusActive},
+			{ID: "eeffaabbee112233aabbccd2", ProductID: "aabbccddee112233aabbccd2", Quantity: 1, Status: domain.ReservationStatusActive},
+			{ID: "eeffaabbee112233aabbccd3", ProductID: "aabbccddee112233aabbccd3", Quantity: 4, Status: domain.ReservationStatusActive},
+		}
+
+		m.reservationRepo.EXPECT().GetExpired(gomock.Any(), gomock.Any(), int64(50)).Return(expired, nil)
+		m.txManager.EXPECT().
+			WithTransaction(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+				return fn(ctx)
+			}).
+			Times(3)
+
+		m.reservationRepo.EXPECT().
+			UpdateStatus(gomock.Any(), expired[0].ID, domain.ReservationStatusActive, domain.ReservationStatusReleased).
+			Return(nil)
+		m.productRepo.EXPECT().ReleaseReservedStock(gomock.Any(), expired[0].ProductID, "", 2).Return(nil)
+
+		m.reservationRepo.EXPECT().
+			UpdateStatus(gomock.Any(), expired[1].ID, domain.ReservationStatusActive, domain.ReservationStatusReleased).
+			Return(serviceerrors.NewConflictError("reservation is no longer active"))
+
+		m.reservationRepo.EXPECT().
+			UpdateStatus(gomock.Any(), expired[2].ID, domain.ReservationStatusActive, domain.ReservationStatusReleased).
+			Return(nil)
+		m.productRepo.EXPECT().ReleaseReservedStock(gomock.Any(), expired[2].ProductID, "", 4).Return(errors.New("db error"))
+
+		released, err := svc.ReleaseExpired(context.Background(), 50)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if released != 1 {
+			t.Fatalf("expected 1 released reservation, got %d", released)
+		}
+	})
+
+	t.Run("retries transient failures and moves on when they persist", func(t *testing.T) {
+		svc, m := setupReservationService(t)
+		expired := []*domain.Reservation{
+			{ID: "eeffaabbee112233aabbccd1", ProductID: "aabbccddee112233aabbccd1", Quantity: 2, Status: domain.ReservationStatusActive},
+			{ID: "eeffaabbee112233aabbccd2", ProductID: "aabbccddee112233aabbccd2", Quantity: 1, Status: domain.ReservationStatusActive},
+		}
+		writeConflict := serviceerrors.NewUnavailableError("write conflict")
+
+		m.reservationRepo.EXPECT().GetExpired(gomock.Any(), gomock.Any(), int64(50)).Return(expired, nil)
+		gomock.InOrder(
+			// The first reservation conflicts once, then is released.
+			m.txManager.EXPECT().WithTransaction(gomock.Any(), gomock.Any()).Return(writeConflict),
+			m.txManager.EXPECT().
+				WithTransaction(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+					return fn(ctx)
+				}),
+			// The second one keeps conflicting and is left for the next sweep.
+			m.txManager.EXPECT().WithTransaction(gomock.Any(), gomock.Any()).Return(writeConflict).Times(reservationReleaseAttempts),
+		)
+		m.reservationRepo.EXPECT().
+			UpdateStatus(gomock.Any(), expired[0].ID, domain.ReservationStatusActive, domain.ReservationStatusReleased).
+			Return(nil)
+		m.productRepo.EXPECT().ReleaseReservedStock(gomock.Any(), expired[0].ProductID, "", 2).Return(nil)
+
+		released, err := svc.ReleaseExpired(context.Background(), 50)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if released != 1 {
+			t.Fatalf("expected 1 released reservation, got %d", released)
+		}
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		svc, m := setupReservationService(t)
+
+		m.reservationRepo.EXPECT().GetExpired(gomock.Any(), gomock.Any(), int64(50)).Return(nil, errors.New("db error"))
+
+		if _, err := svc.ReleaseExpired(context.Background(), 50); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
@@ -9,6 +9,9 @@ const (
 	KindConflict
 	KindUnprocessableEntity
 	KindInvalidRequest
+	KindInvalidTransition
+	// KindUnavailable marks failures that may succeed if retried, such as write conflicts.
+	KindUnavailable
 )
 
 func IsOfKind(err error, kind ErrorKind) bool {
@@ -22,6 +25,7 @@ func IsOfKind(err error, kind ErrorKind) bool {
 type ServiceError struct {
 	Kind    ErrorKind
 	Message string
+	Details any
 }
 
 func (e *ServiceError) Error() string {
@@ -32,6 +36,10 @@ func NewNotFoundError(message string) *ServiceError {
 	return &ServiceError{Kind: KindNotFound, Message: message}
 }
 
+func NewNotFoundErrorWithDetails(message string, details any) *ServiceError {
+	return &ServiceError{Kind: KindNotFound, Message: message, Details: details}
+}
+
 func NewConflictError(message string) *ServiceError {
 	return &ServiceError{Kind: KindConflict, Message: message}
 }
@@ -40,6 +48,18 @@ func NewUnprocessableEntityError(message string) *ServiceError {
 	return &ServiceError{Kind: KindUnprocessableEntity, Message: message}
 }
 
+func NewUnprocessableEntityErrorWithDetails(message string, details any) *ServiceError {
+	return &ServiceError{Kind: KindUnprocessableEntity, Message: message, Details: details}
+}
+
 func NewInvalidRequestError(message string) *ServiceError {
 	return &ServiceError{Kind: KindInvalidRequest, Message: message}
 }
+
+func NewInvalidTransitionError(message string, details any) *ServiceError {
+	return &ServiceError{Kind: KindInvalidTransition, Message: message, Details: details}
+}
+
+func NewUnavailableError(message string) *ServiceError {
+	return &ServiceError{Kind: KindUnavailable, Message: message}
+}
@@ -1,10 +1,13 @@
 package integration_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -18,6 +21,7 @@ import (
 	"github.com/rafaelleal24/challenge/internal/core/domain"
 	"github.com/rafaelleal24/challenge/internal/core/dto"
 	"github.com/rafaelleal24/challenge/internal/core/service"
+	"github.com/rafaelleal24/challenge/internal/core/serviceerrors"
 	"github.com/testcontainers/testcontainers-go/modules/mongodb"
 	tcrabbit "github.com/testcontainers/testcontainers-go/modules/rabbitmq"
 	tcredis "github.com/testcontainers/testcontainers-go/modules/redis"
@@ -83,7 +87,9 @@ func TestMain(m *testing.M) {
 		MaxRetries: 2,
 		RetryDelay: 100 * time.Millisecond,
 		ExchangeConfigs: []adaptconfig.ExchangeConfig{
-			{Name: "exchange.order", Type: "direct", Durable: true, AutoDelete: false},
+			{Entity: "order", Name: "exchange.order", Type: "direct", Durable: true, AutoDelete: false},
+			{Entity: "product", Name: "exchange.product", Type: "direct", Durable: true, AutoDelete: false},
+			{Entity: "customer", Name: "exchange.customer", Type: "direct", Durable: true, AutoDelete: false},
 		},
 	})
 	if err != nil {
@@ -143,18 +149,24 @@ func buildServices(t *testing.T, dbName string) (
 
 	outboxRepo := repository.NewOutboxRepository(db)
 	orderRepo := repository.NewOrderRepository(db, outboxRepo)
-	productRepo := repository.NewProductRepository(db)
-	customerRepo := repository.NewCustomerRepository(db)
+	productRepo := repository.NewProductRepository(db, outboxRepo)
+	customerRepo := repository.NewCustomerRepository(db, outboxRepo)
+	reservationRepo := repository.NewReservationRepository(db)
+	stockMovementRepo := repository.NewStockMovementRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
 	txManager := adaptmongo.NewTransactionManager(mongoClient)
 
-	customerService := service.NewCustomerService(customerRepo)
-	productService := service.NewProductService(productRepo)
+	customerExistenceCache := adaptredis.NewCache[bool](redisClient, dbName+"-customer-exists")
+	customerService := service.NewCustomerService(customerRepo, customerExistenceCache)
+	productService := service.NewProductService(productRepo, categoryRepo, stockMovementRepo, txManager, 10)
+	reservationService := service.NewReservationService(reservationRepo, productService, txManager, 15*time.Minute)
 
 	orderCache := adaptredis.NewCache[domain.Order](redisClient, dbName+"-order")
 	idempotencyCache := adaptredis.NewCache[service.IdempotencyEntry[domain.Order]](redisClient, dbName+"-idemp")
 	idempotencyService := service.NewIdempotencyService(idempotencyCache, 5*time.Minute, 500*time.Millisecond, 10*time.Second)
 
-	orderService := service.NewOrderService(orderRepo, productService, customerService, orderCache, idempotencyService, txManager)
+	quoteSigner := service.NewQuoteSigner([]byte("integration-secret"), 10*time.Minute)
+	orderService := service.NewOrderService(orderRepo, productService, reservationService, customerService, orderCache, idempotencyService, txManager, quoteSigner)
 
 	outboxHandler := outbox.NewHandler(outboxRepo, broker, adaptconfig.OutboxConfig{
 		Interval:  100 * time.Millisecond,
@@ -164,6 +176,18 @@ func buildServices(t *testing.T, dbName string) (
 	return orderService, productService, customerService, outboxHandler
 }
 
+func createCustomer(t *testing.T, ctx context.Context, customerSvc *service.CustomerService) domain.ID {
+	t.Helper()
+	customer, err := customerSvc.CreateCustomer(ctx, &dto.CreateCustomerRequest{
+		Name:  "Integration Customer",
+		Email: "integration@example.com",
+	})
+	if err != nil {
+		t.Fatalf("create customer: %v", err)
+	}
+	return customer.ID
+}
+
 func TestIntegration_CreateOrder_FullCycle(t *testing.T) {
 	msgs := setupConsumer(t, "order.update_status")
 
@@ -174,10 +198,7 @@ func TestIntegration_CreateOrder_FullCycle(t *testing.T) {
 	defer cancelHandler()
 	go outboxHandler.Start(handlerCtx)
 
-	customerID, err := customerSvc.Create(ctx)
-	if err != nil {
-		t.Fatalf("create customer: %v", err)
-	}
+	customerID := createCustomer(t, ctx, customerSvc)
 
 	product, err := productSvc.CreateProduct(ctx, &dto.CreateProductRequest{
 		Name: "Integration Widget", Description: "e2e", Price: 2999, Stock: 50,
@@ -204,14 +225,27 @@ func TestIntegration_CreateOrder_FullCycle(t *testing.T) {
 	}
 
 	productAfter, _ := productSvc.GetByID(ctx, product.ID)
-	if productAfter.Stock != 47 {
-		t.Fatalf("expected stock 47, got %d", productAfter.Stock)
+	if productAfter.Stock != 50 || productAfter.AvailableStock() != 47 {
+		t.Fatalf("expected stock 50 with 47 available, got %d with %d available", productAfter.Stock, productAfter.AvailableStock())
 	}
 
-	if err := orderSvc.UpdateOrderStatus(ctx, order.ID, domain.OrderStatusProcessing); err != nil {
+	if err := orderSvc.UpdateOrderStatus(ctx, order.ID, domain.OrderStatusProcessing, nil, "", ""); err != nil {
 		t.Fatalf("update status: %v", err)
 	}
 
+	productAfter, _ = productSvc.GetByID(ctx, product.ID)
+	if productAfter.Stock != 47 || productAfter.Reserved != 0 {
+		t.Fatalf("expected committed stock 47 with nothing reserved, got %d with %d reserved", productAfter.Stock, productAfter.Reserved)
+	}
+
+	movements, err := productSvc.GetStockMovements(ctx, product.ID, &dto.ListStockMovementsRequest{})
+	if err != nil {
+		t.Fatalf("get stock movements: %v", err)
+	}
+	if len(movements.Items) != 1 || movements.Items[0].Quantity != -3 || movements.Items[0].OrderID != order.ID {
+		t.Fatalf("expected one order deduction of 3, got %+v", movements.Items)
+	}
+
 	select {
 	case msg := <-msgs:
 		var event domain.OrderUpdateStatusEvent
@@ -244,7 +278,7 @@ func TestIntegration_CreateOrder_Idempotency(t *testing.T) {
 	orderSvc, productSvc, customerSvc, _ := buildServices(t, "int_idempotency")
 	ctx := context.Background()
 
-	customerID, _ := customerSvc.Create(ctx)
+	customerID := createCustomer(t, ctx, customerSvc)
 	product, _ := productSvc.CreateProduct(ctx, &dto.CreateProductRequest{
 		Name: "Idemp Widget", Description: "test", Price: 1000, Stock: 100,
 	})
@@ -267,10 +301,10 @@ func TestIntegration_CreateOrder_Idempotency(t *testing.T) {
 		t.Fatalf("expected same order: %s vs %s", order1.ID, order2.ID)
 	}
 
-	// Stock deducted only once
+	// Stock reserved only once
 	p, _ := productSvc.GetByID(ctx, product.ID)
-	if p.Stock != 98 {
-		t.Fatalf("expected stock 98 (single deduction), got %d", p.Stock)
+	if p.AvailableStock() != 98 {
+		t.Fatalf("expected available stock 98 (single reservation), got %d", p.AvailableStock())
 	}
 }
 
@@ -278,7 +312,7 @@ func TestIntegration_CreateOrder_InsufficientStock(t *testing.T) {
 	orderSvc, productSvc, customerSvc, _ := buildServices(t, "int_low_stock")
 	ctx := context.Background()
 
-	customerID, _ := customerSvc.Create(ctx)
+	customerID := createCustomer(t, ctx, customerSvc)
 	product, _ := productSvc.CreateProduct(ctx, &dto.CreateProductRequest{
 		Name: "Low Stock", Description: "test", Price: 500, Stock: 2,
 	})
@@ -292,8 +326,210 @@ func TestIntegration_CreateOrder_InsufficientStock(t *testing.T) {
 	}
 
 	unchanged, _ := productSvc.GetByID(ctx, product.ID)
-	if unchanged.Stock != 2 {
-		t.Fatalf("stock should be unchanged after rollback: expected 2, got %d", unchanged.Stock)
+	if unchanged.Stock != 2 || unchanged.Reserved != 0 {
+		t.Fatalf("stock should be unchanged after rollback: expected 2 with nothing reserved, got %d with %d reserved", unchanged.Stock, unchanged.Reserved)
+	}
+}
+
+func TestIntegration_CreateOrder_KeepsShippingAddress(t *testing.T) {
+	orderSvc, productSvc, customerSvc, _ := buildServices(t, "int_shipping_address")
+	ctx := context.Background()
+
+	customerID := createCustomer(t, ctx, customerSvc)
+	_, saved, err := customerSvc.AddAddress(ctx, customerID, &dto.SaveAddressRequest{
+		Label: "home",
+		AddressRequest: dto.AddressRequest{
+			Recipient: "Ada Lovelace", Line1: "Rua A, 1", City: "São Paulo", PostalCode: "01000-000", Country: "BR",
+		},
+	})
+	if err != nil {
+		t.Fatalf("add address: %v", err)
+	}
+	product, _ := productSvc.CreateProduct(ctx, &dto.CreateProductRequest{
+		Name: "Shipped", Description: "test", Price: 500, Stock: 5,
+	})
+
+	order, err := orderSvc.CreateOrder(ctx, "", &dto.CreateOrderRequest{
+		CustomerID:        customerID,
+		Items:             []dto.OrderItem{{ProductID: product.ID, Quantity: 1}},
+		ShippingAddressID: saved.ID,
+	})
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+
+	replacement := &dto.SaveAddressRequest{
+		Label: "home",
+		AddressRequest: dto.AddressRequest{
+			Recipient: "Ada Lovelace", Line1: "Rua B, 2", City: "Rio de Janeiro", PostalCode: "20000-000", Country: "BR",
+		},
+	}
+	if _, _, err := customerSvc.ReplaceAddress(ctx, customerID, saved.ID, replacement); err != nil {
+		t.Fatalf("replace address: %v", err)
+	}
+
+	stored, err := orderSvc.GetOrderByID(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("get order: %v", err)
+	}
+	if stored.ShippingAddress == nil || stored.ShippingAddress.Line1 != "Rua A, 1" {
+		t.Fatalf("expected the order to keep the address it was placed with, got %+v", stored.ShippingAddress)
+	}
+}
+
+func TestIntegration_CreateOrder_ReportsEveryShortage(t *testing.T) {
+	orderSvc, productSvc, customerSvc, _ := buildServices(t, "int_shortages")
+	ctx := context.Background()
+
+	customerID := createCustomer(t, ctx, customerSvc)
+	plenty, _ := productSvc.CreateProduct(ctx, &dto.CreateProductRequest{
+		Name: "Plenty", Description: "test", Price: 500, Stock: 50,
+	})
+	few, _ := productSvc.CreateProduct(ctx, &dto.CreateProductRequest{
+		Name: "Few", Description: "test", Price: 500, Stock: 1,
+	})
+	none, _ := productSvc.CreateProduct(ctx, &dto.CreateProductRequest{
+		Name: "None", Description: "test", Price: 500, Stock: 0,
+	})
+
+	_, err := orderSvc.CreateOrder(ctx, "", &dto.CreateOrderRequest{
+		CustomerID: customerID,
+		Items: []dto.OrderItem{
+			{ProductID: plenty.ID, Quantity: 2},
+			{ProductID: few.ID, Quantity: 3},
+			{ProductID: none.ID, Quantity: 1},
+		},
+	})
+	var serviceErr *serviceerrors.ServiceError
+	if !errors.As(err, &serviceErr) || serviceErr.Kind != serviceerrors.KindUnprocessableEntity {
+		t.Fatalf("expected KindUnprocessableEntity, got %v", err)
+	}
+	details, _ := serviceErr.Details.(map[string]any)
+	shortages, _ := details["insufficient_stock"].([]domain.StockShortage)
+	if len(shortages) != 2 || shortages[0].ProductID != few.ID || shortages[0].Available != 1 || shortages[1].ProductID != none.ID {
+		t.Fatalf("expected shortages for %s and %s, got %+v", few.ID, none.ID, serviceErr.Details)
+	}
+
+	unchanged, _ := productSvc.GetByID(ctx, plenty.ID)
+	if unchanged.Reserved != 0 {
+		t.Fatalf("expected nothing reserved after rollback, got %d", unchanged.Reserved)
+	}
+}
+
+func TestIntegration_CreateOrder_Variant(t *testing.T) {
+	orderSvc, productSvc, customerSvc, _ := buildServices(t, "int_variants")
+	ctx := context.Background()
+
+	customerID := createCustomer(t, ctx, customerSvc)
+	largePrice := 3499
+	product, err := productSvc.CreateProduct(ctx, &dto.CreateProductRequest{
+		Name: "Tee", Description: "test", Price: 2999,
+		Variants: []dto.CreateVariantRequest{
+			{SKU: "INT-TEE-M", Name: "Medium", Stock: 4},
+			{SKU: "INT-TEE-L", Name: "Large", Price: &largePrice, Stock: 6},
+		},
+	})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	order, err := orderSvc.CreateOrder(ctx, "", &dto.CreateOrderRequest{
+		CustomerID: customerID,
+		Items:      []dto.OrderItem{{ProductID: product.ID, SKU: "INT-TEE-L", Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	if order.TotalAmount != domain.Amount(6998) {
+		t.Fatalf("expected the variant price to be charged, got total %d", order.TotalAmount)
+	}
+
+	p, _ := productSvc.GetByID(ctx, product.ID)
+	if p.Variant("INT-TEE-L").Reserved != 2 || p.Variant("INT-TEE-M").Reserved != 0 || p.Reserved != 2 {
+		t.Fatalf("expected 2 units of INT-TEE-L reserved, got %+v", p.Variants)
+	}
+}
+
+func TestIntegration_ProductImport(t *testing.T) {
+	orderSvc, productSvc, customerSvc, _ := buildServices(t, "int_product_import")
+	db := mongoClient.Database("int_product_import")
+	productRepo := repository.NewProductRepository(db, repository.NewOutboxRepository(db))
+	importSvc := service.NewProductImportService(productRepo, repository.NewCategoryRepository(db), repository.NewStockMovementRepository(db), repository.NewProductImportRepository(db), adaptmongo.NewTransactionManager(mongoClient), time.Minute)
+	ctx := context.Background()
+
+	existing, err := productSvc.CreateProduct(ctx, &dto.CreateProductRequest{SKU: "INT-MUG", Name: "Mug", Price: 800, Stock: 5})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	body := "sku,name,price,stock\nINT-MUG,Large mug,950,99\nINT-CAP,Cap,1200,7\nINT-BAD,,100,1\n"
+	productImport, err := importSvc.StartImport(ctx, domain.ProductImportFormatCSV, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("start import: %v", err)
+	}
+	if found, err := importSvc.ProcessNext(ctx); err != nil || !found {
+		t.Fatalf("process import: found %v, err %v", found, err)
+	}
+
+	status, err := importSvc.GetImport(ctx, productImport.ID)
+	if err != nil {
+		t.Fatalf("get import: %v", err)
+	}
+	if status.Status != domain.ProductImportStatusCompleted || status.Created != 1 || status.Updated != 1 || status.Failed != 1 {
+		t.Fatalf("unexpected import %+v", status)
+	}
+
+	mug, _ := productSvc.GetByID(ctx, existing.ID)
+	if mug.Name != "Large mug" || mug.Price != domain.Amount(950) || mug.Stock != 5 {
+		t.Fatalf("expected the mug to be updated with its stock kept, got %+v", mug)
+	}
+
+	// The imported cap can be ordered like any other product.
+	customerID := createCustomer(t, ctx, customerSvc)
+	page, err := productSvc.ListProducts(ctx, &dto.ListProductsRequest{NamePrefix: "Cap"})
+	if err != nil || len(page.Items) != 1 {
+		t.Fatalf("list imported product: %v (err %v)", page, err)
+	}
+	movements, err := productSvc.GetStockMovements(ctx, page.Items[0].ID, &dto.ListStockMovementsRequest{})
+	if err != nil || len(movements.Items) != 1 || movements.Items[0].Reason != domain.StockMovementReasonImport || movements.Items[0].Quantity != 7 {
+		t.Fatalf("expected the imported stock in the ledger, got %+v (err %v)", movements, err)
+	}
+	if _, err := orderSvc.CreateOrder(ctx, "", &dto.CreateOrderRequest{
+		CustomerID: customerID,
+		Items:      []dto.OrderItem{{ProductID: page.Items[0].ID, Quantity: 2}},
+	}); err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+
+	if _, err := productSvc.CreateProduct(ctx, &dto.CreateProductRequest{Name: "Hoodie", Price: 5000, Variants: []dto.CreateVariantRequest{
+		{SKU: "INT-HOODIE-S", Name: "Small", Stock: 2},
+		{SKU: "INT-HOODIE-M", Name: "Medium", Stock: 3},
+	}}); err != nil {
+		t.Fatalf("create product with variants: %v", err)
+	}
+
+	var exported bytes.Buffer
+	if err := importSvc.ExportProducts(ctx, domain.ProductImportFormatNDJSON, &exported); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if lines := strings.Count(exported.String(), "\n"); lines != 4 {
+		t.Fatalf("expected 4 exported rows, got %d:\n%s", lines, exported.String())
+	}
+
+	// Importing the export back matches every row, variants included, to what it came from.
+	roundTrip, err := importSvc.StartImport(ctx, domain.ProductImportFormatNDJSON, &exported)
+	if err != nil {
+		t.Fatalf("start round trip import: %v", err)
+	}
+	if found, err := importSvc.ProcessNext(ctx); err != nil || !found {
+		t.Fatalf("process round trip import: found %v, err %v", found, err)
+	}
+	status, err = importSvc.GetImport(ctx, roundTrip.ID)
+	if err != nil {
+		t.Fatalf("get round trip import: %v", err)
+	}
+	if status.Created != 0 || status.Updated != 4 || status.Failed != 0 {
+		t.Fatalf("expected every exported row to update its product, got %+v", status)
 	}
 }
 
@@ -318,7 +554,7 @@ func TestIntegration_GetOrderByID_Cache(t *testing.T) {
 	orderSvc, productSvc, customerSvc, _ := buildServices(t, "int_cache")
 	ctx := context.Background()
 
-	customerID, _ := customerSvc.Create(ctx)
+	customerID := createCustomer(t, ctx, customerSvc)
 	product, _ := productSvc.CreateProduct(ctx, &dto.CreateProductRequest{
 		Name: "Cache Widget", Description: "test", Price: 1500, Stock: 20,
 	})
@@ -354,7 +590,7 @@ func TestIntegration_MultipleStatusUpdates(t *testing.T) {
 	defer cancelHandler()
 	go outboxHandler.Start(handlerCtx)
 
-	customerID, _ := customerSvc.Create(ctx)
+	customerID := createCustomer(t, ctx, customerSvc)
 	product, _ := productSvc.CreateProduct(ctx, &dto.CreateProductRequest{
 		Name: "Multi Widget", Description: "test", Price: 1000, Stock: 10,
 	})
@@ -365,7 +601,7 @@ func TestIntegration_MultipleStatusUpdates(t *testing.T) {
 
 	transitions := []domain.OrderStatus{domain.OrderStatusProcessing, domain.OrderStatusShipped}
 	for _, status := range transitions {
-		if err := orderSvc.UpdateOrderStatus(ctx, order.ID, status); err != nil {
+		if err := orderSvc.UpdateOrderStatus(ctx, order.ID, status, nil, "", ""); err != nil {
 			t.Fatalf("update to %q: %v", status, err)
 		}
 
@@ -387,4 +623,98 @@ func TestIntegration_MultipleStatusUpdates(t *testing.T) {
 	if final.Status != domain.OrderStatusShipped {
 		t.Fatalf("expected final status 'shipped', got %q", final.Status)
 	}
+
+	history, err := orderSvc.GetOrderStatusHistory(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("status history: %v", err)
+	}
+	if len(history) != len(transitions) {
+		t.Fatalf("expected %d history entries, got %d", len(transitions), len(history))
+	}
+}
+
+func TestIntegration_CancelOrder_RestoresStock(t *testing.T) {
+	msgs := setupConsumer(t, "order.cancelled")
+
+	orderSvc, productSvc, customerSvc, outboxHandler := buildServices(t, "int_cancel")
+	ctx := context.Background()
+
+	handlerCtx, cancelHandler := context.WithCancel(ctx)
+	defer cancelHandler()
+	go outboxHandler.Start(handlerCtx)
+
+	customerID := createCustomer(t, ctx, customerSvc)
+	product, _ := productSvc.CreateProduct(ctx, &dto.CreateProductRequest{
+		Name: "Cancel Widget", Description: "test", Price: 1000, Stock: 10,
+	})
+	order, err := orderSvc.CreateOrder(ctx, "", &dto.CreateOrderRequest{
+		CustomerID: customerID,
+		Items:      []dto.OrderItem{{ProductID: product.ID, Quantity: 4}},
+	})
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+
+	cancelled, err := orderSvc.CancelOrder(ctx, order.ID, "", "customer request")
+	if err != nil {
+		t.Fatalf("cancel order: %v", err)
+	}
+	if cancelled.Status != domain.OrderStatusCancelled {
+		t.Fatalf("expected status 'cancelled', got %q", cancelled.Status)
+	}
+
+	p, _ := productSvc.GetByID(ctx, product.ID)
+	if p.Stock != 10 || p.AvailableStock() != 10 {
+		t.Fatalf("expected reservation released with stock 10 available, got %d with %d available", p.Stock, p.AvailableStock())
+	}
+
+	select {
+	case msg := <-msgs:
+		var event domain.OrderCancelledEvent
+		if err := json.Unmarshal(msg.Body, &event); err != nil {
+			t.Fatalf("unmarshal event: %v", err)
+		}
+		if event.OrderID != order.ID {
+			t.Fatalf("event order_id: expected %s, got %s", order.ID, event.OrderID)
+		}
+		if event.Reason != "customer request" {
+			t.Fatalf("event reason: expected 'customer request', got %q", event.Reason)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for order.cancelled event")
+	}
+}
+
+func TestIntegration_ExpireStaleOrders_ReleasesStock(t *testing.T) {
+	orderSvc, productSvc, customerSvc, _ := buildServices(t, "int_expire")
+	ctx := context.Background()
+
+	customerID := createCustomer(t, ctx, customerSvc)
+	product, _ := productSvc.CreateProduct(ctx, &dto.CreateProductRequest{
+		Name: "Expiry Widget", Description: "test", Price: 1000, Stock: 10,
+	})
+	order, err := orderSvc.CreateOrder(ctx, "", &dto.CreateOrderRequest{
+		CustomerID: customerID,
+		Items:      []dto.OrderItem{{ProductID: product.ID, Quantity: 4}},
+	})
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+
+	expired, err := orderSvc.ExpireStaleOrders(ctx, time.Now().Add(time.Minute), 10)
+	if err != nil {
+		t.Fatalf("expire orders: %v", err)
+	}
+	if expired != 1 {
+		t.Fatalf("expected 1 expired order, got %d", expired)
+	}
+
+	got, _ := orderSvc.GetOrderByID(ctx, order.ID)
+	if got.Status != domain.OrderStatusCancelled {
+		t.Fatalf("expected status 'cancelled', got %q", got.Status)
+	}
+	p, _ := productSvc.GetByID(ctx, product.ID)
+	if p.AvailableStock() != 10 {
+		t.Fatalf("expected available stock 10, got %d", p.AvailableStock())
+	}
 }